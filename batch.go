@@ -116,6 +116,8 @@ type batchResults struct {
 	qqIdx     int
 	closed    bool
 	endTraced bool
+
+	noticeScope *noticeScope
 }
 
 // Exec reads the results from the next query in the batch as if the query has been sent with Exec.
@@ -214,6 +216,9 @@ func (br *batchResults) QueryRow() Row {
 // resyncronize the connection with the server. In this case the underlying connection will have been closed.
 func (br *batchResults) Close() error {
 	defer func() {
+		if br.conn != nil {
+			br.conn.endNoticeScope(br.noticeScope)
+		}
 		if !br.endTraced {
 			if br.conn != nil && br.conn.batchTracer != nil {
 				br.conn.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err})
@@ -256,6 +261,10 @@ func (br *batchResults) earlyError() error {
 	return br.err
 }
 
+func (br *batchResults) setNoticeScope(noticeScope *noticeScope) {
+	br.noticeScope = noticeScope
+}
+
 func (br *batchResults) nextQueryAndArgs() (query string, args []any, ok bool) {
 	if br.b != nil && br.qqIdx < len(br.b.QueuedQueries) {
 		bi := br.b.QueuedQueries[br.qqIdx]
@@ -277,6 +286,8 @@ type pipelineBatchResults struct {
 	qqIdx     int
 	closed    bool
 	endTraced bool
+
+	noticeScope *noticeScope
 }
 
 // Exec reads the results from the next query in the batch as if the query has been sent with Exec.
@@ -385,6 +396,7 @@ func (br *pipelineBatchResults) QueryRow() Row {
 // resyncronize the connection with the server. In this case the underlying connection will have been closed.
 func (br *pipelineBatchResults) Close() error {
 	defer func() {
+		br.conn.endNoticeScope(br.noticeScope)
 		if !br.endTraced {
 			if br.conn.batchTracer != nil {
 				br.conn.batchTracer.TraceBatchEnd(br.ctx, br.conn, TraceBatchEndData{Err: br.err})
@@ -428,6 +440,10 @@ func (br *pipelineBatchResults) earlyError() error {
 	return br.err
 }
 
+func (br *pipelineBatchResults) setNoticeScope(noticeScope *noticeScope) {
+	br.noticeScope = noticeScope
+}
+
 func (br *pipelineBatchResults) nextQueryAndArgs() (query string, args []any, err error) {
 	if br.b == nil {
 		return "", nil, errors.New("no reference to batch")
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
@@ -71,12 +72,20 @@ type Conn struct {
 	statementCache     stmtcache.Cache
 	descriptionCache   stmtcache.Cache
 
+	functionDescriptions map[string]*functionDescription // functions called with FunctionCall
+
 	queryTracer    QueryTracer
 	batchTracer    BatchTracer
 	copyFromTracer CopyFromTracer
+	copyToTracer   CopyToTracer
 	prepareTracer  PrepareTracer
 
 	notifications []*gaussdbconn.Notification
+	noticeScope   *noticeScope // notice handler of the operation in progress
+
+	initialParameterStatuses map[string]string // parameters reported when the connection was established
+	changedParameterStatuses map[string]string // parameters that differ from initialParameterStatuses
+	parameterStatusConn      atomic.Pointer[gaussdbconn.GaussdbConn]
 
 	doneChan   chan struct{}
 	closedChan chan error
@@ -263,6 +272,9 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 	if t, ok := c.queryTracer.(CopyFromTracer); ok {
 		c.copyFromTracer = t
 	}
+	if t, ok := c.queryTracer.(CopyToTracer); ok {
+		c.copyToTracer = t
+	}
 	if t, ok := c.queryTracer.(PrepareTracer); ok {
 		c.prepareTracer = t
 	}
@@ -272,12 +284,19 @@ func connect(ctx context.Context, config *ConnConfig) (c *Conn, err error) {
 		config.Config.OnNotification = c.bufferNotifications
 	}
 
-	c.gaussdbConn, err = gaussdbconn.ConnectConfig(ctx, &config.Config)
+	// The notice and parameter status handler wrappers are only installed on the config used to connect. c.config keeps
+	// the original handlers so that Config and connections made from it do not wrap them again.
+	gaussdbConfig := config.Config
+	gaussdbConfig.OnNotice = c.handleNotice(config.Config.OnNotice)
+	gaussdbConfig.OnParameterStatus = c.handleParameterStatus(config.Config.OnParameterStatus)
+
+	c.gaussdbConn, err = gaussdbconn.ConnectConfig(ctx, &gaussdbConfig)
 	if err != nil {
 		return nil, err
 	}
 
 	c.preparedStatements = make(map[string]*gaussdbconn.StatementDescription)
+	c.initSessionState()
 	c.doneChan = make(chan struct{})
 	c.closedChan = make(chan error)
 	c.wbuf = make([]byte, 0, 1024)
@@ -459,6 +478,9 @@ func (c *Conn) Exec(ctx context.Context, sql string, arguments ...any) (gaussdbc
 		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: arguments})
 	}
 
+	scope := c.beginNoticeScope(ctx)
+	defer c.endNoticeScope(scope)
+
 	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
 		return gaussdbconn.CommandTag{}, err
 	}
@@ -695,6 +717,14 @@ type QueryResultFormats []int16
 // QueryResultFormatsByOID controls the result format (text=0, binary=1) of a query by the result column OID.
 type QueryResultFormatsByOID map[uint32]int16
 
+// QueryFetchSize limits the number of rows fetched from the server per round trip when used as the first arguments to
+// Query. The query is bound to a named portal and the next rows are fetched as Rows.Next advances. This keeps the memory
+// used for large results bounded. Closing the Rows early does not fetch the remaining rows. A QueryFetchSize of 0
+// fetches all rows at once.
+//
+// QueryFetchSize is not supported with QueryExecModeSimpleProtocol.
+type QueryFetchSize uint32
+
 // QueryRewriter rewrites a query when used as the first arguments to a query method.
 type QueryRewriter interface {
 	RewriteQuery(ctx context.Context, conn *Conn, sql string, args []any) (newSQL string, newArgs []any, err error)
@@ -718,15 +748,18 @@ type QueryRewriter interface {
 // An implementor of QueryRewriter may be passed as the first element of args. It can rewrite the sql and change or
 // replace args. For example, NamedArgs is QueryRewriter that implements named arguments.
 //
-// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats, and
-// QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
-// needed. See the documentation for those types for details.
+// For extra control over how the query is executed, the types QueryExecMode, QueryResultFormats,
+// QueryResultFormatsByOID, and QueryFetchSize may be used as the first args to control exactly how the query is
+// executed. This is rarely needed. See the documentation for those types for details.
 func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error) {
 	if c.queryTracer != nil {
 		ctx = c.queryTracer.TraceQueryStart(ctx, c, TraceQueryStartData{SQL: sql, Args: args})
 	}
 
+	scope := c.beginNoticeScope(ctx)
+
 	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
+		c.endNoticeScope(scope)
 		if c.queryTracer != nil {
 			c.queryTracer.TraceQueryEnd(ctx, c, TraceQueryEndData{Err: err})
 		}
@@ -735,6 +768,7 @@ func (c *Conn) Query(ctx context.Context, sql string, args ...any) (Rows, error)
 
 	var resultFormats QueryResultFormats
 	var resultFormatsByOID QueryResultFormatsByOID
+	var fetchSize QueryFetchSize
 	mode := c.config.DefaultQueryExecMode
 	var queryRewriter QueryRewriter
 
@@ -747,6 +781,9 @@ optionLoop:
 		case QueryResultFormatsByOID:
 			resultFormatsByOID = arg
 			args = args[1:]
+		case QueryFetchSize:
+			fetchSize = arg
+			args = args[1:]
 		case QueryExecMode:
 			mode = arg
 			args = args[1:]
@@ -765,6 +802,7 @@ optionLoop:
 		sql, args, err = queryRewriter.RewriteQuery(ctx, c, sql, args)
 		if err != nil {
 			rows := c.getRows(ctx, originalSQL, originalArgs)
+			rows.noticeScope = scope
 			err = fmt.Errorf("rewrite query failed: %w", err)
 			rows.fatal(err)
 			return rows, err
@@ -778,6 +816,7 @@ optionLoop:
 
 	c.eqb.reset()
 	rows := c.getRows(ctx, sql, args)
+	rows.noticeScope = scope
 
 	var err error
 	sd, explicitPreparedStatement := c.preparedStatements[sql]
@@ -815,9 +854,17 @@ optionLoop:
 		}
 
 		if !explicitPreparedStatement && mode == QueryExecModeCacheDescribe {
-			rows.resultReader = c.gaussdbConn.ExecParams(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats)
+			if fetchSize > 0 {
+				rows.resultReader = c.gaussdbConn.ExecParamsFetch(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats, uint32(fetchSize))
+			} else {
+				rows.resultReader = c.gaussdbConn.ExecParams(ctx, sql, c.eqb.ParamValues, sd.ParamOIDs, c.eqb.ParamFormats, resultFormats)
+			}
 		} else {
-			rows.resultReader = c.gaussdbConn.ExecPrepared(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats)
+			if fetchSize > 0 {
+				rows.resultReader = c.gaussdbConn.ExecPreparedFetch(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats, uint32(fetchSize))
+			} else {
+				rows.resultReader = c.gaussdbConn.ExecPrepared(ctx, sd.Name, c.eqb.ParamValues, c.eqb.ParamFormats, resultFormats)
+			}
 		}
 	} else if mode == QueryExecModeExec {
 		err := c.eqb.Build(c.typeMap, nil, args)
@@ -826,8 +873,18 @@ optionLoop:
 			return rows, rows.err
 		}
 
-		rows.resultReader = c.gaussdbConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		if fetchSize > 0 {
+			rows.resultReader = c.gaussdbConn.ExecParamsFetch(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats, uint32(fetchSize))
+		} else {
+			rows.resultReader = c.gaussdbConn.ExecParams(ctx, sql, c.eqb.ParamValues, nil, c.eqb.ParamFormats, c.eqb.ResultFormats)
+		}
 	} else if mode == QueryExecModeSimpleProtocol {
+		if fetchSize > 0 {
+			err = fmt.Errorf("QueryFetchSize is not supported with QueryExecModeSimpleProtocol")
+			rows.fatal(err)
+			return rows, err
+		}
+
 		sql, err = c.sanitizeForSimpleQuery(sql, args...)
 		if err != nil {
 			rows.fatal(err)
@@ -922,6 +979,11 @@ func (c *Conn) SendBatch(ctx context.Context, b *Batch) (br BatchResults) {
 		}()
 	}
 
+	scope := c.beginNoticeScope(ctx)
+	defer func() {
+		br.(interface{ setNoticeScope(*noticeScope) }).setNoticeScope(scope)
+	}()
+
 	if err := c.deallocateInvalidatedCachedStatements(ctx); err != nil {
 		return &batchResults{ctx: ctx, conn: c, err: err}
 	}
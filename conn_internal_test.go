@@ -6,6 +6,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbtype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -53,3 +54,17 @@ func TestStmtCacheSizeLimit(t *testing.T) {
 	assert.Len(t, conn.preparedStatements, cacheLimit+1)
 	assert.Equal(t, cacheLimit, conn.statementCache.Len())
 }
+
+func TestConnSearchPathChangeClearsFunctionDescriptions(t *testing.T) {
+	conn := &Conn{
+		typeMap:                  gaussdbtype.NewMap(),
+		changedParameterStatuses: make(map[string]string),
+		functionDescriptions:     map[string]*functionDescription{"lo_creat": {oid: 957}},
+	}
+
+	conn.updateSessionState("application_name", "foo")
+	assert.Len(t, conn.functionDescriptions, 1)
+
+	conn.updateSessionState("search_path", "myschema")
+	assert.Empty(t, conn.functionDescriptions)
+}
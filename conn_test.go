@@ -5,6 +5,7 @@ import (
 	"context"
 	"database/sql"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
@@ -1400,6 +1401,134 @@ func TestConnDeallocateInvalidatedCachedStatementsInTransactionWithBatch(t *test
 	ensureConnValid(t, conn)
 }
 
+func TestConnFunctionCall(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	defaultConnTestRunner.RunTest(ctx, t, func(ctx context.Context, t testing.TB, conn *gaussdbgo.Conn) {
+		var s string
+		err := conn.FunctionCall(ctx, "upper(text)", &s, "foo")
+		require.NoError(t, err)
+		require.Equal(t, "FOO", s)
+
+		var n int32
+		err = conn.FunctionCall(ctx, "int4pl", &n, 40, 2)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+
+		err = conn.FunctionCall(ctx, "int4pl", &n, 1)
+		require.ErrorContains(t, err, "expects 2 arguments")
+
+		err = conn.FunctionCall(ctx, "no_such_function", nil)
+		require.Error(t, err)
+	})
+}
+
+func TestConnWithNoticeHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config.RuntimeParams["client_min_messages"] = "notice"
+	var connNotices []string
+	config.OnNotice = func(_ *gaussdbconn.GaussdbConn, n *gaussdbconn.Notice) {
+		connNotices = append(connNotices, n.Message)
+	}
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create or replace function gaussdbgo_test_notice(msg text) returns int language plpgsql as $$
+begin
+  raise notice '%', msg;
+  return 1;
+end$$`)
+	defer mustExec(t, conn, "drop function gaussdbgo_test_notice(text)")
+
+	var notices []string
+	noticeCtx := gaussdbgo.WithNoticeHandler(ctx, func(n *gaussdbconn.Notice) {
+		notices = append(notices, n.Message)
+	})
+
+	_, err := conn.Exec(noticeCtx, "select gaussdbgo_test_notice('exec')")
+	require.NoError(t, err)
+	require.Equal(t, []string{"exec"}, notices)
+
+	rows, err := conn.Query(noticeCtx, "select gaussdbgo_test_notice('query')")
+	require.NoError(t, err)
+	rows.Close()
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"exec", "query"}, notices)
+
+	batch := &gaussdbgo.Batch{}
+	batch.Queue("select gaussdbgo_test_notice('batch 1')")
+	batch.Queue("select gaussdbgo_test_notice('batch 2')")
+	err = conn.SendBatch(noticeCtx, batch).Close()
+	require.NoError(t, err)
+	require.Equal(t, []string{"exec", "query", "batch 1", "batch 2"}, notices)
+
+	// Notices of calls without the handler are not captured.
+	_, err = conn.Exec(ctx, "select gaussdbgo_test_notice('other')")
+	require.NoError(t, err)
+	require.Equal(t, []string{"exec", "query", "batch 1", "batch 2"}, notices)
+
+	// The connection-wide handler still receives all notices.
+	require.Equal(t, []string{"exec", "query", "batch 1", "batch 2", "other"}, connNotices)
+
+	// A connection made from the config of conn does not report its notices to the handler of conn.
+	otherConn := mustConnect(t, conn.Config())
+	defer closeConn(t, otherConn)
+
+	rows, err = conn.Query(noticeCtx, "select 1")
+	require.NoError(t, err)
+	_, err = otherConn.Exec(ctx, "select gaussdbgo_test_notice('other conn')")
+	require.NoError(t, err)
+	rows.Close()
+	require.NoError(t, rows.Err())
+	require.Equal(t, []string{"exec", "query", "batch 1", "batch 2"}, notices)
+	require.Equal(t, []string{"exec", "query", "batch 1", "batch 2", "other", "other conn"}, connNotices)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnChangedParameterStatuses(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config := mustParseConfig(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	var reported []string
+	config.OnParameterStatus = func(_ *gaussdbconn.GaussdbConn, name, value string) {
+		reported = append(reported, name)
+	}
+	conn := mustConnect(t, config)
+	defer closeConn(t, conn)
+
+	require.Empty(t, conn.ChangedParameterStatuses())
+	initialTimeZone := conn.TypeMap().ParameterStatus("TimeZone")
+	require.NotEmpty(t, initialTimeZone)
+
+	reported = nil
+	_, err := conn.Exec(ctx, "set timezone = 'Asia/Shanghai'")
+	require.NoError(t, err)
+	require.Contains(t, reported, "TimeZone")
+	require.Equal(t, map[string]string{"TimeZone": "Asia/Shanghai"}, conn.ChangedParameterStatuses())
+	require.Equal(t, "Asia/Shanghai", conn.TypeMap().ParameterStatus("TimeZone"))
+
+	_, err = conn.Exec(ctx, "set timezone = '"+initialTimeZone+"'")
+	require.NoError(t, err)
+	require.Empty(t, conn.ChangedParameterStatuses())
+
+	// The config of conn keeps the original handler.
+	require.Equal(t, reflect.ValueOf(config.OnParameterStatus).Pointer(), reflect.ValueOf(conn.Config().OnParameterStatus).Pointer())
+
+	ensureConnValid(t, conn)
+}
+
 func TestErrNoRows(t *testing.T) {
 	t.Parallel()
 
@@ -263,6 +263,9 @@ func (ct *copyFrom) buildCopyBuf(buf []byte, sd *gaussdbconn.StatementDescriptio
 // Even though enum types appear to be strings they still must be registered to use with CopyFrom. This can be done with
 // Conn.LoadType and gaussdbtype.Map.RegisterType.
 func (c *Conn) CopyFrom(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource) (int64, error) {
+	scope := c.beginNoticeScope(ctx)
+	defer c.endNoticeScope(scope)
+
 	ct := &copyFrom{
 		conn:          c,
 		tableName:     tableName,
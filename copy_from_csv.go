@@ -0,0 +1,188 @@
+package gaussdbgo
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/sanitize"
+)
+
+// CopyFromCSVOptions are the options of CopyFromCSV. Options that are empty or false use the server default.
+type CopyFromCSVOptions struct {
+	// Delimiter separates the columns of a row. The default is a comma.
+	Delimiter string
+
+	// Quote is the quote character. The default is a double quote.
+	Quote string
+
+	// Escape is the character that escapes a quote character within a quoted value. The default is Quote.
+	Escape string
+
+	// Null is the string that represents NULL. The default is an unquoted empty string.
+	Null string
+
+	// Header skips the first line of the input.
+	Header bool
+
+	// ErrorHandling configures the fault tolerance of the copy.
+	ErrorHandling CopyFromErrorHandling
+}
+
+func (opts CopyFromCSVOptions) copyOptions() [][2]string {
+	options := [][2]string{{"format", "csv"}}
+	options = appendCopyOption(options, "delimiter", opts.Delimiter)
+	options = appendCopyOption(options, "quote", opts.Quote)
+	options = appendCopyOption(options, "escape", opts.Escape)
+	options = appendCopyOption(options, "null", opts.Null)
+	if opts.Header {
+		options = append(options, [2]string{"header", "true"})
+	}
+	return opts.ErrorHandling.appendCopyOptions(options)
+}
+
+// CopyFromTextOptions are the options of CopyFromText. Options that are empty use the server default.
+type CopyFromTextOptions struct {
+	// Delimiter separates the columns of a row. The default is a tab.
+	Delimiter string
+
+	// Null is the string that represents NULL. The default is \N.
+	Null string
+
+	// ErrorHandling configures the fault tolerance of the copy.
+	ErrorHandling CopyFromErrorHandling
+}
+
+func (opts CopyFromTextOptions) copyOptions() [][2]string {
+	options := [][2]string{{"format", "text"}}
+	options = appendCopyOption(options, "delimiter", opts.Delimiter)
+	options = appendCopyOption(options, "null", opts.Null)
+	return opts.ErrorHandling.appendCopyOptions(options)
+}
+
+func appendCopyOption(options [][2]string, name, value string) [][2]string {
+	if value == "" {
+		return options
+	}
+	return append(options, [2]string{name, value})
+}
+
+// copyFromReader streams r to the server with a COPY FROM STDIN statement with options.
+type copyFromReader struct {
+	conn          *Conn
+	tableName     Identifier
+	columnNames   []string
+	r             io.Reader
+	options       [][2]string
+	errorHandling CopyFromErrorHandling
+}
+
+func (ct *copyFromReader) sql() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("copy ")
+	buf.WriteString(ct.tableName.Sanitize())
+	if len(ct.columnNames) > 0 {
+		buf.WriteString(" ( ")
+		for i, cn := range ct.columnNames {
+			if i != 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(quoteIdentifier(cn))
+		}
+		buf.WriteString(" )")
+	}
+	buf.WriteString(" from stdin")
+	buf.WriteString(ct.errorHandling.clause())
+	buf.WriteString(" with (")
+	for i, option := range ct.options {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(option[0])
+		buf.WriteByte(' ')
+		buf.Write(sanitize.QuoteString(nil, option[1]))
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func (ct *copyFromReader) run(ctx context.Context) (int64, error) {
+	err := ct.errorHandling.validate()
+	if err != nil {
+		return 0, err
+	}
+
+	var report *copyErrorReport
+	if ct.errorHandling.logErrors() && ct.errorHandling.Rejected != nil {
+		report, err = beginCopyErrorReport(ctx, ct.conn, ct.tableName)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	rowCount, err := ct.copy(ctx)
+	if err != nil || report == nil {
+		return rowCount, err
+	}
+
+	err = report.read(ctx, ct.errorHandling.Rejected)
+	return rowCount, err
+}
+
+func (ct *copyFromReader) copy(ctx context.Context) (int64, error) {
+	if ct.conn.copyFromTracer != nil {
+		ctx = ct.conn.copyFromTracer.TraceCopyFromStart(ctx, ct.conn, TraceCopyFromStartData{
+			TableName:   ct.tableName,
+			ColumnNames: ct.columnNames,
+		})
+	}
+
+	scope := ct.conn.beginNoticeScope(ctx)
+	defer ct.conn.endNoticeScope(scope)
+
+	commandTag, err := ct.conn.gaussdbConn.CopyFrom(ctx, ct.r, ct.sql())
+
+	if ct.conn.copyFromTracer != nil {
+		ct.conn.copyFromTracer.TraceCopyFromEnd(ctx, ct.conn, TraceCopyFromEndData{
+			CommandTag: commandTag,
+			Err:        err,
+		})
+	}
+
+	return commandTag.RowsAffected(), err
+}
+
+// CopyFromCSV uses the GaussDB copy protocol to insert the CSV data read from r into tableName. The data is streamed to
+// the server without being decoded. columnNames maps the columns of the CSV data to the columns of the table. All
+// columns of the table in order are used if it is nil. CopyFromCSV returns the number of rows copied and an error. Set
+// opts.ErrorHandling to skip bad rows instead of aborting the copy.
+//
+// Note: context cancellation will only interrupt operations on the underlying GaussDB network connection. Reads on r
+// could still block.
+func (c *Conn) CopyFromCSV(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromCSVOptions) (int64, error) {
+	ct := &copyFromReader{
+		conn:          c,
+		tableName:     tableName,
+		columnNames:   columnNames,
+		r:             r,
+		options:       opts.copyOptions(),
+		errorHandling: opts.ErrorHandling,
+	}
+
+	return ct.run(ctx)
+}
+
+// CopyFromText is like CopyFromCSV but for data in the GaussDB text format, i.e. delimited columns with backslash
+// escapes.
+func (c *Conn) CopyFromText(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromTextOptions) (int64, error) {
+	ct := &copyFromReader{
+		conn:          c,
+		tableName:     tableName,
+		columnNames:   columnNames,
+		r:             r,
+		options:       opts.copyOptions(),
+		errorHandling: opts.ErrorHandling,
+	}
+
+	return ct.run(ctx)
+}
@@ -0,0 +1,128 @@
+package gaussdbgo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CopyFromErrorHandling configures how CopyFromCSV and CopyFromText handle rows that cannot be loaded. The zero value
+// aborts the copy on the first bad row.
+type CopyFromErrorHandling struct {
+	// LogErrors makes the server record rows with data type errors in the pgxc_copy_error_log table instead of aborting
+	// the copy. The table must have been created with copy_error_log_create() beforehand.
+	LogErrors bool
+
+	// LogErrorData records the raw data of rejected rows in addition to the error. It implies LogErrors.
+	LogErrorData bool
+
+	// RejectLimit is the number of rejected rows after which the copy is aborted. A negative value means unlimited. Zero
+	// uses the server default. It requires LogErrors.
+	RejectLimit int64
+
+	// IgnoreExtraData ignores extra columns at the end of a row instead of failing.
+	IgnoreExtraData bool
+
+	// FillMissingFields sets missing columns at the end of a row to NULL instead of failing.
+	FillMissingFields bool
+
+	// Rejected receives the rows rejected by the copy when it is not nil and LogErrors or LogErrorData is set. They are
+	// read back from pgxc_copy_error_log after the copy. pgxc_copy_error_log does not identify the copy that logged a
+	// row, so rows are selected by table and by a start time between the start of the copy and the end of the copy.
+	// Rows logged by a concurrent copy from STDIN into the same table, e.g. by another session, that starts within that
+	// window are included too.
+	Rejected *[]CopyFromRejectedRow
+}
+
+// CopyFromRejectedRow is a row rejected by a copy with CopyFromErrorHandling.LogErrors.
+type CopyFromRejectedRow struct {
+	// LineNumber is the line number of the row in the copy data.
+	LineNumber int64
+
+	// RawData is the raw data of the row. It is only recorded with CopyFromErrorHandling.LogErrorData.
+	RawData string
+
+	// Error is the error message explaining why the row was rejected.
+	Error string
+}
+
+func (eh CopyFromErrorHandling) logErrors() bool {
+	return eh.LogErrors || eh.LogErrorData
+}
+
+func (eh CopyFromErrorHandling) validate() error {
+	if eh.RejectLimit != 0 && !eh.logErrors() {
+		return errors.New("RejectLimit requires LogErrors")
+	}
+	return nil
+}
+
+// clause returns the error logging clause that precedes the with options of the copy statement.
+func (eh CopyFromErrorHandling) clause() string {
+	var buf strings.Builder
+	if eh.LogErrorData {
+		buf.WriteString(" log errors data")
+	} else if eh.LogErrors {
+		buf.WriteString(" log errors")
+	}
+	if eh.RejectLimit < 0 {
+		buf.WriteString(" reject limit 'unlimited'")
+	} else if eh.RejectLimit > 0 {
+		buf.WriteString(" reject limit '")
+		buf.WriteString(strconv.FormatInt(eh.RejectLimit, 10))
+		buf.WriteString("'")
+	}
+	return buf.String()
+}
+
+func (eh CopyFromErrorHandling) appendCopyOptions(options [][2]string) [][2]string {
+	if eh.IgnoreExtraData {
+		options = append(options, [2]string{"ignore_extra_data", "true"})
+	}
+	if eh.FillMissingFields {
+		options = append(options, [2]string{"fill_missing_fields", "true"})
+	}
+	return options
+}
+
+// copyErrorReport reads the rows a copy recorded in pgxc_copy_error_log.
+type copyErrorReport struct {
+	conn      *Conn
+	relName   string
+	beginTime time.Time
+}
+
+// beginCopyErrorReport must be called immediately before the copy into tableName starts and read immediately after it
+// ends to keep the window in which rows of other copies are included small.
+func beginCopyErrorReport(ctx context.Context, conn *Conn, tableName Identifier) (*copyErrorReport, error) {
+	report := &copyErrorReport{conn: conn}
+
+	// pgxc_copy_error_log identifies the table by its schema qualified name and the copy by its start time. Unlike now()
+	// clock_timestamp() is not the start of the transaction so earlier copies in the same transaction are excluded.
+	err := conn.QueryRow(ctx, `select n.nspname || '.' || c.relname, clock_timestamp()
+from pg_catalog.pg_class c
+join pg_catalog.pg_namespace n on n.oid = c.relnamespace
+where c.oid = $1::text::regclass`, tableName.Sanitize()).Scan(&report.relName, &report.beginTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (r *copyErrorReport) read(ctx context.Context, rejected *[]CopyFromRejectedRow) error {
+	rows, _ := r.conn.Query(ctx, `select lineno, coalesce(rawrecord, ''), coalesce(detail, '')
+from pgxc_copy_error_log
+where relname = $1 and filename = 'STDIN' and begintime between $2 and clock_timestamp()
+order by begintime, lineno`, r.relName, r.beginTime)
+
+	rejectedRows, err := CollectRows(rows, RowToStructByPos[CopyFromRejectedRow])
+	if err != nil {
+		return err
+	}
+
+	*rejected = rejectedRows
+	return nil
+}
@@ -0,0 +1,278 @@
+package gaussdbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CopyFromMergeAction is what CopyFromMerge does with a row whose conflict columns match a row of the target table.
+type CopyFromMergeAction int
+
+const (
+	// CopyFromMergeUpdate updates the matching row of the target table.
+	CopyFromMergeUpdate CopyFromMergeAction = iota
+
+	// CopyFromMergeDoNothing keeps the matching row of the target table unchanged.
+	CopyFromMergeDoNothing
+)
+
+// CopyFromMergeOptions are the options of CopyFromMerge.
+type CopyFromMergeOptions struct {
+	// ConflictColumns identify a row. A copied row matches a row of the target table if all conflict columns are equal.
+	// They must be a subset of the copied columns and must not be NULL. It is required.
+	ConflictColumns []string
+
+	// OnConflict is the action for copied rows that match a row of the target table. The default is
+	// CopyFromMergeUpdate.
+	OnConflict CopyFromMergeAction
+
+	// UpdateColumns are the columns set by CopyFromMergeUpdate. The default is all copied columns that are not conflict
+	// columns.
+	UpdateColumns []string
+
+	// DeleteMissing deletes the rows of the target table that match no copied row.
+	DeleteMissing bool
+}
+
+// CopyFromMergeResult is the number of rows of the target table changed by CopyFromMerge.
+type CopyFromMergeResult struct {
+	Inserted int64
+	Updated  int64
+	Deleted  int64
+}
+
+// copyFromMergeStagingPrefix is the prefix of the name of the temporary table that holds the copied rows.
+const copyFromMergeStagingPrefix = "gaussdbgo_merge_"
+
+type copyFromMerge struct {
+	tableName     Identifier
+	columnNames   []string
+	rowSrc        CopyFromSource
+	opts          CopyFromMergeOptions
+	updateColumns []string
+
+	// tableOID is the OID of the target table. It is resolved at the start of run.
+	tableOID uint32
+}
+
+func newCopyFromMerge(tableName Identifier, columnNames []string, rowSrc CopyFromSource, opts CopyFromMergeOptions) (*copyFromMerge, error) {
+	if len(columnNames) == 0 {
+		return nil, errors.New("columnNames must not be empty")
+	}
+	if len(opts.ConflictColumns) == 0 {
+		return nil, errors.New("ConflictColumns must not be empty")
+	}
+	for _, cn := range opts.ConflictColumns {
+		if !slices.Contains(columnNames, cn) {
+			return nil, fmt.Errorf("conflict column %s is not a copied column", cn)
+		}
+	}
+
+	updateColumns := opts.UpdateColumns
+	if updateColumns == nil {
+		for _, cn := range columnNames {
+			if !slices.Contains(opts.ConflictColumns, cn) {
+				updateColumns = append(updateColumns, cn)
+			}
+		}
+	}
+	for _, cn := range updateColumns {
+		if !slices.Contains(columnNames, cn) {
+			return nil, fmt.Errorf("update column %s is not a copied column", cn)
+		}
+		if slices.Contains(opts.ConflictColumns, cn) {
+			return nil, fmt.Errorf("update column %s is a conflict column", cn)
+		}
+	}
+
+	switch opts.OnConflict {
+	case CopyFromMergeUpdate, CopyFromMergeDoNothing:
+	default:
+		return nil, fmt.Errorf("unknown CopyFromMergeAction: %v", opts.OnConflict)
+	}
+
+	return &copyFromMerge{
+		tableName:     tableName,
+		columnNames:   columnNames,
+		rowSrc:        rowSrc,
+		opts:          opts,
+		updateColumns: updateColumns,
+	}, nil
+}
+
+// stagingTableName is derived from the OID of the target table so that distinct targets never share a staging table.
+func (cm *copyFromMerge) stagingTableName() Identifier {
+	return Identifier{copyFromMergeStagingPrefix + strconv.FormatUint(uint64(cm.tableOID), 10)}
+}
+
+func (cm *copyFromMerge) createStagingSQL() string {
+	return fmt.Sprintf("create temporary table %s as select %s from %s where false",
+		cm.stagingTableName().Sanitize(), quoteColumnList("", cm.columnNames), cm.tableName.Sanitize())
+}
+
+// lockSQL locks the target table against concurrent writes, including other merges, but not against reads.
+func (cm *copyFromMerge) lockSQL() string {
+	return "lock table " + cm.tableName.Sanitize() + " in share row exclusive mode"
+}
+
+func (cm *copyFromMerge) updateSQL() string {
+	sets := make([]string, len(cm.updateColumns))
+	for i, cn := range cm.updateColumns {
+		sets[i] = quoteIdentifier(cn) + " = s." + quoteIdentifier(cn)
+	}
+	return fmt.Sprintf("update %s t set %s from %s s where %s",
+		cm.tableName.Sanitize(), strings.Join(sets, ", "), cm.stagingTableName().Sanitize(), cm.matchCondition())
+}
+
+func (cm *copyFromMerge) insertSQL() string {
+	return fmt.Sprintf("insert into %s (%s) select %s from %s s where not exists (select 1 from %s t where %s)",
+		cm.tableName.Sanitize(), quoteColumnList("", cm.columnNames), quoteColumnList("s.", cm.columnNames),
+		cm.stagingTableName().Sanitize(), cm.tableName.Sanitize(), cm.matchCondition())
+}
+
+func (cm *copyFromMerge) deleteSQL() string {
+	return fmt.Sprintf("delete from %s t where not exists (select 1 from %s s where %s)",
+		cm.tableName.Sanitize(), cm.stagingTableName().Sanitize(), cm.matchCondition())
+}
+
+// matchCondition matches the target table t and the staging table s on the conflict columns.
+func (cm *copyFromMerge) matchCondition() string {
+	conditions := make([]string, len(cm.opts.ConflictColumns))
+	for i, cn := range cm.opts.ConflictColumns {
+		conditions[i] = "t." + quoteIdentifier(cn) + " = s." + quoteIdentifier(cn)
+	}
+	return strings.Join(conditions, " and ")
+}
+
+func (cm *copyFromMerge) run(ctx context.Context, tx Tx) (CopyFromMergeResult, error) {
+	var result CopyFromMergeResult
+
+	err := tx.QueryRow(ctx, "select $1::text::regclass::oid", cm.tableName.Sanitize()).Scan(&cm.tableOID)
+	if err != nil {
+		return result, err
+	}
+
+	_, err = tx.Exec(ctx, cm.createStagingSQL())
+	if err != nil {
+		return result, err
+	}
+
+	err = cm.copy(ctx, tx.Conn())
+	if err != nil {
+		return result, err
+	}
+
+	// Without the lock concurrent merges could both insert a row for the same key.
+	_, err = tx.Exec(ctx, cm.lockSQL())
+	if err != nil {
+		return result, err
+	}
+
+	// Update before insert so that inserted rows are not counted as updated.
+	if cm.opts.OnConflict == CopyFromMergeUpdate && len(cm.updateColumns) > 0 {
+		commandTag, err := tx.Exec(ctx, cm.updateSQL())
+		if err != nil {
+			return result, err
+		}
+		result.Updated = commandTag.RowsAffected()
+	}
+
+	commandTag, err := tx.Exec(ctx, cm.insertSQL())
+	if err != nil {
+		return result, err
+	}
+	result.Inserted = commandTag.RowsAffected()
+
+	if cm.opts.DeleteMissing {
+		commandTag, err := tx.Exec(ctx, cm.deleteSQL())
+		if err != nil {
+			return result, err
+		}
+		result.Deleted = commandTag.RowsAffected()
+	}
+
+	_, err = tx.Exec(ctx, "drop table "+cm.stagingTableName().Sanitize())
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// copy copies the rows into the staging table. Unlike Conn.CopyFrom it never caches the statement description of the
+// staging table. The staging table is recreated by every merge so a cached description would become stale when the
+// column types of the target table change.
+func (cm *copyFromMerge) copy(ctx context.Context, conn *Conn) error {
+	scope := conn.beginNoticeScope(ctx)
+	defer conn.endNoticeScope(scope)
+
+	ct := &copyFrom{
+		conn:          conn,
+		tableName:     cm.stagingTableName(),
+		columnNames:   cm.columnNames,
+		rowSrc:        cm.rowSrc,
+		readerErrChan: make(chan error),
+		mode:          QueryExecModeDescribeExec,
+	}
+
+	_, err := ct.run(ctx)
+	return err
+}
+
+// copyFromMergeFunc implements CopyFromMerge for all types that can begin a transaction. db is either a *Conn or a Tx
+// in which case a pseudo nested transaction is used.
+func copyFromMergeFunc(
+	ctx context.Context,
+	db interface {
+		Begin(ctx context.Context) (Tx, error)
+	},
+	tableName Identifier,
+	columnNames []string,
+	rowSrc CopyFromSource,
+	opts CopyFromMergeOptions,
+) (CopyFromMergeResult, error) {
+	cm, err := newCopyFromMerge(tableName, columnNames, rowSrc, opts)
+	if err != nil {
+		return CopyFromMergeResult{}, err
+	}
+
+	var result CopyFromMergeResult
+	err = BeginFunc(ctx, db, func(tx Tx) error {
+		var err error
+		result, err = cm.run(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return CopyFromMergeResult{}, err
+	}
+
+	return result, nil
+}
+
+// CopyFromMerge uses the GaussDB copy protocol to merge the rows of rowSrc into tableName. The rows are copied into a
+// temporary staging table with the columnNames of tableName. Rows that do not match a row of tableName on
+// opts.ConflictColumns are inserted. Matching rows are handled according to opts.OnConflict. If opts.DeleteMissing is
+// true the rows of tableName that match no copied row are deleted. Everything runs in one transaction.
+// CopyFromMerge returns the number of rows inserted, updated and deleted and an error.
+//
+// tableName is locked in SHARE ROW EXCLUSIVE mode after the rows are copied until the transaction ends. Concurrent
+// merges and other writes into tableName wait for the lock, so concurrent merges of the same keys do not insert
+// duplicate rows. Reads are not blocked.
+//
+// The conflict columns of the copied rows should be unique. Otherwise a row of tableName is updated with any of the
+// matching rows.
+func (c *Conn) CopyFromMerge(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource, opts CopyFromMergeOptions) (CopyFromMergeResult, error) {
+	return copyFromMergeFunc(ctx, c, tableName, columnNames, rowSrc, opts)
+}
+
+func quoteColumnList(prefix string, columnNames []string) string {
+	quoted := make([]string, len(columnNames))
+	for i, cn := range columnNames {
+		quoted[i] = prefix + quoteIdentifier(cn)
+	}
+	return strings.Join(quoted, ", ")
+}
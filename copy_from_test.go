@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -894,3 +895,208 @@ func TestCopyFromFunc(t *testing.T) {
 
 	ensureConnValid(t, conn)
 }
+
+func TestConnCopyFromCSV(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(
+		a int4,
+		b text,
+		c text
+	)`)
+
+	input := "b;a\nhello;1\n\"semi;colon\";2\nNULL;3\n"
+	copyCount, err := conn.CopyFromCSV(ctx, gaussdbgo.Identifier{"foo"}, []string{"b", "a"}, strings.NewReader(input), gaussdbgo.CopyFromCSVOptions{
+		Delimiter: ";",
+		Null:      "NULL",
+		Header:    true,
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, copyCount)
+
+	rows, _ := conn.Query(ctx, "select a, b from foo order by a")
+	type row struct {
+		A int32
+		B *string
+	}
+	result, err := gaussdbgo.CollectRows(rows, gaussdbgo.RowToStructByPos[row])
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	require.Equal(t, "hello", *result[0].B)
+	require.Equal(t, "semi;colon", *result[1].B)
+	require.Nil(t, result[2].B)
+
+	_, err = conn.CopyFromCSV(ctx, gaussdbgo.Identifier{"foo"}, []string{"a"}, strings.NewReader("not a number\n"), gaussdbgo.CopyFromCSVOptions{})
+	require.Error(t, err)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnCopyFromText(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	defer closeConn(t, conn)
+
+	mustExec(t, conn, `create temporary table foo(a int4, b text)`)
+
+	copyCount, err := conn.CopyFromText(ctx, gaussdbgo.Identifier{"foo"}, nil, strings.NewReader("1\tone\n2\t\\N\n"), gaussdbgo.CopyFromTextOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, copyCount)
+
+	copyCount, err = conn.CopyFromText(ctx, gaussdbgo.Identifier{"foo"}, nil, strings.NewReader("3|three\n4|-\n"), gaussdbgo.CopyFromTextOptions{Delimiter: "|", Null: "-"})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, copyCount)
+
+	var nullCount int64
+	err = conn.QueryRow(ctx, "select count(*) from foo where b is null").Scan(&nullCount)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, nullCount)
+
+	ensureConnValid(t, conn)
+}
+
+func TestConnCopyFromCSVErrorHandling(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn := mustConnectString(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	defer closeConn(t, conn)
+
+	tx, err := conn.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	var errorLogExists bool
+	err = tx.QueryRow(ctx, "select exists(select 1 from pg_class where relname = 'pgxc_copy_error_log')").Scan(&errorLogExists)
+	require.NoError(t, err)
+	if !errorLogExists {
+		_, err = tx.Exec(ctx, "select copy_error_log_create()")
+		require.NoError(t, err)
+	}
+
+	_, err = tx.Exec(ctx, "create table copy_from_csv_error_handling(a int4, b text, c text)")
+	require.NoError(t, err)
+
+	var rejected []gaussdbgo.CopyFromRejectedRow
+	input := "1,one,x\nnot a number,two,y\n3,three\n4,four,z,extra\n"
+	copyCount, err := tx.CopyFromCSV(ctx, gaussdbgo.Identifier{"copy_from_csv_error_handling"}, nil, strings.NewReader(input), gaussdbgo.CopyFromCSVOptions{
+		ErrorHandling: gaussdbgo.CopyFromErrorHandling{
+			LogErrorData:      true,
+			RejectLimit:       -1,
+			IgnoreExtraData:   true,
+			FillMissingFields: true,
+			Rejected:          &rejected,
+		},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, copyCount)
+	require.Len(t, rejected, 1)
+	require.EqualValues(t, 2, rejected[0].LineNumber)
+	require.Equal(t, "not a number,two,y", rejected[0].RawData)
+	require.NotEmpty(t, rejected[0].Error)
+
+	var missingCount int64
+	err = tx.QueryRow(ctx, "select count(*) from copy_from_csv_error_handling where c is null").Scan(&missingCount)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, missingCount)
+
+	// Rows rejected by the earlier copy in the same transaction are not reported again.
+	rejected = nil
+	copyCount, err = tx.CopyFromCSV(ctx, gaussdbgo.Identifier{"copy_from_csv_error_handling"}, nil, strings.NewReader("5,five,q\nbad,six,r\n"), gaussdbgo.CopyFromCSVOptions{
+		ErrorHandling: gaussdbgo.CopyFromErrorHandling{
+			LogErrorData: true,
+			RejectLimit:  -1,
+			Rejected:     &rejected,
+		},
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, copyCount)
+	require.Len(t, rejected, 1)
+	require.Equal(t, "bad,six,r", rejected[0].RawData)
+
+	_, err = tx.CopyFromCSV(ctx, gaussdbgo.Identifier{"copy_from_csv_error_handling"}, nil, strings.NewReader(input), gaussdbgo.CopyFromCSVOptions{
+		ErrorHandling: gaussdbgo.CopyFromErrorHandling{RejectLimit: 1},
+	})
+	require.EqualError(t, err, "RejectLimit requires LogErrors")
+
+	require.NoError(t, tx.Rollback(ctx))
+	ensureConnValid(t, conn)
+}
+
+func TestConnCopyFromMerge(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *gaussdbgo.Conn) {
+		mustExec(t, conn, `create temporary table foo(id int4 primary key, a text, b text)`)
+		mustExec(t, conn, `insert into foo values (1, 'one', 'x'), (2, 'two', 'y'), (3, 'three', 'z')`)
+
+		result, err := conn.CopyFromMerge(ctx, gaussdbgo.Identifier{"foo"}, []string{"id", "a", "b"}, gaussdbgo.CopyFromRows([][]any{
+			{int32(2), "TWO", "Y"},
+			{int32(3), "THREE", "Z"},
+			{int32(4), "four", "w"},
+		}), gaussdbgo.CopyFromMergeOptions{
+			ConflictColumns: []string{"id"},
+			UpdateColumns:   []string{"a"},
+			DeleteMissing:   true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, gaussdbgo.CopyFromMergeResult{Inserted: 1, Updated: 2, Deleted: 1}, result)
+
+		rows, _ := conn.Query(ctx, "select id, a, b from foo order by id")
+		type row struct {
+			ID int32
+			A  string
+			B  string
+		}
+		merged, err := gaussdbgo.CollectRows(rows, gaussdbgo.RowToStructByPos[row])
+		require.NoError(t, err)
+		require.Equal(t, []row{{2, "TWO", "y"}, {3, "THREE", "z"}, {4, "four", "w"}}, merged)
+
+		result, err = conn.CopyFromMerge(ctx, gaussdbgo.Identifier{"foo"}, []string{"id", "a", "b"}, gaussdbgo.CopyFromRows([][]any{
+			{int32(4), "FOUR", "W"},
+			{int32(5), "five", "v"},
+		}), gaussdbgo.CopyFromMergeOptions{
+			ConflictColumns: []string{"id"},
+			OnConflict:      gaussdbgo.CopyFromMergeDoNothing,
+		})
+		require.NoError(t, err)
+		require.Equal(t, gaussdbgo.CopyFromMergeResult{Inserted: 1}, result)
+
+		// A failed merge leaves the table unchanged.
+		_, err = conn.CopyFromMerge(ctx, gaussdbgo.Identifier{"foo"}, []string{"id", "a", "b"}, gaussdbgo.CopyFromRows([][]any{
+			{int32(6), "six", "u"},
+			{nil, "null", "id"},
+		}), gaussdbgo.CopyFromMergeOptions{ConflictColumns: []string{"id"}})
+		require.Error(t, err)
+
+		var count int64
+		err = conn.QueryRow(ctx, "select count(*) from foo").Scan(&count)
+		require.NoError(t, err)
+		require.EqualValues(t, 4, count)
+
+		// The staging table follows a change of the column types of the target table.
+		mustExec(t, conn, "alter table foo alter column b type int4 using length(b)")
+		result, err = conn.CopyFromMerge(ctx, gaussdbgo.Identifier{"foo"}, []string{"id", "a", "b"}, gaussdbgo.CopyFromRows([][]any{
+			{int32(5), "FIVE", int32(42)},
+		}), gaussdbgo.CopyFromMergeOptions{ConflictColumns: []string{"id"}})
+		require.NoError(t, err)
+		require.Equal(t, gaussdbgo.CopyFromMergeResult{Updated: 1}, result)
+
+		mustExec(t, conn, "drop table foo")
+	})
+}
@@ -0,0 +1,127 @@
+package gaussdbgo
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbtype"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/gaussdbio"
+	"github.com/stretchr/testify/require"
+)
+
+func buildBinaryCopyData(t *testing.T, m *gaussdbtype.Map, oids []uint32, rows [][]any) []byte {
+	buf := append([]byte{}, copyBinarySignature...)
+	buf = gaussdbio.AppendInt32(buf, 0)
+	buf = gaussdbio.AppendInt32(buf, 0)
+	for _, row := range rows {
+		buf = gaussdbio.AppendInt16(buf, int16(len(row)))
+		for i, value := range row {
+			var err error
+			buf, err = encodeCopyValue(m, buf, oids[i], value)
+			require.NoError(t, err)
+		}
+	}
+	return gaussdbio.AppendInt16(buf, -1)
+}
+
+// The server may split the copy stream at any position.
+func TestCopyToDecoderSplitsStream(t *testing.T) {
+	m := gaussdbtype.NewMap()
+	fields := []gaussdbconn.FieldDescription{
+		{Name: "id", DataTypeOID: gaussdbtype.Int4OID},
+		{Name: "name", DataTypeOID: gaussdbtype.TextOID},
+	}
+	inputRows := [][]any{{int32(1), "foo"}, {int32(2), nil}, {nil, "bar,baz"}}
+	data := buildBinaryCopyData(t, m, []uint32{gaussdbtype.Int4OID, gaussdbtype.TextOID}, inputRows)
+
+	for chunkSize := 1; chunkSize <= len(data); chunkSize++ {
+		var rows [][]any
+		dst := CopyToRows(&rows)
+		require.NoError(t, dst.Start(m, fields))
+		d := &copyToDecoder{typeMap: m, fields: fields, dst: dst}
+		for i := 0; i < len(data); i += chunkSize {
+			_, err := d.Write(data[i:min(i+chunkSize, len(data))])
+			require.NoError(t, err)
+		}
+		require.NoError(t, d.err)
+		require.True(t, d.done)
+		require.Empty(t, d.buf)
+		require.EqualValues(t, len(inputRows), d.rowCount)
+		require.Equal(t, inputRows, rows)
+	}
+}
+
+func TestCopyToCSV(t *testing.T) {
+	m := gaussdbtype.NewMap()
+	fields := []gaussdbconn.FieldDescription{
+		{Name: "id", DataTypeOID: gaussdbtype.Int4OID},
+		{Name: "name", DataTypeOID: gaussdbtype.TextOID},
+	}
+
+	var buf bytes.Buffer
+	dst := CopyToCSV(csv.NewWriter(&buf), true)
+	require.NoError(t, dst.Start(m, fields))
+	require.NoError(t, dst.Row([]any{int32(1), "foo"}))
+	require.NoError(t, dst.Row([]any{nil, "bar,baz"}))
+	require.NoError(t, dst.(interface{ Flush() error }).Flush())
+	require.Equal(t, "id,name\n1,foo\n,\"bar,baz\"\n", buf.String())
+}
+
+func TestCopyFromReaderSQL(t *testing.T) {
+	ct := &copyFromReader{
+		tableName:   Identifier{"public", "foo"},
+		columnNames: []string{"a", `b"c`},
+		options: CopyFromCSVOptions{
+			Delimiter: ";",
+			Quote:     `'`,
+			Null:      "NULL",
+			Header:    true,
+		}.copyOptions(),
+	}
+	require.Equal(t, `copy "public"."foo" ( "a", "b""c" ) from stdin with (format 'csv', delimiter ';', quote '''', null 'NULL', header 'true')`, ct.sql())
+
+	ct = &copyFromReader{tableName: Identifier{"foo"}, options: CopyFromTextOptions{Null: `\N`}.copyOptions()}
+	require.Equal(t, `copy "foo" from stdin with (format 'text', null '\N')`, ct.sql())
+
+	opts := CopyFromTextOptions{ErrorHandling: CopyFromErrorHandling{
+		LogErrorData:      true,
+		RejectLimit:       -1,
+		IgnoreExtraData:   true,
+		FillMissingFields: true,
+	}}
+	ct = &copyFromReader{tableName: Identifier{"foo"}, options: opts.copyOptions(), errorHandling: opts.ErrorHandling}
+	require.Equal(t, `copy "foo" from stdin log errors data reject limit 'unlimited' with (format 'text', ignore_extra_data 'true', fill_missing_fields 'true')`, ct.sql())
+
+	ct.errorHandling = CopyFromErrorHandling{LogErrors: true, RejectLimit: 10}
+	require.Equal(t, `copy "foo" from stdin log errors reject limit '10' with (format 'text', ignore_extra_data 'true', fill_missing_fields 'true')`, ct.sql())
+
+	require.EqualError(t, CopyFromErrorHandling{RejectLimit: 10}.validate(), "RejectLimit requires LogErrors")
+}
+
+func TestCopyFromMergeSQL(t *testing.T) {
+	cm, err := newCopyFromMerge(Identifier{"public", "foo"}, []string{"id", "a", "b"}, nil, CopyFromMergeOptions{ConflictColumns: []string{"id"}})
+	require.NoError(t, err)
+	cm.tableOID = 16384
+	require.Equal(t, Identifier{"gaussdbgo_merge_16384"}, cm.stagingTableName())
+	require.Equal(t, `create temporary table "gaussdbgo_merge_16384" as select "id", "a", "b" from "public"."foo" where false`, cm.createStagingSQL())
+	require.Equal(t, `lock table "public"."foo" in share row exclusive mode`, cm.lockSQL())
+	require.Equal(t, `update "public"."foo" t set "a" = s."a", "b" = s."b" from "gaussdbgo_merge_16384" s where t."id" = s."id"`, cm.updateSQL())
+	require.Equal(t, `insert into "public"."foo" ("id", "a", "b") select s."id", s."a", s."b" from "gaussdbgo_merge_16384" s where not exists (select 1 from "public"."foo" t where t."id" = s."id")`, cm.insertSQL())
+	require.Equal(t, `delete from "public"."foo" t where not exists (select 1 from "gaussdbgo_merge_16384" s where t."id" = s."id")`, cm.deleteSQL())
+
+	cm, err = newCopyFromMerge(Identifier{"foo"}, []string{"x", "y", "a", "b"}, nil, CopyFromMergeOptions{ConflictColumns: []string{"x", "y"}, UpdateColumns: []string{"b"}})
+	require.NoError(t, err)
+	cm.tableOID = 16385
+	require.Equal(t, `update "foo" t set "b" = s."b" from "gaussdbgo_merge_16385" s where t."x" = s."x" and t."y" = s."y"`, cm.updateSQL())
+
+	_, err = newCopyFromMerge(Identifier{"foo"}, []string{"id", "a"}, nil, CopyFromMergeOptions{})
+	require.EqualError(t, err, "ConflictColumns must not be empty")
+
+	_, err = newCopyFromMerge(Identifier{"foo"}, []string{"id", "a"}, nil, CopyFromMergeOptions{ConflictColumns: []string{"missing"}})
+	require.EqualError(t, err, "conflict column missing is not a copied column")
+
+	_, err = newCopyFromMerge(Identifier{"foo"}, []string{"id", "a"}, nil, CopyFromMergeOptions{ConflictColumns: []string{"id"}, UpdateColumns: []string{"id"}})
+	require.EqualError(t, err, "update column id is a conflict column")
+}
@@ -0,0 +1,361 @@
+package gaussdbgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"errors"
+	"fmt"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbtype"
+)
+
+// CopyToDestination is the interface used by *Conn.CopyTo as the destination for copy data.
+type CopyToDestination interface {
+	// Start is called once before the first row with the type map used to decode the values and the descriptions of the
+	// copied columns.
+	Start(typeMap *gaussdbtype.Map, fields []gaussdbconn.FieldDescription) error
+
+	// Row is called for every copied row with the decoded values. NULL is decoded as nil. The values may be retained. If
+	// Row returns an error *Conn.CopyTo discards the remaining rows and returns the error.
+	Row(values []any) error
+}
+
+// CopyToRows returns a CopyToDestination that appends every copied row to rows.
+func CopyToRows(rows *[][]any) CopyToDestination {
+	return &copyToRows{rows: rows}
+}
+
+type copyToRows struct {
+	rows *[][]any
+}
+
+func (ctr *copyToRows) Start(*gaussdbtype.Map, []gaussdbconn.FieldDescription) error {
+	return nil
+}
+
+func (ctr *copyToRows) Row(values []any) error {
+	*ctr.rows = append(*ctr.rows, values)
+	return nil
+}
+
+// CopyToFunc returns a CopyToDestination that calls fn with every copied row.
+func CopyToFunc(fn func(values []any) error) CopyToDestination {
+	return &copyToFunc{fn: fn}
+}
+
+type copyToFunc struct {
+	fn func([]any) error
+}
+
+func (ctf *copyToFunc) Start(*gaussdbtype.Map, []gaussdbconn.FieldDescription) error {
+	return nil
+}
+
+func (ctf *copyToFunc) Row(values []any) error {
+	return ctf.fn(values)
+}
+
+// CopyToCSV returns a CopyToDestination that writes every copied row as a record to w. Values are written in the
+// GaussDB text format and NULL is written as an empty field. If header is true the column names are written as the
+// first record. w is flushed after the last row. Check w.Error for write errors.
+func CopyToCSV(w *csv.Writer, header bool) CopyToDestination {
+	return &copyToCSV{w: w, header: header}
+}
+
+type copyToCSV struct {
+	w      *csv.Writer
+	header bool
+
+	typeMap *gaussdbtype.Map
+	fields  []gaussdbconn.FieldDescription
+	record  []string
+}
+
+func (ctc *copyToCSV) Start(typeMap *gaussdbtype.Map, fields []gaussdbconn.FieldDescription) error {
+	ctc.typeMap = typeMap
+	ctc.fields = fields
+	ctc.record = make([]string, len(fields))
+
+	if ctc.header {
+		for i := range fields {
+			ctc.record[i] = fields[i].Name
+		}
+		return ctc.w.Write(ctc.record)
+	}
+
+	return nil
+}
+
+func (ctc *copyToCSV) Row(values []any) error {
+	for i, value := range values {
+		if value == nil {
+			ctc.record[i] = ""
+			continue
+		}
+
+		buf, err := ctc.typeMap.Encode(ctc.fields[i].DataTypeOID, TextFormatCode, value, nil)
+		if err != nil {
+			return fmt.Errorf("failed to encode column %s: %w", ctc.fields[i].Name, err)
+		}
+		ctc.record[i] = string(buf)
+	}
+
+	return ctc.w.Write(ctc.record)
+}
+
+func (ctc *copyToCSV) Flush() error {
+	ctc.w.Flush()
+	return ctc.w.Error()
+}
+
+// copyBinarySignature starts every stream in the binary COPY format.
+var copyBinarySignature = []byte("PGCOPY\n\377\r\n\000")
+
+// copyToDecoder is the io.Writer that receives the binary COPY stream. It decodes every complete row and passes it to
+// dst. After the first error the rest of the stream is discarded so the connection remains usable.
+type copyToDecoder struct {
+	typeMap *gaussdbtype.Map
+	fields  []gaussdbconn.FieldDescription
+	dst     CopyToDestination
+
+	buf           []byte
+	headerDecoded bool
+	done          bool
+	rowCount      int64
+	err           error
+}
+
+func (d *copyToDecoder) Write(p []byte) (int, error) {
+	if d.err != nil || d.done {
+		return len(p), nil
+	}
+
+	d.buf = append(d.buf, p...)
+	d.err = d.decode()
+	return len(p), nil
+}
+
+// decode decodes the complete rows in d.buf and removes them from d.buf.
+func (d *copyToDecoder) decode() error {
+	rp := 0
+	defer func() {
+		d.buf = d.buf[:copy(d.buf, d.buf[rp:])]
+	}()
+
+	if !d.headerDecoded {
+		// Signature, flags and header extension length.
+		if len(d.buf) < len(copyBinarySignature)+8 {
+			return nil
+		}
+		if !bytes.Equal(d.buf[:len(copyBinarySignature)], copyBinarySignature) {
+			return errors.New("invalid binary copy signature")
+		}
+		extLen := int(binary.BigEndian.Uint32(d.buf[len(copyBinarySignature)+4:]))
+		headerLen := len(copyBinarySignature) + 8 + extLen
+		if len(d.buf) < headerLen {
+			return nil
+		}
+		rp = headerLen
+		d.headerDecoded = true
+	}
+
+	for len(d.buf)-rp >= 2 {
+		fieldCount := int16(binary.BigEndian.Uint16(d.buf[rp:]))
+		if fieldCount == -1 {
+			rp = len(d.buf)
+			d.done = true
+			return nil
+		}
+		if int(fieldCount) != len(d.fields) {
+			return fmt.Errorf("expected %d columns in copy data, got %d", len(d.fields), fieldCount)
+		}
+
+		// Find the end of the row before decoding anything.
+		end := rp + 2
+		complete := true
+		for i := 0; i < len(d.fields); i++ {
+			if len(d.buf)-end < 4 {
+				complete = false
+				break
+			}
+			n := int32(binary.BigEndian.Uint32(d.buf[end:]))
+			end += 4
+			if n > 0 {
+				end += int(n)
+			}
+		}
+		if !complete || end > len(d.buf) {
+			return nil
+		}
+
+		values := make([]any, len(d.fields))
+		pos := rp + 2
+		for i := range d.fields {
+			n := int32(binary.BigEndian.Uint32(d.buf[pos:]))
+			pos += 4
+			if n < 0 {
+				continue
+			}
+			src := d.buf[pos : pos+int(n)]
+			pos += int(n)
+
+			value, err := d.decodeValue(&d.fields[i], src)
+			if err != nil {
+				return fmt.Errorf("failed to decode column %s: %w", d.fields[i].Name, err)
+			}
+			values[i] = value
+		}
+		rp = end
+
+		err := d.dst.Row(values)
+		if err != nil {
+			return err
+		}
+		d.rowCount++
+	}
+
+	return nil
+}
+
+func (d *copyToDecoder) decodeValue(fd *gaussdbconn.FieldDescription, src []byte) (any, error) {
+	if dt, ok := d.typeMap.TypeForOID(fd.DataTypeOID); ok {
+		return dt.Codec.DecodeValue(d.typeMap, fd.DataTypeOID, BinaryFormatCode, src)
+	}
+
+	buf := make([]byte, len(src))
+	copy(buf, src)
+	return buf, nil
+}
+
+type copyTo struct {
+	conn         *Conn
+	tableOrQuery any
+	columnNames  []string
+	dst          CopyToDestination
+	mode         QueryExecMode
+}
+
+func (ct *copyTo) run(ctx context.Context) (int64, error) {
+	var tableName Identifier
+	var query string
+	switch source := ct.tableOrQuery.(type) {
+	case Identifier:
+		tableName = source
+	case string:
+		query = source
+	default:
+		return 0, fmt.Errorf("tableOrQuery must be an Identifier or a string, got %T", ct.tableOrQuery)
+	}
+
+	if ct.conn.copyToTracer != nil {
+		ctx = ct.conn.copyToTracer.TraceCopyToStart(ctx, ct.conn, TraceCopyToStartData{
+			TableName:   tableName,
+			ColumnNames: ct.columnNames,
+			SQL:         query,
+		})
+	}
+
+	commandTag, err := ct.copy(ctx, tableName, query)
+
+	if ct.conn.copyToTracer != nil {
+		ct.conn.copyToTracer.TraceCopyToEnd(ctx, ct.conn, TraceCopyToEndData{
+			CommandTag: commandTag,
+			Err:        err,
+		})
+	}
+
+	return commandTag.RowsAffected(), err
+}
+
+func (ct *copyTo) copy(ctx context.Context, tableName Identifier, query string) (gaussdbconn.CommandTag, error) {
+	var selectSQL, copySQL string
+	if query != "" {
+		if ct.columnNames != nil {
+			return gaussdbconn.CommandTag{}, errors.New("columnNames must be nil when copying a query")
+		}
+		selectSQL = query
+		copySQL = fmt.Sprintf("copy (%s) to stdout binary", query)
+	} else {
+		quotedTableName := tableName.Sanitize()
+		quotedColumnNames := "*"
+		columnList := ""
+		if len(ct.columnNames) > 0 {
+			cbuf := &bytes.Buffer{}
+			for i, cn := range ct.columnNames {
+				if i != 0 {
+					cbuf.WriteString(", ")
+				}
+				cbuf.WriteString(quoteIdentifier(cn))
+			}
+			quotedColumnNames = cbuf.String()
+			columnList = " ( " + quotedColumnNames + " )"
+		}
+		selectSQL = fmt.Sprintf("select %s from %s", quotedColumnNames, quotedTableName)
+		copySQL = fmt.Sprintf("copy %s%s to stdout binary", quotedTableName, columnList)
+	}
+
+	switch ct.mode {
+	case QueryExecModeExec, QueryExecModeSimpleProtocol:
+		// The binary format requires the column OIDs. See copyFrom.run.
+		ct.mode = QueryExecModeDescribeExec
+	case QueryExecModeCacheStatement, QueryExecModeCacheDescribe, QueryExecModeDescribeExec:
+	default:
+		return gaussdbconn.CommandTag{}, fmt.Errorf("unknown QueryExecMode: %v", ct.mode)
+	}
+
+	sd, err := ct.conn.getStatementDescription(ctx, ct.mode, selectSQL)
+	if err != nil {
+		return gaussdbconn.CommandTag{}, fmt.Errorf("statement description failed: %w", err)
+	}
+
+	err = ct.dst.Start(ct.conn.typeMap, sd.Fields)
+	if err != nil {
+		return gaussdbconn.CommandTag{}, err
+	}
+
+	d := &copyToDecoder{typeMap: ct.conn.typeMap, fields: sd.Fields, dst: ct.dst}
+	commandTag, err := ct.conn.gaussdbConn.CopyTo(ctx, d, copySQL)
+	if err != nil {
+		return commandTag, err
+	}
+	if d.err != nil {
+		return gaussdbconn.CommandTag{}, d.err
+	}
+	if !d.done || len(d.buf) > 0 {
+		return gaussdbconn.CommandTag{}, errors.New("incomplete binary copy data")
+	}
+
+	if flusher, ok := ct.dst.(interface{ Flush() error }); ok {
+		err = flusher.Flush()
+		if err != nil {
+			return gaussdbconn.CommandTag{}, err
+		}
+	}
+
+	return commandTag, nil
+}
+
+// CopyTo uses the GaussDB copy protocol to read a table or the result of a query in bulk. tableOrQuery is either an
+// Identifier for a table or a string with a query. columnNames selects the columns of a table. All columns are copied
+// if it is nil. It must be nil for a query. Every row is decoded and passed to dst. CopyTo returns the number of rows
+// copied and an error.
+//
+// CopyTo uses the binary format. A gaussdbtype.Type that supports the binary format should be registered for the type
+// of each column. Values of other types are passed to dst as []byte.
+func (c *Conn) CopyTo(ctx context.Context, tableOrQuery any, columnNames []string, dst CopyToDestination) (int64, error) {
+	scope := c.beginNoticeScope(ctx)
+	defer c.endNoticeScope(scope)
+
+	ct := &copyTo{
+		conn:         c,
+		tableOrQuery: tableOrQuery,
+		columnNames:  columnNames,
+		dst:          dst,
+		mode:         c.config.DefaultQueryExecMode,
+	}
+
+	return ct.run(ctx)
+}
@@ -0,0 +1,78 @@
+package gaussdbgo_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnCopyTo(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *gaussdbgo.Conn) {
+		mustExec(t, conn, `create temporary table foo(
+			a int2,
+			b int4,
+			c int8,
+			d text
+		)`)
+		mustExec(t, conn, `insert into foo values (0, 1, 2, 'abc'), (null, null, null, null)`)
+
+		var rows [][]any
+		copyCount, err := conn.CopyTo(ctx, gaussdbgo.Identifier{"foo"}, nil, gaussdbgo.CopyToRows(&rows))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, copyCount)
+		require.Equal(t, [][]any{{int16(0), int32(1), int64(2), "abc"}, {nil, nil, nil, nil}}, rows)
+
+		rows = nil
+		copyCount, err = conn.CopyTo(ctx, gaussdbgo.Identifier{"foo"}, []string{"d", "b"}, gaussdbgo.CopyToRows(&rows))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, copyCount)
+		require.Equal(t, [][]any{{"abc", int32(1)}, {nil, nil}}, rows)
+
+		var buf bytes.Buffer
+		copyCount, err = conn.CopyTo(ctx, "select b, d from foo order by b", nil, gaussdbgo.CopyToCSV(csv.NewWriter(&buf), true))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, copyCount)
+		require.Equal(t, "b,d\n1,abc\n,\n", buf.String())
+
+		_, err = conn.CopyTo(ctx, "select b, d from foo", []string{"b"}, gaussdbgo.CopyToRows(&rows))
+		require.Error(t, err)
+
+		ensureConnValid(t, conn)
+	})
+}
+
+func TestConnCopyToDestinationError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *gaussdbgo.Conn) {
+		errStop := errors.New("stop")
+		rowCount := 0
+		_, err := conn.CopyTo(ctx, "select generate_series(1, 100)", nil, gaussdbgo.CopyToFunc(func(values []any) error {
+			rowCount++
+			if rowCount == 3 {
+				return errStop
+			}
+			return nil
+		}))
+		require.ErrorIs(t, err, errStop)
+		require.Equal(t, 3, rowCount)
+
+		// The rest of the copy data is discarded and the connection remains usable.
+		ensureConnValid(t, conn)
+	})
+}
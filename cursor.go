@@ -0,0 +1,99 @@
+package gaussdbgo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// CursorOptions are the options of a cursor declared with Tx.DeclareCursor.
+type CursorOptions struct {
+	// Scroll allows the cursor to move backwards, e.g. with a negative count for Fetch or Move.
+	Scroll bool
+
+	// Hold keeps the cursor open after the transaction that declared it commits. Cursor methods are only valid while the
+	// transaction is open. Afterwards the cursor can be used by name on the same connection until it is closed, e.g.
+	// with "fetch 100 from " + Identifier{cursor.Name()}.Sanitize().
+	Hold bool
+}
+
+func (opts CursorOptions) declareSQL(name, sql string) string {
+	var buf strings.Builder
+	buf.WriteString("declare ")
+	buf.WriteString(Identifier{name}.Sanitize())
+	if opts.Scroll {
+		buf.WriteString(" scroll")
+	}
+	buf.WriteString(" cursor")
+	if opts.Hold {
+		buf.WriteString(" with hold")
+	}
+	buf.WriteString(" for ")
+	buf.WriteString(sql)
+	return buf.String()
+}
+
+// Cursor is a server-side cursor declared with Tx.DeclareCursor. It is only valid within the transaction where it was
+// declared.
+type Cursor struct {
+	tx   Tx
+	name string
+}
+
+// declareCursor declares a cursor on tx. It implements Tx.DeclareCursor for all Tx implementations.
+func declareCursor(ctx context.Context, tx Tx, name, sql string, args []any, opts CursorOptions) (*Cursor, error) {
+	if name == "" {
+		return nil, errors.New("cursor name must not be empty")
+	}
+
+	_, err := tx.Exec(ctx, opts.declareSQL(name, sql), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{tx: tx, name: name}, nil
+}
+
+// Name returns the name of the cursor.
+func (c *Cursor) Name() string {
+	return c.name
+}
+
+// Fetch fetches the next n rows of the cursor. A negative n fetches the previous -n rows. This requires
+// CursorOptions.Scroll. The returned Rows must be closed before the connection is used again. Fewer than n rows are
+// returned when the end of the cursor is reached.
+func (c *Cursor) Fetch(ctx context.Context, n int64) (Rows, error) {
+	// The columns of a FETCH depend on the cursor. Its statement description must not be cached as a cursor with the
+	// same name may later be declared for another query.
+	mode := QueryExecModeDescribeExec
+	if c.tx.Conn().config.DefaultQueryExecMode == QueryExecModeSimpleProtocol {
+		mode = QueryExecModeSimpleProtocol
+	}
+
+	return c.tx.Query(ctx, "fetch "+c.direction(n)+" from "+Identifier{c.name}.Sanitize(), mode)
+}
+
+// Move repositions the cursor by n rows without fetching them. A negative n moves backwards. This requires
+// CursorOptions.Scroll. It returns the number of rows the cursor moved over.
+func (c *Cursor) Move(ctx context.Context, n int64) (int64, error) {
+	commandTag, err := c.tx.Exec(ctx, "move "+c.direction(n)+" in "+Identifier{c.name}.Sanitize())
+	if err != nil {
+		return 0, err
+	}
+	return commandTag.RowsAffected(), nil
+}
+
+// Close closes the cursor. A cursor is closed automatically when the transaction ends unless it was declared with
+// CursorOptions.Hold.
+func (c *Cursor) Close(ctx context.Context) error {
+	_, err := c.tx.Exec(ctx, "close "+Identifier{c.name}.Sanitize())
+	return err
+}
+
+func (c *Cursor) direction(n int64) string {
+	if n < 0 {
+		return "backward " + strconv.FormatInt(-n, 10)
+	}
+	return "forward " + strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,70 @@
+package gaussdbgo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbxtest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxDeclareCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, nil, func(ctx context.Context, t testing.TB, conn *gaussdbgo.Conn) {
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		defer tx.Rollback(ctx)
+
+		cursor, err := tx.DeclareCursor(ctx, "numbers", "select n from generate_series(1, $1::int4) n", []any{10}, gaussdbgo.CursorOptions{Scroll: true})
+		require.NoError(t, err)
+		require.Equal(t, "numbers", cursor.Name())
+
+		rows, err := cursor.Fetch(ctx, 3)
+		require.NoError(t, err)
+		numbers, err := gaussdbgo.CollectRows(rows, gaussdbgo.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3}, numbers)
+
+		n, err := cursor.Move(ctx, 5)
+		require.NoError(t, err)
+		require.EqualValues(t, 5, n)
+
+		rows, err = cursor.Fetch(ctx, 5)
+		require.NoError(t, err)
+		numbers, err = gaussdbgo.CollectRows(rows, gaussdbgo.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{9, 10}, numbers)
+
+		rows, err = cursor.Fetch(ctx, -2)
+		require.NoError(t, err)
+		numbers, err = gaussdbgo.CollectRows(rows, gaussdbgo.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{10, 9}, numbers)
+
+		require.NoError(t, cursor.Close(ctx))
+
+		// A cursor with the same name may be declared for a query with other columns.
+		cursor, err = tx.DeclareCursor(ctx, "numbers", "select 'one'::text, 1::int4", nil, gaussdbgo.CursorOptions{})
+		require.NoError(t, err)
+		var s string
+		var i int32
+		rows, err = cursor.Fetch(ctx, 1)
+		require.NoError(t, err)
+		_, err = gaussdbgo.ForEachRow(rows, []any{&s, &i}, func() error { return nil })
+		require.NoError(t, err)
+		require.Equal(t, "one", s)
+		require.EqualValues(t, 1, i)
+
+		require.NoError(t, tx.Commit(ctx))
+
+		_, err = cursor.Fetch(ctx, 1)
+		require.True(t, errors.Is(err, gaussdbgo.ErrTxClosed))
+	})
+}
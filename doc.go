@@ -58,6 +58,11 @@ gaussdbgo also implements QueryRow in the same style as database/sql.
         return err
     }
 
+By default all rows of a query are sent by the server as fast as it produces them. Pass a QueryFetchSize as the first
+argument to fetch a large result in chunks as Rows.Next advances.
+
+    rows, _ := conn.Query(context.Background(), "select * from events", gaussdbgo.QueryFetchSize(1000))
+
 Use Exec to execute a query that does not return a result set.
 
     commandTag, err := conn.Exec(context.Background(), "delete from widgets where id=$1", 42)
@@ -113,6 +118,24 @@ transaction depending on the return value of the function. These can be simpler
         return err
     }
 
+Use DeclareCursor to page through a large result with a server-side cursor within a transaction.
+
+    cursor, err := tx.DeclareCursor(context.Background(), "report", "select * from orders where day = $1", []any{day}, gaussdbgo.CursorOptions{})
+    if err != nil {
+        return err
+    }
+    for {
+        rows, _ := cursor.Fetch(context.Background(), 1000)
+        orders, err := gaussdbgo.CollectRows(rows, gaussdbgo.RowToStructByName[Order])
+        if err != nil {
+            return err
+        }
+        if len(orders) == 0 {
+            break
+        }
+        // process orders
+    }
+
 Prepared Statements
 
 Prepared statements can be manually created with the Prepare method. However, this is rarely necessary because gaussdbgo
@@ -156,6 +179,58 @@ When you already have a typed array using CopyFromSlice can be more convenient.
 
 CopyFrom can be faster than an insert with as few as 5 rows.
 
+Use CopyFromCSV or CopyFromText to stream data that is already in the CSV or text format from an io.Reader without
+decoding it first.
+
+    f, err := os.Open("people.csv")
+    if err != nil {
+        return err
+    }
+    defer f.Close()
+
+    copyCount, err := conn.CopyFromCSV(
+        context.Background(),
+        gaussdbgo.Identifier{"people"},
+        []string{"first_name", "last_name", "age"},
+        f,
+        gaussdbgo.CopyFromCSVOptions{Header: true},
+    )
+
+Set CopyFromErrorHandling in the options to record bad rows in the pgxc_copy_error_log table instead of aborting the
+copy. The rejected rows can be read back with the line number, raw data and error message.
+
+    var rejected []gaussdbgo.CopyFromRejectedRow
+    copyCount, err := conn.CopyFromCSV(
+        context.Background(),
+        gaussdbgo.Identifier{"people"},
+        []string{"first_name", "last_name", "age"},
+        f,
+        gaussdbgo.CopyFromCSVOptions{
+            ErrorHandling: gaussdbgo.CopyFromErrorHandling{
+                LogErrorData: true,
+                RejectLimit:  100,
+                Rejected:     &rejected,
+            },
+        },
+    )
+
+Use CopyFromMerge to upsert rows in bulk. The rows are copied into a temporary staging table and then merged into the
+target table on the conflict columns in one transaction.
+
+    result, err := conn.CopyFromMerge(
+        context.Background(),
+        gaussdbgo.Identifier{"people"},
+        []string{"id", "first_name", "last_name", "age"},
+        gaussdbgo.CopyFromRows(rows),
+        gaussdbgo.CopyFromMergeOptions{ConflictColumns: []string{"id"}},
+    )
+
+Use CopyTo to read a table or the result of a query in bulk. Every row is decoded and passed to a CopyToDestination.
+CopyToRows collects the rows into a [][]any and CopyToCSV writes them to a csv.Writer.
+
+    var rows [][]any
+    copyCount, err := conn.CopyTo(context.Background(), gaussdbgo.Identifier{"people"}, nil, gaussdbgo.CopyToRows(&rows))
+
 Listen and Notify
 
 gaussdbgo can listen to the GaussDB notification system with the `Conn.WaitForNotification` method. It blocks until a
@@ -172,6 +247,17 @@ notification is received or the context is canceled.
     }
     // do something with notification
 
+Notices
+
+Notices such as those raised with RAISE NOTICE are passed to gaussdbconn.Config.OnNotice for the whole connection. Use
+WithNoticeHandler to receive the notices of a single Exec, Query, SendBatch or CopyFrom call.
+
+    var notices []*gaussdbconn.Notice
+    ctx = gaussdbgo.WithNoticeHandler(ctx, func(n *gaussdbconn.Notice) {
+        notices = append(notices, n)
+    })
+    _, err := conn.Exec(ctx, "call check_order($1)", orderID)
+
 
 Tracing and Logging
 
@@ -0,0 +1,92 @@
+package gaussdbgo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// functionDescription is the OID and the argument and result types of a function called with Conn.FunctionCall.
+type functionDescription struct {
+	oid       uint32
+	argOIDs   []uint32
+	resultOID uint32
+}
+
+// FunctionCall calls the function name with args using the fast-path function call protocol and scans the result into
+// result. result may be nil to discard the result. name is either a function name such as "lo_creat" that identifies a
+// single function or a function signature such as "lowrite(integer, bytea)". args are encoded for the argument types
+// of the function with the connection's type map.
+//
+// The function is looked up by name the first time it is called on a connection. The OID is cached until search_path
+// changes, so a function that is dropped and recreated must be called on a new connection.
+func (c *Conn) FunctionCall(ctx context.Context, name string, result any, args ...any) error {
+	fd, err := c.describeFunction(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != len(fd.argOIDs) {
+		return fmt.Errorf("function %s expects %d arguments, got %d", name, len(fd.argOIDs), len(args))
+	}
+
+	argValues := make([][]byte, len(args))
+	argFormats := make([]int16, len(args))
+	for i, arg := range args {
+		argFormats[i] = c.typeMap.FormatCodeForOID(fd.argOIDs[i])
+		argValues[i], err = c.typeMap.Encode(fd.argOIDs[i], argFormats[i], arg, nil)
+		if err != nil {
+			return fmt.Errorf("failed to encode argument %d of function %s: %w", i+1, name, err)
+		}
+	}
+
+	resultFormat := c.typeMap.FormatCodeForOID(fd.resultOID)
+	buf, err := c.gaussdbConn.FunctionCall(ctx, fd.oid, argValues, argFormats, resultFormat)
+	if err != nil {
+		return err
+	}
+
+	if result == nil {
+		return nil
+	}
+	return c.typeMap.Scan(fd.resultOID, resultFormat, buf, result)
+}
+
+// describeFunction returns the description of the function name from the cache or from pg_proc.
+func (c *Conn) describeFunction(ctx context.Context, name string) (*functionDescription, error) {
+	if fd, ok := c.functionDescriptions[name]; ok {
+		return fd, nil
+	}
+
+	cast := "regproc"
+	if strings.Contains(name, "(") {
+		cast = "regprocedure"
+	}
+
+	var fd functionDescription
+	var argTypes string
+	err := c.QueryRow(
+		ctx,
+		"select p.oid, p.proargtypes::text, p.prorettype from pg_catalog.pg_proc p where p.oid = $1::text::"+cast,
+		name,
+	).Scan(&fd.oid, &argTypes, &fd.resultOID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find function %s: %w", name, err)
+	}
+
+	for _, s := range strings.Fields(argTypes) {
+		oid, err := strconv.ParseUint(s, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse argument types of function %s: %w", name, err)
+		}
+		fd.argOIDs = append(fd.argOIDs, uint32(oid))
+	}
+
+	if c.functionDescriptions == nil {
+		c.functionDescriptions = make(map[string]*functionDescription)
+	}
+	c.functionDescriptions[name] = &fd
+
+	return &fd, nil
+}
@@ -2,6 +2,7 @@
 //
 // Resources:
 //   https://tools.ietf.org/html/rfc5802
+//   https://tools.ietf.org/html/rfc5929
 //   https://tools.ietf.org/html/rfc8265
 //
 // Inspiration drawn from other implementations:
@@ -13,9 +14,12 @@ package gaussdbconn
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -28,16 +32,30 @@ import (
 
 const clientNonceLen = 18
 
+const (
+	scramSHA256Name     = "SCRAM-SHA-256"
+	scramSHA256PlusName = "SCRAM-SHA-256-PLUS"
+)
+
 // Perform SCRAM authentication.
 func (g *GaussdbConn) scramAuth(serverAuthMechanisms []string) error {
-	sc, err := newScramClient(serverAuthMechanisms, g.config.Password)
+	var channelBindingData []byte
+	if tlsConn, ok := g.conn.(*tls.Conn); ok && g.config.ChannelBinding != "disable" {
+		var err error
+		channelBindingData, err = tlsServerEndPoint(tlsConn.ConnectionState())
+		if err != nil {
+			return err
+		}
+	}
+
+	sc, err := newScramClient(serverAuthMechanisms, g.password, g.config.ChannelBinding, channelBindingData)
 	if err != nil {
 		return err
 	}
 
 	// Send client-first-message in a SASLInitialResponse
 	saslInitialResponse := &gaussdbproto.SASLInitialResponse{
-		AuthMechanism: "SCRAM-SHA-256",
+		AuthMechanism: sc.authMechanism,
 		Data:          sc.clientFirstMessage(),
 	}
 	g.frontend.Send(saslInitialResponse)
@@ -71,7 +89,37 @@ func (g *GaussdbConn) scramAuth(serverAuthMechanisms []string) error {
 	if err != nil {
 		return err
 	}
-	return sc.recvServerFinalMessage(saslFinal.Data)
+	err = sc.recvServerFinalMessage(saslFinal.Data)
+	if err != nil {
+		return err
+	}
+
+	g.channelBound = sc.authMechanism == scramSHA256PlusName
+	return nil
+}
+
+// tlsServerEndPoint returns the tls-server-end-point channel binding data (RFC 5929) for a TLS connection. It is the
+// hash of the server certificate using the hash function of the certificate's signature algorithm, where MD5 and SHA-1
+// are replaced by SHA-256.
+func tlsServerEndPoint(state tls.ConnectionState) ([]byte, error) {
+	if len(state.PeerCertificates) == 0 {
+		return nil, errors.New("no server certificate for channel binding")
+	}
+	cert := state.PeerCertificates[0]
+
+	var hash crypto.Hash
+	switch cert.SignatureAlgorithm {
+	case x509.SHA384WithRSA, x509.ECDSAWithSHA384, x509.SHA384WithRSAPSS:
+		hash = crypto.SHA384
+	case x509.SHA512WithRSA, x509.ECDSAWithSHA512, x509.SHA512WithRSAPSS:
+		hash = crypto.SHA512
+	default:
+		hash = crypto.SHA256
+	}
+
+	h := hash.New()
+	h.Write(cert.Raw)
+	return h.Sum(nil), nil
 }
 
 func (g *GaussdbConn) rxSASLContinue() (*gaussdbproto.AuthenticationSASLContinue, error) {
@@ -109,6 +157,10 @@ type scramClient struct {
 	password             []byte
 	clientNonce          []byte
 
+	authMechanism      string // SCRAM-SHA-256 or SCRAM-SHA-256-PLUS
+	gs2Header          []byte // GS2 header of the client-first-message indicating channel binding use
+	channelBindingData []byte // tls-server-end-point data for SCRAM-SHA-256-PLUS
+
 	clientFirstMessageBare []byte
 
 	serverFirstMessage   []byte
@@ -120,21 +172,43 @@ type scramClient struct {
 	authMessage    []byte
 }
 
-func newScramClient(serverAuthMechanisms []string, password string) (*scramClient, error) {
+// newScramClient creates a SCRAM client. channelBinding is the channel_binding connection setting and
+// channelBindingData is the tls-server-end-point data of the connection or nil when the connection does not use TLS.
+func newScramClient(serverAuthMechanisms []string, password string, channelBinding string, channelBindingData []byte) (*scramClient, error) {
 	sc := &scramClient{
 		serverAuthMechanisms: serverAuthMechanisms,
 	}
 
-	// Ensure server supports SCRAM-SHA-256
 	hasScramSHA256 := false
+	hasScramSHA256Plus := false
 	for _, mech := range sc.serverAuthMechanisms {
-		if mech == "SCRAM-SHA-256" {
+		switch mech {
+		case scramSHA256Name:
 			hasScramSHA256 = true
-			break
+		case scramSHA256PlusName:
+			hasScramSHA256Plus = true
 		}
 	}
-	if !hasScramSHA256 {
+
+	switch {
+	case channelBindingData != nil && hasScramSHA256Plus:
+		sc.authMechanism = scramSHA256PlusName
+		sc.gs2Header = []byte("p=tls-server-end-point,,")
+		sc.channelBindingData = channelBindingData
+	case channelBinding == "require":
+		if channelBindingData == nil {
+			return nil, errors.New("channel binding required but connection does not use TLS")
+		}
+		return nil, errors.New("channel binding required but server does not support SCRAM-SHA-256-PLUS")
+	case !hasScramSHA256:
 		return nil, errors.New("server does not support SCRAM-SHA-256")
+	case channelBindingData != nil:
+		// The client supports channel binding but the server did not offer it. "y" lets the server detect a downgrade.
+		sc.authMechanism = scramSHA256Name
+		sc.gs2Header = []byte("y,,")
+	default:
+		sc.authMechanism = scramSHA256Name
+		sc.gs2Header = []byte("n,,")
 	}
 
 	// precis.OpaqueString is equivalent to SASLprep for password.
@@ -158,7 +232,7 @@ func newScramClient(serverAuthMechanisms []string, password string) (*scramClien
 
 func (sc *scramClient) clientFirstMessage() []byte {
 	sc.clientFirstMessageBare = []byte(fmt.Sprintf("n=,r=%s", sc.clientNonce))
-	return []byte(fmt.Sprintf("n,,%s", sc.clientFirstMessageBare))
+	return append(append([]byte{}, sc.gs2Header...), sc.clientFirstMessageBare...)
 }
 
 func (sc *scramClient) recvServerFirstMessage(serverFirstMessage []byte) error {
@@ -217,7 +291,8 @@ func (sc *scramClient) recvServerFirstMessage(serverFirstMessage []byte) error {
 }
 
 func (sc *scramClient) clientFinalMessage() string {
-	clientFinalMessageWithoutProof := []byte(fmt.Sprintf("c=biws,r=%s", sc.clientAndServerNonce))
+	channelBinding := base64.StdEncoding.EncodeToString(append(append([]byte{}, sc.gs2Header...), sc.channelBindingData...))
+	clientFinalMessageWithoutProof := []byte(fmt.Sprintf("c=%s,r=%s", channelBinding, sc.clientAndServerNonce))
 
 	sc.saltedPassword = pbkdf2.Key([]byte(sc.password), sc.salt, sc.iterations, 32, sha256.New)
 	sc.authMessage = bytes.Join([][]byte{sc.clientFirstMessageBare, sc.serverFirstMessage, clientFinalMessageWithoutProof}, []byte(","))
@@ -25,7 +25,7 @@ func (g *GaussdbConn) authSha256(r *readBuf) (*writeBuf, error) {
 
 	passwordStoredMethod := r.int32()
 	digest := ""
-	if len(g.config.Password) == 0 {
+	if len(g.password) == 0 {
 		return nil, fmt.Errorf("The server requested password-based authentication, but no password was provided.")
 	}
 
@@ -33,7 +33,7 @@ func (g *GaussdbConn) authSha256(r *readBuf) (*writeBuf, error) {
 		random64code := string(r.next(64))
 		token := string(r.next(8))
 		serverIteration := r.int32()
-		result := RFC5802Algorithm(g.config.Password, random64code, token, "", serverIteration, "sha256")
+		result := RFC5802Algorithm(g.password, random64code, token, "", serverIteration, "sha256")
 		if len(result) == 0 {
 			return nil, fmt.Errorf("Invalid username/password,login denied.")
 		}
@@ -48,7 +48,7 @@ func (g *GaussdbConn) authSha256(r *readBuf) (*writeBuf, error) {
 		return w, nil
 	} else if passwordStoredMethod == Md5Password {
 		s := string(r.next(4))
-		digest = "md5" + md5s(md5s(g.config.Password+g.config.User)+s)
+		digest = "md5" + md5s(md5s(g.password+g.config.User)+s)
 
 		w := g.writeBuf('p')
 		w.int16(4 + len(digest) + 1)
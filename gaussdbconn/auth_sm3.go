@@ -0,0 +1,30 @@
+package gaussdbconn
+
+import (
+	"errors"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+)
+
+// Perform SM3 authentication. The proof is computed with RFC5802Algorithm using SM3 as the stored key hash. If the
+// server included its signature in the request it is verified before the proof is sent.
+func (g *GaussdbConn) sm3Auth(msg *gaussdbproto.AuthenticationSM3) error {
+	if len(g.password) == 0 {
+		return errors.New("The server requested password-based authentication, but no password was provided.")
+	}
+
+	if msg.PasswordStoredMethod != PlainPassword && msg.PasswordStoredMethod != Sm3Password {
+		return errors.New("The password-stored method is not supported, must be plain or sm3.")
+	}
+
+	if len(msg.Random64Code) == 0 || len(msg.Token) == 0 {
+		return errors.New("The server sent an incomplete SM3 authentication request.")
+	}
+
+	result := RFC5802Algorithm(g.password, string(msg.Random64Code), string(msg.Token), string(msg.ServerSignature), int(msg.Iteration), "sm3")
+	if len(result) == 0 {
+		return errors.New("Invalid server signature, the server may not know the password.")
+	}
+
+	return g.txPasswordMessage(string(result))
+}
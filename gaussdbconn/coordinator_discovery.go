@@ -0,0 +1,121 @@
+package gaussdbconn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// coordinatorListSQL returns the active coordinators (CN) of a distributed GaussDB cluster.
+const coordinatorListSQL = "select node_host, node_port from pgxc_node where node_type = 'C' and nodeis_active order by node_name"
+
+// coordinatorDiscovery holds the coordinator list discovered from a distributed GaussDB cluster.
+type coordinatorDiscovery struct {
+	// buildFallbacks builds the FallbackConfigs for a discovered host with the same TLS settings as the statically
+	// configured hosts.
+	buildFallbacks func(host string, port uint16) ([]*FallbackConfig, error)
+
+	mux         sync.Mutex
+	fallbacks   []*FallbackConfig
+	lastRefresh time.Time
+	refreshing  bool
+}
+
+// fallbackConfigs returns the hosts to attempt given the statically configured seed hosts. Discovered coordinators
+// come first, followed by the seed hosts that were not discovered.
+func (d *coordinatorDiscovery) fallbackConfigs(seed []*FallbackConfig) []*FallbackConfig {
+	d.mux.Lock()
+	discovered := d.fallbacks
+	d.mux.Unlock()
+
+	if len(discovered) == 0 {
+		return seed
+	}
+
+	known := make(map[string]struct{}, len(discovered))
+	fallbacks := make([]*FallbackConfig, 0, len(discovered)+len(seed))
+	for _, fb := range discovered {
+		known[net.JoinHostPort(fb.Host, strconv.Itoa(int(fb.Port)))] = struct{}{}
+		fallbacks = append(fallbacks, fb)
+	}
+	for _, fb := range seed {
+		if _, ok := known[net.JoinHostPort(fb.Host, strconv.Itoa(int(fb.Port)))]; !ok {
+			fallbacks = append(fallbacks, fb)
+		}
+	}
+
+	return fallbacks
+}
+
+// maybeRefresh starts a background refresh of the coordinator list if config.RefreshCNListInterval has elapsed since
+// the last refresh and no refresh is in progress.
+func (d *coordinatorDiscovery) maybeRefresh(config *Config) {
+	d.mux.Lock()
+	if d.refreshing || time.Since(d.lastRefresh) < config.RefreshCNListInterval {
+		d.mux.Unlock()
+		return
+	}
+	d.refreshing = true
+	d.mux.Unlock()
+
+	go d.refresh(config.Copy())
+}
+
+// refresh reads the coordinator list over a dedicated connection. Errors are ignored and the previous list is kept.
+// An empty coordinator list (e.g. a centralized deployment) resets to the seed hosts.
+func (d *coordinatorDiscovery) refresh(config *Config) {
+	var fallbacks []*FallbackConfig
+	var err error
+	defer func() {
+		d.mux.Lock()
+		if err == nil {
+			d.fallbacks = fallbacks
+		}
+		d.lastRefresh = time.Now()
+		d.refreshing = false
+		d.mux.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.RefreshCNListInterval)
+	defer cancel()
+
+	fallbacks, err = d.queryCoordinators(ctx, config)
+}
+
+func (d *coordinatorDiscovery) queryCoordinators(ctx context.Context, config *Config) ([]*FallbackConfig, error) {
+	// The refresh connection only needs to reach any node of the cluster.
+	config.ValidateConnect = nil
+	config.AfterConnect = nil
+
+	gaussdbConn, err := ConnectConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	defer gaussdbConn.Close(ctx)
+
+	results, err := gaussdbConn.Exec(ctx, coordinatorListSQL).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var fallbacks []*FallbackConfig
+	for _, row := range results[0].Rows {
+		if len(row) != 2 || row[0] == nil || row[1] == nil {
+			return nil, fmt.Errorf("unexpected coordinator row: %q", row)
+		}
+		port, err := parsePort(string(row[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid coordinator port %q: %w", row[1], err)
+		}
+		hostFallbacks, err := d.buildFallbacks(string(row[0]), port)
+		if err != nil {
+			return nil, err
+		}
+		fallbacks = append(fallbacks, hostFallbacks...)
+	}
+
+	return fallbacks, nil
+}
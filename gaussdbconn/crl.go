@@ -0,0 +1,131 @@
+package gaussdbconn
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// revocationLists are the certificate revocation lists read from sslcrl and sslcrldir.
+type revocationLists []*x509.RevocationList
+
+// loadRevocationLists reads the CRL file sslcrl and all files in the directory sslcrldir. Either may be empty. Files may
+// contain one or more PEM encoded CRLs or a single DER encoded CRL.
+func loadRevocationLists(sslcrl, sslcrldir string) (revocationLists, error) {
+	var paths []string
+	if sslcrl != "" {
+		paths = append(paths, sslcrl)
+	}
+	if sslcrldir != "" {
+		entries, err := os.ReadDir(sslcrldir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read sslcrldir: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(sslcrldir, entry.Name()))
+			}
+		}
+	}
+
+	if sslcrl == "" && sslcrldir == "" {
+		return nil, nil
+	}
+
+	// Non-nil even if sslcrldir is empty so that the certificates are still required to be covered by a CRL.
+	rls := revocationLists{}
+	for _, path := range paths {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CRL file: %w", err)
+		}
+		fileRLs, err := parseRevocationLists(buf)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse CRL file %s: %w", path, err)
+		}
+		rls = append(rls, fileRLs...)
+	}
+
+	return rls, nil
+}
+
+func parseRevocationLists(buf []byte) ([]*x509.RevocationList, error) {
+	if !bytes.Contains(buf, []byte("-----BEGIN")) {
+		rl, err := x509.ParseRevocationList(buf)
+		if err != nil {
+			return nil, err
+		}
+		return []*x509.RevocationList{rl}, nil
+	}
+
+	var rls []*x509.RevocationList
+	for {
+		var block *pem.Block
+		block, buf = pem.Decode(buf)
+		if block == nil {
+			break
+		}
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		rl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rls = append(rls, rl)
+	}
+	if len(rls) == 0 {
+		return nil, errors.New("no CRL found")
+	}
+
+	return rls, nil
+}
+
+// check returns an error unless at least one of the verified chains contains no revoked certificate. Every certificate
+// but the root of a chain is checked against the CRLs signed by its issuer. As with libpq, a certificate whose issuer
+// has no CRL and a CRL that is past its next update time are errors.
+func (rls revocationLists) check(verifiedChains [][]*x509.Certificate) error {
+	if len(verifiedChains) == 0 {
+		return errors.New("no verified certificate chain to check for revocation")
+	}
+
+	var err error
+	for _, chain := range verifiedChains {
+		err = rls.checkChain(chain)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (rls revocationLists) checkChain(chain []*x509.Certificate) error {
+	now := time.Now()
+	for i := 0; i < len(chain)-1; i++ {
+		cert, issuer := chain[i], chain[i+1]
+		covered := false
+		for _, rl := range rls {
+			if !bytes.Equal(rl.RawIssuer, cert.RawIssuer) || rl.CheckSignatureFrom(issuer) != nil {
+				continue
+			}
+			covered = true
+			if !rl.NextUpdate.IsZero() && now.After(rl.NextUpdate) {
+				return fmt.Errorf("CRL for issuer %q has expired", issuer.Subject)
+			}
+			for _, entry := range rl.RevokedCertificateEntries {
+				if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+					return fmt.Errorf("certificate %q has been revoked", cert.Subject)
+				}
+			}
+		}
+		if !covered {
+			return fmt.Errorf("no CRL for issuer %q of certificate %q", issuer.Subject, cert.Subject)
+		}
+	}
+	return nil
+}
@@ -45,6 +45,8 @@ func defaultSettings() map[string]string {
 	}
 
 	settings["target_session_attrs"] = "any"
+	settings["channel_binding"] = "prefer"
+	settings["gssencmode"] = "disable"
 
 	return settings
 }
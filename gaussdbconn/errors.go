@@ -225,9 +225,35 @@ func redactURL(u *url.URL) string {
 	if _, pwSet := u.User.Password(); pwSet {
 		u.User = url.UserPassword(u.User.Username(), "xxxxx")
 	}
+	u.RawQuery = redactProxyQuery(u.RawQuery)
 	return u.String()
 }
 
+// redactProxyQuery redacts the password of the proxy URL in the query string of a URL connection string.
+func redactProxyQuery(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		if key != "proxy" {
+			continue
+		}
+		value, err := url.QueryUnescape(value)
+		if err != nil {
+			continue
+		}
+		proxyURL, err := url.Parse(value)
+		if err != nil {
+			params[i] = "proxy=xxxxx"
+			continue
+		}
+		if _, pwSet := proxyURL.User.Password(); pwSet {
+			proxyURL.User = url.UserPassword(proxyURL.User.Username(), "xxxxx")
+			params[i] = "proxy=" + url.QueryEscape(proxyURL.String())
+		}
+	}
+	return strings.Join(params, "&")
+}
+
 type NotPreferredError struct {
 	err         error
 	safeToRetry bool
@@ -2,6 +2,8 @@
 
 package gaussdbconn
 
+import "net"
+
 func NewParseConfigError(conn, msg string, err error) error {
 	return &ParseConfigError{
 		ConnString: conn,
@@ -9,3 +11,7 @@ func NewParseConfigError(conn, msg string, err error) error {
 		err:        err,
 	}
 }
+
+func NewGSSEncConn(conn net.Conn, enc GSSEncrypter) net.Conn {
+	return &gssEncConn{Conn: conn, enc: enc}
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"math/rand"
 	"net"
 	"strconv"
 	"strings"
@@ -71,6 +72,11 @@ type NoticeHandler func(*GaussdbConn, *Notice)
 // notice event.
 type NotificationHandler func(*GaussdbConn, *Notification)
 
+// ParameterStatusHandler is a function that can handle a run-time parameter value reported by the GaussDB server.
+// Parameter statuses can be received at any time, usually after a SET statement. The *GaussdbConn is provided so the
+// handler is aware of the origin of the parameter status, but it must not invoke any query method.
+type ParameterStatusHandler func(gaussdbConn *GaussdbConn, name, value string)
+
 // GaussdbConn is a low-level GaussDB connection handle. It is not safe for concurrent usage.
 type GaussdbConn struct {
 	scratch []byte
@@ -87,7 +93,9 @@ type GaussdbConn struct {
 
 	customData map[string]any
 
-	config *Config
+	config       *Config
+	password     string // password resolved for this connection by Config.GetPassword or Config.Password
+	channelBound bool   // authenticated with SCRAM-SHA-256-PLUS channel binding
 
 	status byte // One of connStatus* constants
 
@@ -136,7 +144,8 @@ func ConnectWithOptions(ctx context.Context, connString string, parseConfigOptio
 // [ParseConfig]. ctx can be used to cancel a connect attempt.
 //
 // If config.Fallbacks are present they will sequentially be tried in case of error establishing network connection. An
-// authentication error will terminate the chain of attempts and be returned as the error.
+// authentication error will terminate the chain of attempts and be returned as the error. If
+// config.ParallelConnectDelay is set the attempts are made in parallel instead.
 func ConnectConfig(ctx context.Context, config *Config) (*GaussdbConn, error) {
 	// Default values are set in ParseConfig. Enforce initial creation by ParseConfig rather than setting defaults from
 	// zero values.
@@ -155,7 +164,12 @@ func ConnectConfig(ctx context.Context, config *Config) (*GaussdbConn, error) {
 		return nil, &ConnectError{Config: config, err: fmt.Errorf("hostname resolving error: %w", errors.Join(allErrors...))}
 	}
 
-	gaussdbConn, errs := connectPreferred(ctx, config, connectConfigs)
+	var gaussdbConn *GaussdbConn
+	if config.ParallelConnectDelay > 0 {
+		gaussdbConn, errs = connectParallel(ctx, config, connectConfigs)
+	} else {
+		gaussdbConn, errs = connectPreferred(ctx, config, connectConfigs)
+	}
 	if len(errs) > 0 {
 		allErrors = append(allErrors, errs...)
 		return nil, &ConnectError{Config: config, err: errors.Join(allErrors...)}
@@ -169,6 +183,10 @@ func ConnectConfig(ctx context.Context, config *Config) (*GaussdbConn, error) {
 		}
 	}
 
+	if config.RefreshCNListInterval > 0 && config.coordinatorDiscovery != nil {
+		config.coordinatorDiscovery.maybeRefresh(config)
+	}
+
 	return gaussdbConn, nil
 }
 
@@ -179,18 +197,29 @@ func buildConnectOneConfigs(ctx context.Context, config *Config) ([]*connectOneC
 	// Simplify usage by treating primary config and fallbacks the same.
 	fallbackConfigs := []*FallbackConfig{
 		{
-			Host:      config.Host,
-			Port:      config.Port,
-			TLSConfig: config.TLSConfig,
+			Host:       config.Host,
+			Port:       config.Port,
+			TLSConfig:  config.TLSConfig,
+			TLCPConfig: config.TLCPConfig,
+
+			GSSEncryption: config.GSSEncryption,
 		},
 	}
 	fallbackConfigs = append(fallbackConfigs, config.Fallbacks...)
+	if config.coordinatorDiscovery != nil {
+		fallbackConfigs = config.coordinatorDiscovery.fallbackConfigs(fallbackConfigs)
+	}
+	fallbackConfigs = config.orderFallbackConfigs(fallbackConfigs)
 
 	var configs []*connectOneConfig
 
 	var allErrors []error
 
 	for _, fb := range fallbackConfigs {
+		if fb.GSSEncryption && (fb.TLSConfig != nil || fb.TLCPConfig != nil) {
+			fb = &FallbackConfig{Host: fb.Host, Port: fb.Port, GSSEncryption: true}
+		}
+
 		// skip resolve for unix sockets
 		if isAbsolutePath(fb.Host) {
 			network, address := NetworkAddress(fb.Host, fb.Port)
@@ -198,7 +227,10 @@ func buildConnectOneConfigs(ctx context.Context, config *Config) ([]*connectOneC
 				network:          network,
 				address:          address,
 				originalHostname: fb.Host,
+				port:             fb.Port,
 				tlsConfig:        fb.TLSConfig,
+				tlcpConfig:       fb.TLCPConfig,
+				gssEncryption:    fb.GSSEncryption,
 			})
 
 			continue
@@ -210,6 +242,10 @@ func buildConnectOneConfigs(ctx context.Context, config *Config) ([]*connectOneC
 			continue
 		}
 
+		if config.LoadBalanceHosts == LoadBalanceHostsRandom {
+			rand.Shuffle(len(ips), func(i, j int) { ips[i], ips[j] = ips[j], ips[i] })
+		}
+
 		for _, ip := range ips {
 			splitIP, splitPort, err := net.SplitHostPort(ip)
 			if err == nil {
@@ -222,7 +258,10 @@ func buildConnectOneConfigs(ctx context.Context, config *Config) ([]*connectOneC
 					network:          network,
 					address:          address,
 					originalHostname: fb.Host,
+					port:             uint16(port),
 					tlsConfig:        fb.TLSConfig,
+					tlcpConfig:       fb.TLCPConfig,
+					gssEncryption:    fb.GSSEncryption,
 				})
 			} else {
 				network, address := NetworkAddress(ip, fb.Port)
@@ -230,7 +269,10 @@ func buildConnectOneConfigs(ctx context.Context, config *Config) ([]*connectOneC
 					network:          network,
 					address:          address,
 					originalHostname: fb.Host,
+					port:             fb.Port,
 					tlsConfig:        fb.TLSConfig,
+					tlcpConfig:       fb.TLCPConfig,
+					gssEncryption:    fb.GSSEncryption,
 				})
 			}
 		}
@@ -267,18 +309,8 @@ func connectPreferred(ctx context.Context, config *Config, connectOneConfigs []*
 
 		allErrors = append(allErrors, err)
 
-		var gaussdbError *GaussdbError
-		if errors.As(err, &gaussdbError) {
-			const ERRCODE_INVALID_PASSWORD = "28P01"                    // wrong password
-			const ERRCODE_INVALID_AUTHORIZATION_SPECIFICATION = "28000" // wrong password or bad pg_hba.conf settings
-			const ERRCODE_INVALID_CATALOG_NAME = "3D000"                // db does not exist
-			const ERRCODE_INSUFFICIENT_PRIVILEGE = "42501"              // missing connect privilege
-			if gaussdbError.Code == ERRCODE_INVALID_PASSWORD ||
-				gaussdbError.Code == ERRCODE_INVALID_AUTHORIZATION_SPECIFICATION && c.tlsConfig != nil ||
-				gaussdbError.Code == ERRCODE_INVALID_CATALOG_NAME ||
-				gaussdbError.Code == ERRCODE_INSUFFICIENT_PRIVILEGE {
-				return nil, allErrors
-			}
+		if isFatalConnectError(err, c) {
+			return nil, allErrors
 		}
 
 		var npErr *NotPreferredError
@@ -298,6 +330,177 @@ func connectPreferred(ctx context.Context, config *Config, connectOneConfigs []*
 	return nil, allErrors
 }
 
+// isFatalConnectError returns true if err means that attempting to connect to other hosts is pointless. e.g. A wrong
+// password or a database that does not exist.
+func isFatalConnectError(err error, c *connectOneConfig) bool {
+	var gaussdbError *GaussdbError
+	if errors.As(err, &gaussdbError) {
+		const ERRCODE_INVALID_PASSWORD = "28P01"                    // wrong password
+		const ERRCODE_INVALID_AUTHORIZATION_SPECIFICATION = "28000" // wrong password or bad pg_hba.conf settings
+		const ERRCODE_INVALID_CATALOG_NAME = "3D000"                // db does not exist
+		const ERRCODE_INSUFFICIENT_PRIVILEGE = "42501"              // missing connect privilege
+		if gaussdbError.Code == ERRCODE_INVALID_PASSWORD ||
+			gaussdbError.Code == ERRCODE_INVALID_AUTHORIZATION_SPECIFICATION && c.tlsConfig != nil ||
+			gaussdbError.Code == ERRCODE_INVALID_CATALOG_NAME ||
+			gaussdbError.Code == ERRCODE_INSUFFICIENT_PRIVILEGE {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parallelConnectResult is the outcome of connecting to all connectOneConfigs of a single address.
+type parallelConnectResult struct {
+	index        int
+	gaussdbConn  *GaussdbConn
+	errs         []error
+	fatal        bool
+	notPreferred *connectOneConfig
+}
+
+// connectParallel attempts to connect to the preferred host from connectOneConfigs like connectPreferred, but does not
+// wait for an attempt to fail before starting the next one. Attempts are started in order, config.ParallelConnectDelay
+// apart or as soon as the previous attempt fails. The connectOneConfigs of a single address (e.g. with and without TLS)
+// are attempted one after another in the same attempt. The first connection that passes ValidateConnect is returned
+// and all other attempts are canceled and closed. A fatal error such as a wrong password stops all attempts.
+func connectParallel(ctx context.Context, config *Config, connectOneConfigs []*connectOneConfig) (*GaussdbConn, []error) {
+	octx := ctx
+
+	var groups [][]*connectOneConfig
+	for i, c := range connectOneConfigs {
+		if i > 0 && c.address == connectOneConfigs[i-1].address {
+			groups[len(groups)-1] = append(groups[len(groups)-1], c)
+		} else {
+			groups = append(groups, []*connectOneConfig{c})
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	results := make(chan parallelConnectResult, len(groups))
+
+	startAttempt := func(i int) {
+		go func() {
+			result := parallelConnectResult{index: i}
+			defer func() { results <- result }()
+
+			attemptCtx := ctx
+			if config.ConnectTimeout != 0 {
+				var attemptCancel context.CancelFunc
+				attemptCtx, attemptCancel = context.WithTimeout(ctx, config.ConnectTimeout)
+				defer attemptCancel()
+			}
+
+			for _, c := range groups[i] {
+				gaussdbConn, err := connectOne(attemptCtx, config, c, false)
+				if gaussdbConn != nil {
+					result.gaussdbConn = gaussdbConn
+					return
+				}
+
+				result.errs = append(result.errs, err)
+
+				if isFatalConnectError(err, c) {
+					result.fatal = true
+					return
+				}
+
+				var npErr *NotPreferredError
+				if errors.As(err, &npErr) {
+					result.notPreferred = c
+				}
+			}
+		}()
+	}
+
+	var allErrors []error
+	var fallbackConnectOneConfig *connectOneConfig
+	fallbackIndex := -1
+	started, finished := 1, 0
+
+	startAttempt(0)
+	timer := time.NewTimer(config.ParallelConnectDelay)
+	defer timer.Stop()
+
+	for finished < started {
+		var timerC <-chan time.Time
+		if started < len(groups) {
+			timerC = timer.C
+		}
+
+		select {
+		case <-timerC:
+			startAttempt(started)
+			started++
+			timer.Reset(config.ParallelConnectDelay)
+		case result := <-results:
+			finished++
+
+			if result.gaussdbConn != nil {
+				cancel()
+				go closeParallelConnectLosers(results, started-finished)
+				return result.gaussdbConn, nil
+			}
+
+			allErrors = append(allErrors, result.errs...)
+
+			if result.fatal {
+				cancel()
+				go closeParallelConnectLosers(results, started-finished)
+				return nil, allErrors
+			}
+
+			if result.notPreferred != nil && result.index > fallbackIndex {
+				fallbackIndex = result.index
+				fallbackConnectOneConfig = result.notPreferred
+			}
+
+			// Do not wait for the delay when the previous attempt already failed.
+			if started < len(groups) {
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+				startAttempt(started)
+				started++
+				timer.Reset(config.ParallelConnectDelay)
+			}
+		}
+	}
+	cancel()
+
+	if fallbackConnectOneConfig != nil {
+		ctx := octx
+		if config.ConnectTimeout != 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(octx, config.ConnectTimeout)
+			defer cancel()
+		}
+		gaussdbConn, err := connectOne(ctx, config, fallbackConnectOneConfig, true)
+		if err == nil {
+			return gaussdbConn, nil
+		}
+		allErrors = append(allErrors, err)
+	}
+
+	return nil, allErrors
+}
+
+// closeParallelConnectLosers waits for the remaining n attempts of connectParallel and closes any connection they
+// established.
+func closeParallelConnectLosers(results chan parallelConnectResult, n int) {
+	for i := 0; i < n; i++ {
+		result := <-results
+		if result.gaussdbConn != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+			result.gaussdbConn.Close(ctx)
+			cancel()
+		}
+	}
+}
+
 // connectOne makes one connection attempt to a single host.
 func connectOne(ctx context.Context, config *Config, connectConfig *connectOneConfig,
 	ignoreNotPreferredErr bool,
@@ -315,17 +518,41 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 		return e
 	}
 
+	gaussdbConn.password = config.Password
+	if config.GetPassword != nil {
+		password, err := config.GetPassword(ctx, connectConfig.originalHostname, connectConfig.port, config.User)
+		if err != nil {
+			return nil, newPerDialConnectError("get password", err)
+		}
+		if password != "" {
+			gaussdbConn.password = password
+		}
+	}
+
 	gaussdbConn.conn, err = config.DialFunc(ctx, connectConfig.network, connectConfig.address)
 	if err != nil {
 		return nil, newPerDialConnectError("dial error", err)
 	}
 
-	if config.minReadBufferSize == 0 {
-		config.minReadBufferSize = 8192
+	// Do not default config.minReadBufferSize in place. connectOne may be called concurrently with the same config.
+	minReadBufferSize := config.minReadBufferSize
+	if minReadBufferSize == 0 {
+		minReadBufferSize = 8192
 	}
-	gaussdbConn.scratch = make([]byte, config.minReadBufferSize)
+	gaussdbConn.scratch = make([]byte, minReadBufferSize)
+
+	if connectConfig.gssEncryption {
+		gaussdbConn.contextWatcher = ctxwatch.NewContextWatcher(&DeadlineContextWatcherHandler{Conn: gaussdbConn.conn})
+		gaussdbConn.contextWatcher.Watch(ctx)
+		gssConn, err := startGSSEnc(gaussdbConn.conn, config, connectConfig.originalHostname)
+		gaussdbConn.contextWatcher.Unwatch() // Always unwatch `netConn` after GSSAPI encryption is established.
+		if err != nil {
+			gaussdbConn.conn.Close()
+			return nil, newPerDialConnectError("gssenc error", err)
+		}
 
-	if connectConfig.tlsConfig != nil {
+		gaussdbConn.conn = gssConn
+	} else if connectConfig.tlsConfig != nil {
 		gaussdbConn.contextWatcher = ctxwatch.NewContextWatcher(&DeadlineContextWatcherHandler{Conn: gaussdbConn.conn})
 		gaussdbConn.contextWatcher.Watch(ctx)
 		tlsConn, err := startTLS(gaussdbConn.conn, connectConfig.tlsConfig)
@@ -336,6 +563,17 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 		}
 
 		gaussdbConn.conn = tlsConn
+	} else if connectConfig.tlcpConfig != nil {
+		gaussdbConn.contextWatcher = ctxwatch.NewContextWatcher(&DeadlineContextWatcherHandler{Conn: gaussdbConn.conn})
+		gaussdbConn.contextWatcher.Watch(ctx)
+		tlcpConn, err := startTLCP(gaussdbConn.conn, connectConfig.tlcpConfig)
+		gaussdbConn.contextWatcher.Unwatch() // Always unwatch `netConn` after TLCP.
+		if err != nil {
+			gaussdbConn.conn.Close()
+			return nil, newPerDialConnectError("tlcp error", err)
+		}
+
+		gaussdbConn.conn = tlcpConn
 	}
 
 	gaussdbConn.contextWatcher = ctxwatch.NewContextWatcher(config.BuildContextWatcherHandler(gaussdbConn))
@@ -392,14 +630,26 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 			gaussdbConn.secretKey = msg.SecretKey
 
 		case *gaussdbproto.AuthenticationOk:
+			if err := gaussdbConn.checkChannelBinding(); err != nil {
+				gaussdbConn.conn.Close()
+				return nil, newPerDialConnectError("channel binding", err)
+			}
 		case *gaussdbproto.AuthenticationCleartextPassword:
-			err = gaussdbConn.txPasswordMessage(gaussdbConn.config.Password)
+			if err := gaussdbConn.checkChannelBinding(); err != nil {
+				gaussdbConn.conn.Close()
+				return nil, newPerDialConnectError("channel binding", err)
+			}
+			err = gaussdbConn.txPasswordMessage(gaussdbConn.password)
 			if err != nil {
 				gaussdbConn.conn.Close()
 				return nil, newPerDialConnectError("failed to write password message", err)
 			}
 		case *gaussdbproto.AuthenticationMD5Password:
-			digestedPassword := "md5" + hexMD5(hexMD5(gaussdbConn.config.Password+gaussdbConn.config.User)+string(msg.Salt[:]))
+			if err := gaussdbConn.checkChannelBinding(); err != nil {
+				gaussdbConn.conn.Close()
+				return nil, newPerDialConnectError("channel binding", err)
+			}
+			digestedPassword := "md5" + hexMD5(hexMD5(gaussdbConn.password+gaussdbConn.config.User)+string(msg.Salt[:]))
 			err = gaussdbConn.txPasswordMessage(digestedPassword)
 			if err != nil {
 				gaussdbConn.conn.Close()
@@ -412,11 +662,25 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 				return nil, newPerDialConnectError("failed SASL auth", err)
 			}
 		case *gaussdbproto.AuthenticationSHA256:
+			if err := gaussdbConn.checkChannelBinding(); err != nil {
+				gaussdbConn.conn.Close()
+				return nil, newPerDialConnectError("channel binding", err)
+			}
 			err = gaussdbConn.scramSha256Auth(msg.GetR())
 			if err != nil {
 				gaussdbConn.conn.Close()
 				return nil, newPerDialConnectError("failed SASL SHA256 auth", err)
 			}
+		case *gaussdbproto.AuthenticationSM3:
+			if err := gaussdbConn.checkChannelBinding(); err != nil {
+				gaussdbConn.conn.Close()
+				return nil, newPerDialConnectError("channel binding", err)
+			}
+			err = gaussdbConn.sm3Auth(msg)
+			if err != nil {
+				gaussdbConn.conn.Close()
+				return nil, newPerDialConnectError("failed SM3 auth", err)
+			}
 		case *gaussdbproto.AuthenticationGSS:
 			err = gaussdbConn.gssAuth()
 			if err != nil {
@@ -453,22 +717,41 @@ func connectOne(ctx context.Context, config *Config, connectConfig *connectOneCo
 	}
 }
 
+// checkChannelBinding returns an error if channel_binding=require and authentication is completing or about to proceed
+// without SCRAM-SHA-256-PLUS channel binding. It is checked before a password is sent so it is never disclosed to a
+// server that could not be authenticated.
+func (gaussdbConn *GaussdbConn) checkChannelBinding() error {
+	if gaussdbConn.config.ChannelBinding == "require" && !gaussdbConn.channelBound {
+		return errors.New("channel binding required but server did not authenticate with SCRAM-SHA-256-PLUS")
+	}
+	return nil
+}
+
 func startTLS(conn net.Conn, tlsConfig *tls.Config) (net.Conn, error) {
+	if err := requestSSL(conn); err != nil {
+		return nil, err
+	}
+
+	return tls.Client(conn, tlsConfig), nil
+}
+
+// requestSSL sends an SSLRequest and returns an error unless the server agrees to continue with a TLS handshake.
+func requestSSL(conn net.Conn) error {
 	err := binary.Write(conn, binary.BigEndian, []int32{8, 80877103})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	response := make([]byte, 1)
 	if _, err = io.ReadFull(conn, response); err != nil {
-		return nil, err
+		return err
 	}
 
 	if response[0] != 'S' {
-		return nil, errors.New("server refused TLS connection")
+		return errors.New("server refused TLS connection")
 	}
 
-	return tls.Client(conn, tlsConfig), nil
+	return nil
 }
 
 func (gaussdbConn *GaussdbConn) txPasswordMessage(password string) (err error) {
@@ -583,6 +866,9 @@ func (gaussdbConn *GaussdbConn) receiveMessage() (gaussdbproto.BackendMessage, e
 		gaussdbConn.txStatus = msg.TxStatus
 	case *gaussdbproto.ParameterStatus:
 		gaussdbConn.parameterStatuses[msg.Name] = msg.Value
+		if gaussdbConn.config.OnParameterStatus != nil {
+			gaussdbConn.config.OnParameterStatus(gaussdbConn, msg.Name, msg.Value)
+		}
 	case *gaussdbproto.ErrorResponse:
 		err := ErrorResponseToGuassdbError(msg)
 		if gaussdbConn.config.OnGaussdbError != nil && !gaussdbConn.config.OnGaussdbError(gaussdbConn, err) {
@@ -747,6 +1033,15 @@ func (gaussdbConn *GaussdbConn) ParameterStatus(key string) string {
 	return gaussdbConn.parameterStatuses[key]
 }
 
+// ParameterStatuses returns a copy of all parameters reported by the server.
+func (gaussdbConn *GaussdbConn) ParameterStatuses() map[string]string {
+	parameterStatuses := make(map[string]string, len(gaussdbConn.parameterStatuses))
+	for k, v := range gaussdbConn.parameterStatuses {
+		parameterStatuses[k] = v
+	}
+	return parameterStatuses
+}
+
 // CommandTag is the status text returned by GaussDB for a query.
 type CommandTag struct {
 	s string
@@ -950,6 +1245,77 @@ func (gaussdbConn *GaussdbConn) Deallocate(ctx context.Context, name string) err
 	}
 }
 
+// FunctionCall calls the function identified by oid with the fast-path function call protocol. args are the encoded
+// arguments. A nil arg is NULL. argFormats are the format codes of args with the same semantics as paramFormats of
+// ExecParams. The result is returned in resultFormat. A NULL result is returned as nil.
+//
+// FunctionCall does not send a query to the server. It uses the GaussDB FunctionCall protocol message directly like
+// the libpq PQfn function.
+func (gaussdbConn *GaussdbConn) FunctionCall(ctx context.Context, oid uint32, args [][]byte, argFormats []int16, resultFormat int16) ([]byte, error) {
+	if len(args) > math.MaxUint16 {
+		return nil, fmt.Errorf("function call limited to %v arguments", math.MaxUint16)
+	}
+
+	if err := gaussdbConn.lock(); err != nil {
+		return nil, err
+	}
+	defer gaussdbConn.unlock()
+
+	if ctx != context.Background() {
+		select {
+		case <-ctx.Done():
+			return nil, newContextAlreadyDoneError(ctx)
+		default:
+		}
+		gaussdbConn.contextWatcher.Watch(ctx)
+		defer gaussdbConn.contextWatcher.Unwatch()
+	}
+
+	argFormatCodes := make([]uint16, len(argFormats))
+	for i, f := range argFormats {
+		argFormatCodes[i] = uint16(f)
+	}
+
+	gaussdbConn.frontend.Send(&gaussdbproto.FunctionCall{
+		Function:         oid,
+		ArgFormatCodes:   argFormatCodes,
+		Arguments:        args,
+		ResultFormatCode: uint16(resultFormat),
+	})
+	err := gaussdbConn.flushWithPotentialWriteReadDeadlock()
+	if err != nil {
+		gaussdbConn.asyncClose()
+		return nil, err
+	}
+
+	var result []byte
+	var callErr error
+
+	for {
+		msg, err := gaussdbConn.receiveMessage()
+		if err != nil {
+			gaussdbConn.asyncClose()
+			return nil, normalizeTimeoutError(ctx, err)
+		}
+
+		switch msg := msg.(type) {
+		case *gaussdbproto.FunctionCallResponse:
+			if msg.Result != nil {
+				// msg.Result is only valid until the next message is received.
+				result = make([]byte, len(msg.Result))
+				copy(result, msg.Result)
+			}
+		case *gaussdbproto.ErrorResponse:
+			callErr = ErrorResponseToGuassdbError(msg)
+		case *gaussdbproto.ReadyForQuery:
+			if callErr != nil {
+				return nil, callErr
+			}
+			return result, nil
+		}
+	}
+}
+
 // ErrorResponseToGuassdbError converts a wire protocol error message to a *GaussdbError.
 func ErrorResponseToGuassdbError(msg *gaussdbproto.ErrorResponse) *GaussdbError {
 	return &GaussdbError{
@@ -1168,6 +1534,48 @@ func (gaussdbConn *GaussdbConn) ExecPrepared(ctx context.Context, stmtName strin
 	return result
 }
 
+// fetchPortalName is the name of the portal used by ExecParamsFetch and ExecPreparedFetch.
+const fetchPortalName = "gaussdbconn_fetch"
+
+// ExecParamsFetch is like ExecParams but binds the query to a named portal and fetches at most fetchSize rows per
+// round trip. The next rows are fetched when NextRow has consumed the previous ones. This bounds the memory used for
+// large results. If fetchSize is 0 all rows are fetched at once.
+//
+// Closing the ResultReader before all rows are read closes the portal without fetching the remaining rows. In that case
+// the command tag is empty.
+//
+// ResultReader must be closed before GaussdbConn can be used again.
+func (gaussdbConn *GaussdbConn) ExecParamsFetch(ctx context.Context, sql string, paramValues [][]byte, paramOIDs []uint32, paramFormats []int16, resultFormats []int16, fetchSize uint32) *ResultReader {
+	result := gaussdbConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+
+	gaussdbConn.frontend.SendParse(&gaussdbproto.Parse{Query: sql, ParameterOIDs: paramOIDs})
+	gaussdbConn.frontend.SendBind(&gaussdbproto.Bind{DestinationPortal: fetchPortalName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	gaussdbConn.execExtendedFetchSuffix(result, fetchSize)
+
+	return result
+}
+
+// ExecPreparedFetch is like ExecPrepared but binds the prepared statement to a named portal and fetches at most
+// fetchSize rows per round trip. See ExecParamsFetch for details.
+//
+// ResultReader must be closed before GaussdbConn can be used again.
+func (gaussdbConn *GaussdbConn) ExecPreparedFetch(ctx context.Context, stmtName string, paramValues [][]byte, paramFormats []int16, resultFormats []int16, fetchSize uint32) *ResultReader {
+	result := gaussdbConn.execExtendedPrefix(ctx, paramValues)
+	if result.closed {
+		return result
+	}
+
+	gaussdbConn.frontend.SendBind(&gaussdbproto.Bind{DestinationPortal: fetchPortalName, PreparedStatement: stmtName, ParameterFormatCodes: paramFormats, Parameters: paramValues, ResultFormatCodes: resultFormats})
+
+	gaussdbConn.execExtendedFetchSuffix(result, fetchSize)
+
+	return result
+}
+
 func (gaussdbConn *GaussdbConn) execExtendedPrefix(ctx context.Context, paramValues [][]byte) *ResultReader {
 	gaussdbConn.resultReader = ResultReader{
 		gaussdbConn: gaussdbConn,
@@ -1221,6 +1629,29 @@ func (gaussdbConn *GaussdbConn) execExtendedSuffix(result *ResultReader) {
 	result.readUntilRowDescription()
 }
 
+// execExtendedFetchSuffix executes the portal bound by the caller. It sends Flush instead of Sync so the portal is not
+// destroyed by the end of an implicit transaction while it is suspended. ResultReader.Close sends the Sync.
+func (gaussdbConn *GaussdbConn) execExtendedFetchSuffix(result *ResultReader, fetchSize uint32) {
+	result.portal = fetchPortalName
+	result.fetchSize = fetchSize
+
+	gaussdbConn.frontend.SendDescribe(&gaussdbproto.Describe{ObjectType: 'P', Name: fetchPortalName})
+	gaussdbConn.frontend.SendExecute(&gaussdbproto.Execute{Portal: fetchPortalName, MaxRows: fetchSize})
+	gaussdbConn.frontend.Send(&gaussdbproto.Flush{})
+
+	err := gaussdbConn.flushWithPotentialWriteReadDeadlock()
+	if err != nil {
+		gaussdbConn.asyncClose()
+		result.concludeCommand(CommandTag{}, err)
+		gaussdbConn.contextWatcher.Unwatch()
+		result.closed = true
+		gaussdbConn.unlock()
+		return
+	}
+
+	result.readUntilRowDescription()
+}
+
 // CopyTo executes the copy command sql and copies the results to w.
 func (gaussdbConn *GaussdbConn) CopyTo(ctx context.Context, w io.Writer, sql string) (CommandTag, error) {
 	if err := gaussdbConn.lock(); err != nil {
@@ -1517,6 +1948,11 @@ type ResultReader struct {
 	commandConcluded  bool
 	closed            bool
 	err               error
+
+	// portal and fetchSize are set when the rows are fetched in chunks from a named portal.
+	portal          string
+	fetchSize       uint32
+	portalSuspended bool
 }
 
 // Result is the saved query response that is returned by calling Read on a ResultReader.
@@ -1556,6 +1992,12 @@ func (rr *ResultReader) Read() *Result {
 // NextRow advances the ResultReader to the next row and returns true if a row is available.
 func (rr *ResultReader) NextRow() bool {
 	for !rr.commandConcluded {
+		if rr.portalSuspended {
+			if err := rr.fetchNextChunk(); err != nil {
+				return false
+			}
+		}
+
 		msg, err := rr.receiveMessage()
 		if err != nil {
 			return false
@@ -1592,13 +2034,29 @@ func (rr *ResultReader) Close() (CommandTag, error) {
 	}
 	rr.closed = true
 
-	for !rr.commandConcluded {
+	for !rr.commandConcluded && !rr.portalSuspended {
 		_, err := rr.receiveMessage()
 		if err != nil {
 			return CommandTag{}, rr.err
 		}
 	}
 
+	if rr.portal != "" {
+		// The portal is not fetched to the end if it is still suspended. Close it explicitly as it would otherwise only be
+		// destroyed at the end of the transaction. If the command failed the server ignores everything until the Sync.
+		rr.gaussdbConn.frontend.SendClose(&gaussdbproto.Close{ObjectType: 'P', Name: rr.portal})
+		rr.gaussdbConn.frontend.SendSync(&gaussdbproto.Sync{})
+		err := rr.gaussdbConn.flushWithPotentialWriteReadDeadlock()
+		if err != nil {
+			rr.concludeCommand(CommandTag{}, err)
+			rr.gaussdbConn.contextWatcher.Unwatch()
+			rr.gaussdbConn.asyncClose()
+			return CommandTag{}, rr.err
+		}
+		rr.portalSuspended = false
+		rr.concludeCommand(rr.commandTag, nil)
+	}
+
 	if rr.multiResultReader == nil && rr.pipeline == nil {
 		for {
 			msg, err := rr.receiveMessage()
@@ -1624,7 +2082,7 @@ func (rr *ResultReader) Close() (CommandTag, error) {
 // readUntilRowDescription ensures the ResultReader's fieldDescriptions are loaded. It does not return an error as any
 // error will be stored in the ResultReader.
 func (rr *ResultReader) readUntilRowDescription() {
-	for !rr.commandConcluded {
+	for !rr.commandConcluded && !rr.portalSuspended {
 		// Peek before receive to avoid consuming a DataRow if the result set does not include a RowDescription method.
 		// This should never happen under normal gaussdbconn usage, but it is possible if SendBytes and ReceiveResults are
 		// manually used to construct a query that does not issue a describe statement.
@@ -1667,6 +2125,8 @@ func (rr *ResultReader) receiveMessage() (msg gaussdbproto.BackendMessage, err e
 		rr.concludeCommand(rr.gaussdbConn.makeCommandTag(msg.CommandTag), nil)
 	case *gaussdbproto.EmptyQueryResponse:
 		rr.concludeCommand(CommandTag{}, nil)
+	case *gaussdbproto.PortalSuspended:
+		rr.portalSuspended = true
 	case *gaussdbproto.ErrorResponse:
 		gaussdbError := ErrorResponseToGuassdbError(msg)
 		if rr.pipeline != nil {
@@ -1678,6 +2138,24 @@ func (rr *ResultReader) receiveMessage() (msg gaussdbproto.BackendMessage, err e
 	return msg, nil
 }
 
+// fetchNextChunk requests the next rows of the suspended portal.
+func (rr *ResultReader) fetchNextChunk() error {
+	rr.portalSuspended = false
+
+	rr.gaussdbConn.frontend.SendExecute(&gaussdbproto.Execute{Portal: rr.portal, MaxRows: rr.fetchSize})
+	rr.gaussdbConn.frontend.Send(&gaussdbproto.Flush{})
+	err := rr.gaussdbConn.flushWithPotentialWriteReadDeadlock()
+	if err != nil {
+		rr.concludeCommand(CommandTag{}, err)
+		rr.gaussdbConn.contextWatcher.Unwatch()
+		rr.closed = true
+		rr.gaussdbConn.asyncClose()
+		return err
+	}
+
+	return nil
+}
+
 func (rr *ResultReader) concludeCommand(commandTag CommandTag, err error) {
 	// Keep the first error that is recorded. Store the error before checking if the command is already concluded to
 	// allow for receiving an error after CommandComplete but before ReadyForQuery.
@@ -2484,6 +2962,7 @@ const (
 	PlainPassword  = 0
 	Md5Password    = 1
 	Sha256Password = 2
+	Sm3Password    = 3
 )
 
 func (gaussdbConn *GaussdbConn) writeBuf(b byte) *writeBuf {
@@ -1,9 +1,25 @@
 package gaussdbconn
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/gaussdbmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommandTag(t *testing.T) {
@@ -39,3 +55,240 @@ func TestCommandTag(t *testing.T) {
 		assert.Equalf(t, tt.isSelect, ct.Select(), "%d. %v", i, tt.commandTag)
 	}
 }
+
+func TestBuildConnectOneConfigsLoadBalanceHosts(t *testing.T) {
+	t.Parallel()
+
+	lookupFunc := func(ctx context.Context, host string) ([]string, error) {
+		return []string{host + "1", host + "2"}, nil
+	}
+
+	hostOrder := func(t *testing.T, config *Config) []string {
+		configs, errs := buildConnectOneConfigs(context.Background(), config)
+		require.Empty(t, errs)
+
+		var hosts []string
+		for _, c := range configs {
+			if len(hosts) == 0 || hosts[len(hosts)-1] != c.originalHostname {
+				hosts = append(hosts, c.originalHostname)
+			}
+		}
+		return hosts
+	}
+
+	t.Run("disable", func(t *testing.T) {
+		config, err := ParseConfig("host=a,b,c sslmode=prefer load_balance_hosts=disable")
+		require.NoError(t, err)
+		config.LookupFunc = lookupFunc
+
+		configs, errs := buildConnectOneConfigs(context.Background(), config)
+		require.Empty(t, errs)
+		require.Len(t, configs, 12)
+		assert.Equal(t, "a1:5432", configs[0].address)
+		assert.NotNil(t, configs[0].tlsConfig)
+		assert.Equal(t, "a2:5432", configs[1].address)
+		assert.Equal(t, "a1:5432", configs[2].address)
+		assert.Nil(t, configs[2].tlsConfig)
+		assert.Equal(t, []string{"a", "b", "c"}, hostOrder(t, config))
+	})
+
+	t.Run("roundrobin shares state with copies", func(t *testing.T) {
+		config, err := ParseConfig("host=a,b,c sslmode=prefer load_balance_hosts=roundrobin")
+		require.NoError(t, err)
+		config.LookupFunc = lookupFunc
+
+		assert.Equal(t, []string{"a", "b", "c"}, hostOrder(t, config))
+		assert.Equal(t, []string{"b", "c", "a"}, hostOrder(t, config.Copy()))
+		assert.Equal(t, []string{"c", "a", "b"}, hostOrder(t, config))
+		assert.Equal(t, []string{"a", "b", "c"}, hostOrder(t, config))
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		config, err := ParseConfig("host=a,b,c,d sslmode=disable load_balance_hosts=priority2")
+		require.NoError(t, err)
+		config.LookupFunc = lookupFunc
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, hostOrder(t, config))
+		assert.Equal(t, []string{"b", "a", "c", "d"}, hostOrder(t, config))
+		assert.Equal(t, []string{"a", "b", "c", "d"}, hostOrder(t, config))
+	})
+
+	for _, mode := range []string{"random", "shuffle"} {
+		t.Run(mode, func(t *testing.T) {
+			config, err := ParseConfig("host=a,b,c sslmode=prefer load_balance_hosts=" + mode)
+			require.NoError(t, err)
+			config.LookupFunc = lookupFunc
+
+			configs, errs := buildConnectOneConfigs(context.Background(), config)
+			require.Empty(t, errs)
+			require.Len(t, configs, 12)
+
+			hosts := hostOrder(t, config)
+			assert.ElementsMatch(t, []string{"a", "b", "c"}, hosts)
+		})
+	}
+}
+
+func TestCoordinatorDiscovery(t *testing.T) {
+	t.Parallel()
+
+	// The discovered coordinator only needs to be listening. It is never connected to.
+	discoveredLn, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer discoveredLn.Close()
+	discoveredHost, discoveredPort, _ := strings.Cut(discoveredLn.Addr().String(), ":")
+
+	seedLn, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer seedLn.Close()
+	seedHost, seedPort, _ := strings.Cut(seedLn.Addr().String(), ":")
+
+	userSteps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+	userSteps = append(userSteps, gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}))
+
+	refreshSteps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+	refreshSteps = append(refreshSteps,
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: coordinatorListSQL}),
+		gaussdbmock.SendMessage(&gaussdbproto.RowDescription{Fields: []gaussdbproto.FieldDescription{
+			{Name: []byte("node_host"), DataTypeOID: 19, DataTypeSize: 64, TypeModifier: -1},
+			{Name: []byte("node_port"), DataTypeOID: 23, DataTypeSize: 4, TypeModifier: -1},
+		}}),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte(discoveredHost), []byte(discoveredPort)}}),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte(seedHost), []byte(seedPort)}}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT 2")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+	)
+
+	serverErrChan := make(chan error, 2)
+	go func() {
+		for _, steps := range [][]gaussdbmock.Step{userSteps, refreshSteps} {
+			conn, err := seedLn.Accept()
+			if err != nil {
+				serverErrChan <- err
+				return
+			}
+
+			script := &gaussdbmock.Script{Steps: steps}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+				serverErrChan <- script.Run(gaussdbproto.NewBackend(conn, conn))
+			}()
+		}
+	}()
+
+	config, err := ParseConfig(fmt.Sprintf("sslmode=disable host=%s port=%s refresh_cn_ip_list_time=3600", seedHost, seedPort))
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, config.RefreshCNListInterval)
+
+	configs, errs := buildConnectOneConfigs(context.Background(), config)
+	require.Empty(t, errs)
+	require.Len(t, configs, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	gaussdbConn, err := ConnectConfig(ctx, config.Copy())
+	require.NoError(t, err)
+	require.NoError(t, gaussdbConn.Close(ctx))
+
+	require.Eventually(t, func() bool {
+		config.coordinatorDiscovery.mux.Lock()
+		defer config.coordinatorDiscovery.mux.Unlock()
+		return !config.coordinatorDiscovery.lastRefresh.IsZero()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, <-serverErrChan)
+	}
+
+	configs, errs = buildConnectOneConfigs(context.Background(), config)
+	require.Empty(t, errs)
+	require.Len(t, configs, 2)
+	assert.Equal(t, discoveredLn.Addr().String(), configs[0].address)
+	assert.Equal(t, seedLn.Addr().String(), configs[1].address)
+}
+
+func TestScramClientChannelBinding(t *testing.T) {
+	t.Parallel()
+
+	cbData := []byte("0123456789abcdef0123456789abcdef")
+	both := []string{"SCRAM-SHA-256-PLUS", "SCRAM-SHA-256"}
+	plain := []string{"SCRAM-SHA-256"}
+
+	tests := []struct {
+		name           string
+		mechanisms     []string
+		channelBinding string
+		cbData         []byte
+		mechanism      string
+		gs2Header      string
+		err            string
+	}{
+		{"TLS and server supports PLUS", both, "prefer", cbData, "SCRAM-SHA-256-PLUS", "p=tls-server-end-point,,", ""},
+		{"TLS and server does not support PLUS", plain, "prefer", cbData, "SCRAM-SHA-256", "y,,", ""},
+		{"no TLS", both, "prefer", nil, "SCRAM-SHA-256", "n,,", ""},
+		{"require", both, "require", cbData, "SCRAM-SHA-256-PLUS", "p=tls-server-end-point,,", ""},
+		{"require without TLS", both, "require", nil, "", "", "connection does not use TLS"},
+		{"require and server does not support PLUS", plain, "require", cbData, "", "", "server does not support SCRAM-SHA-256-PLUS"},
+	}
+
+	for _, tt := range tests {
+		sc, err := newScramClient(tt.mechanisms, "secret", tt.channelBinding, tt.cbData)
+		if tt.err != "" {
+			require.ErrorContains(t, err, tt.err, tt.name)
+			continue
+		}
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, tt.mechanism, sc.authMechanism, tt.name)
+
+		clientFirstMessage := string(sc.clientFirstMessage())
+		assert.True(t, strings.HasPrefix(clientFirstMessage, tt.gs2Header+"n=,r="), tt.name)
+
+		serverFirstMessage := fmt.Sprintf("r=%sservernonce,s=%s,i=4096", sc.clientNonce, base64.StdEncoding.EncodeToString([]byte("salt")))
+		require.NoError(t, sc.recvServerFirstMessage([]byte(serverFirstMessage)), tt.name)
+
+		expectedBinding := []byte(tt.gs2Header)
+		if tt.mechanism == "SCRAM-SHA-256-PLUS" {
+			expectedBinding = append(expectedBinding, cbData...)
+		}
+		assert.True(t, strings.HasPrefix(sc.clientFinalMessage(), "c="+base64.StdEncoding.EncodeToString(expectedBinding)+",r="), tt.name)
+	}
+}
+
+func TestTLSServerEndPoint(t *testing.T) {
+	t.Parallel()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		signatureAlgorithm x509.SignatureAlgorithm
+		hash               crypto.Hash
+	}{
+		{x509.ECDSAWithSHA256, crypto.SHA256},
+		{x509.ECDSAWithSHA384, crypto.SHA384},
+		{x509.ECDSAWithSHA512, crypto.SHA512},
+	} {
+		template := &x509.Certificate{
+			SerialNumber:       big.NewInt(1),
+			NotBefore:          time.Now(),
+			NotAfter:           time.Now().Add(time.Hour),
+			SignatureAlgorithm: tt.signatureAlgorithm,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(t, err)
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+
+		h := tt.hash.New()
+		h.Write(der)
+
+		cbData, err := tlsServerEndPoint(tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}})
+		require.NoError(t, err)
+		assert.Equal(t, h.Sum(nil), cbData, tt.signatureAlgorithm.String())
+	}
+
+	_, err = tlsServerEndPoint(tls.ConnectionState{})
+	require.Error(t, err)
+}
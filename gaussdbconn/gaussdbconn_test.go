@@ -1,17 +1,29 @@
 package gaussdbconn_test
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"math"
+	"math/big"
 	"net"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -20,6 +32,9 @@ import (
 	gaussdbgo "github.com/HuaweiCloudDeveloper/gaussdb-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tjfoc/gmsm/gmtls"
+	"github.com/tjfoc/gmsm/sm2"
+	gmx509 "github.com/tjfoc/gmsm/x509"
 
 	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
 	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn/ctxwatch"
@@ -546,431 +561,632 @@ func TestConnectFailsWithResolveFailureAndFailedConnectionAttempts(t *testing.T)
 	require.ErrorContains(t, err, ":2 (127.0.0.1): dial error:")
 }
 
-func TestConnectWithValidateConnect(t *testing.T) {
+func TestConnectParallel(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
-
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	// The first host accepts the connection but never responds.
+	blackholeLn, err := net.Listen("tcp", "127.0.0.1:")
 	require.NoError(t, err)
-
-	dialCount := 0
-	config.DialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
-		dialCount++
-		return net.Dial(network, address)
-	}
-
-	acceptConnCount := 0
-	config.ValidateConnect = func(ctx context.Context, conn *gaussdbconn.GaussdbConn) error {
-		acceptConnCount++
-		if acceptConnCount < 2 {
-			return errors.New("reject first conn")
+	defer blackholeLn.Close()
+	go func() {
+		for {
+			conn, err := blackholeLn.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
 		}
-		return nil
-	}
+	}()
 
-	// Append current primary config to fallbacks
-	config.Fallbacks = append(config.Fallbacks, &gaussdbconn.FallbackConfig{
-		Host:      config.Host,
-		Port:      config.Port,
-		TLSConfig: config.TLSConfig,
-	})
+	steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps, gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}))
+	ln, serverErrChan := serveGaussdbMockScript(t, &gaussdbmock.Script{Steps: steps})
 
-	// Repeat fallbacks
-	config.Fallbacks = append(config.Fallbacks, config.Fallbacks...)
+	_, blackholePort, _ := strings.Cut(blackholeLn.Addr().String(), ":")
+	_, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=127.0.0.1,127.0.0.1 port=%s,%s connect_timeout=10 parallel_connect_delay=50", blackholePort, port)
 
-	conn, err := gaussdbconn.ConnectConfig(ctx, config)
+	config, err := gaussdbconn.ParseConfig(connStr)
 	require.NoError(t, err)
-	closeConn(t, conn)
-
-	assert.True(t, dialCount > 1)
-	assert.True(t, acceptConnCount > 1)
-}
-
-func TestConnectWithValidateConnectTargetSessionAttrsReadWrite(t *testing.T) {
-	t.Parallel()
+	require.Equal(t, 50*time.Millisecond, config.ParallelConnectDelay)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	tooLate := time.Now().Add(2 * time.Second)
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	conn, err := gaussdbconn.ConnectConfig(ctx, config)
 	require.NoError(t, err)
+	require.True(t, time.Now().Before(tooLate))
+	require.Equal(t, ln.Addr().String(), conn.Conn().RemoteAddr().String())
 
-	config.ValidateConnect = gaussdbconn.ValidateConnectTargetSessionAttrsReadWrite
-	config.RuntimeParams["default_transaction_read_only"] = "on"
-
-	conn, err := gaussdbconn.ConnectConfig(ctx, config)
-	if !assert.NotNil(t, err) {
-		conn.Close(ctx)
-	}
+	closeConn(t, conn)
+	require.NoError(t, <-serverErrChan)
 }
 
-func TestConnectWithAfterConnect(t *testing.T) {
+func TestConnectParallelFatalErrorStopsAttempts(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
-
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-
-	config.AfterConnect = func(ctx context.Context, conn *gaussdbconn.GaussdbConn) error {
-		_, err := conn.Exec(ctx, "set search_path to foobar;").ReadAll()
-		return err
+	script := &gaussdbmock.Script{
+		Steps: []gaussdbmock.Step{
+			gaussdbmock.ExpectAnyMessage(&gaussdbproto.StartupMessage{ProtocolVersion: gaussdbproto.ProtocolVersionNumber, Parameters: map[string]string{}}),
+			gaussdbmock.SendMessage(&gaussdbproto.ErrorResponse{Severity: "FATAL", Code: "28P01", Message: "password authentication failed"}),
+		},
 	}
+	ln, serverErrChan := serveGaussdbMockScript(t, script)
 
-	conn, err := gaussdbconn.ConnectConfig(ctx, config)
-	require.NoError(t, err)
-
-	results, err := conn.Exec(ctx, "show search_path;").ReadAll()
+	otherLn, err := net.Listen("tcp", "127.0.0.1:")
 	require.NoError(t, err)
-	defer closeConn(t, conn)
-
-	assert.Equal(t, []byte("foobar"), results[0].Rows[0][0])
-}
+	defer otherLn.Close()
+	otherAccepted := make(chan struct{}, 1)
+	go func() {
+		conn, err := otherLn.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+		otherAccepted <- struct{}{}
+	}()
 
-func TestConnectConfigRequiresConfigFromParseConfig(t *testing.T) {
-	t.Parallel()
+	_, port, _ := strings.Cut(ln.Addr().String(), ":")
+	_, otherPort, _ := strings.Cut(otherLn.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=127.0.0.1,127.0.0.1 port=%s,%s parallel_connect_delay=200", port, otherPort)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	config := &gaussdbconn.Config{}
+	_, err = gaussdbconn.Connect(ctx, connStr)
+	require.ErrorContains(t, err, "28P01")
+	require.NoError(t, <-serverErrChan)
 
-	require.PanicsWithValue(t, "config must be created by ParseConfig", func() { gaussdbconn.ConnectConfig(ctx, config) })
+	select {
+	case <-otherAccepted:
+		t.Fatal("connection attempted after fatal error")
+	case <-time.After(400 * time.Millisecond):
+	}
 }
 
-func TestConnPrepareSyntaxError(t *testing.T) {
-	t.Parallel()
+func serveGaussdbMockScript(t *testing.T, script *gaussdbmock.Script) (net.Listener, chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
 
-	psd, err := gaussdbConn.Prepare(ctx, "ps1", "SYNTAX ERROR", nil)
-	require.Nil(t, psd)
-	require.NotNil(t, err)
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
 
-	ensureConnValid(t, gaussdbConn)
+		err = script.Run(gaussdbproto.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	return ln, serverErrChan
 }
 
-func TestConnPrepareContextPrecanceled(t *testing.T) {
+func TestConnectThroughProxy(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	for _, scheme := range []string{"socks5", "socks5h", "http"} {
+		scheme := scheme
+		t.Run(scheme, func(t *testing.T) {
+			t.Parallel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+			serverLn, err := net.Listen("tcp", "127.0.0.1:")
+			require.NoError(t, err)
+			defer serverLn.Close()
 
-	cancel()
+			serverErrChan := make(chan error, 1)
+			go func() {
+				defer close(serverErrChan)
 
-	psd, err := gaussdbConn.Prepare(ctx, "ps1", "select 1", nil)
-	assert.Nil(t, psd)
-	assert.Error(t, err)
-	assert.True(t, errors.Is(err, context.Canceled))
-	assert.True(t, gaussdbconn.SafeToRetry(err))
+				conn, err := serverLn.Accept()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
 
-	ensureConnValid(t, gaussdbConn)
-}
+				steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+				steps = append(steps, gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}))
+				scriptErrChan := make(chan error, 1)
+				go func() {
+					scriptErrChan <- (&gaussdbmock.Script{Steps: steps}).Run(gaussdbproto.NewBackend(conn, conn))
+				}()
 
-func TestConnDeallocate(t *testing.T) {
-	t.Parallel()
+				cancelConn, err := serverLn.Accept()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+				cancelConn.SetDeadline(time.Now().Add(5 * time.Second))
+				msg, err := gaussdbproto.NewBackend(cancelConn, cancelConn).ReceiveStartupMessage()
+				cancelConn.Close()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+				if _, ok := msg.(*gaussdbproto.CancelRequest); !ok {
+					serverErrChan <- fmt.Errorf("expected CancelRequest, got %T", msg)
+					return
+				}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+				serverErrChan <- <-scriptErrChan
+			}()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+			proxyLn, targets := serveTestProxy(t, scheme)
 
-	_, err = gaussdbConn.Prepare(ctx, "ps1", "select 1", nil)
-	require.NoError(t, err)
+			// With socks5 the host name is resolved locally. Otherwise it is passed to the proxy.
+			_, port, _ := strings.Cut(serverLn.Addr().String(), ":")
+			expectedTarget := net.JoinHostPort("127.0.0.1", port)
+			host := "127.0.0.1"
+			if scheme != "socks5" {
+				host = "localhost"
+				expectedTarget = net.JoinHostPort("localhost", port)
+			}
+			connStr := fmt.Sprintf("sslmode=disable host=%s port=%s proxy=%s://user:secret@%s", host, port, scheme, proxyLn.Addr())
 
-	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
-	require.NoError(t, err)
+			config, err := gaussdbconn.ParseConfig(connStr)
+			require.NoError(t, err)
+			assert.NotContains(t, config.RuntimeParams, "proxy")
 
-	err = gaussdbConn.Deallocate(ctx, "ps1")
-	require.NoError(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
 
-	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
-	require.Error(t, err)
-	var gaussdbError *gaussdbconn.GaussdbError
-	require.ErrorAs(t, err, &gaussdbError)
-	require.Equal(t, "26010", gaussdbError.Code)
+			conn, err := gaussdbconn.ConnectConfig(ctx, config)
+			require.NoError(t, err)
+			require.Equal(t, expectedTarget, conn.Conn().RemoteAddr().String())
+			require.Equal(t, expectedTarget, <-targets)
 
-	ensureConnValid(t, gaussdbConn)
+			require.NoError(t, conn.CancelRequest(ctx))
+			require.Equal(t, expectedTarget, <-targets)
+
+			closeConn(t, conn)
+			require.NoError(t, <-serverErrChan)
+		})
+	}
 }
 
-func TestConnDeallocateSucceedsInAbortedTransaction(t *testing.T) {
+func TestConnectThroughProxyAuthenticationFailure(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
-
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
-
-	err = gaussdbConn.Exec(ctx, "begin").Close()
-	require.NoError(t, err)
+	for _, scheme := range []string{"socks5", "http"} {
+		scheme := scheme
+		t.Run(scheme, func(t *testing.T) {
+			t.Parallel()
 
-	_, err = gaussdbConn.Prepare(ctx, "ps1", "select 1", nil)
-	require.NoError(t, err)
+			proxyLn, _ := serveTestProxy(t, scheme)
 
-	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
-	require.NoError(t, err)
+			config, err := gaussdbconn.ParseConfig(fmt.Sprintf("sslmode=disable host=127.0.0.1 port=1 proxy=%s://user:wrong@%s", scheme, proxyLn.Addr()))
+			require.NoError(t, err)
 
-	err = gaussdbConn.Exec(ctx, "select 1/0").Close() // break transaction with divide by 0 error
-	require.Error(t, err)
-	var gaussdbError *gaussdbconn.GaussdbError
-	require.ErrorAs(t, err, &gaussdbError)
-	require.Equal(t, "22012", gaussdbError.Code)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
 
-	err = gaussdbConn.Deallocate(ctx, "ps1")
-	require.NoError(t, err)
+			_, err = gaussdbconn.ConnectConfig(ctx, config)
+			require.ErrorContains(t, err, "proxy")
+		})
+	}
+}
 
-	err = gaussdbConn.Exec(ctx, "rollback").Close()
+// serveTestProxy starts a minimal SOCKS5 or HTTP CONNECT proxy that requires the username "user" and the password
+// "secret". The target address of every tunnel it establishes is sent to the returned channel.
+func serveTestProxy(t *testing.T, scheme string) (net.Listener, chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
 	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
 
-	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
-	require.Error(t, err)
-	require.ErrorAs(t, err, &gaussdbError)
-	require.Equal(t, "26010", gaussdbError.Code)
+	targets := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
 
-	ensureConnValid(t, gaussdbConn)
-}
+			go func() {
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
 
-func TestConnDeallocateNonExistantStatementSucceeds(t *testing.T) {
-	t.Parallel()
+				br := bufio.NewReader(conn)
+				var target string
+				if scheme == "http" {
+					target, err = acceptTestHTTPConnect(br, conn)
+				} else {
+					target, err = acceptTestSOCKS5Connect(br, conn)
+				}
+				if err != nil {
+					return
+				}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+				// Resolve localhost to the IPv4 loopback the test servers listen on.
+				host, port, _ := net.SplitHostPort(target)
+				if host == "localhost" {
+					host = "127.0.0.1"
+				}
+				targetConn, err := net.Dial("tcp", net.JoinHostPort(host, port))
+				if err != nil {
+					return
+				}
+				defer targetConn.Close()
+				targets <- target
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+				if scheme == "http" {
+					_, err = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+				} else {
+					_, err = conn.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+				}
+				if err != nil {
+					return
+				}
 
-	err = gaussdbConn.Deallocate(ctx, "ps1")
-	require.NoError(t, err)
+				go io.Copy(targetConn, br)
+				io.Copy(conn, targetConn)
+			}()
+		}
+	}()
 
-	ensureConnValid(t, gaussdbConn)
+	return ln, targets
 }
 
-func TestConnExec(t *testing.T) {
-	t.Parallel()
+func acceptTestSOCKS5Connect(br *bufio.Reader, conn net.Conn) (string, error) {
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(br, make([]byte, buf[1])); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write([]byte{0x05, 0x02}); err != nil {
+		return "", err
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
-
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	readString := func() (string, error) {
+		n, err := br.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		s := make([]byte, n)
+		_, err = io.ReadFull(br, s)
+		return string(s), err
+	}
+	if _, err := br.ReadByte(); err != nil {
+		return "", err
+	}
+	username, err := readString()
+	if err != nil {
+		return "", err
+	}
+	password, err := readString()
+	if err != nil {
+		return "", err
+	}
+	if username != "user" || password != "secret" {
+		conn.Write([]byte{0x01, 0x01})
+		return "", errors.New("bad credentials")
+	}
+	if _, err := conn.Write([]byte{0x01, 0x00}); err != nil {
+		return "", err
+	}
 
-	results, err := gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
-	assert.NoError(t, err)
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return "", err
+	}
+	var host string
+	switch header[3] {
+	case 0x01:
+		ip := make([]byte, net.IPv4len)
+		if _, err := io.ReadFull(br, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case 0x03:
+		host, err = readString()
+		if err != nil {
+			return "", err
+		}
+	default:
+		return "", fmt.Errorf("unsupported address type %d", header[3])
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(br, port); err != nil {
+		return "", err
+	}
 
-	assert.Len(t, results, 1)
-	assert.Nil(t, results[0].Err)
-	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
-	assert.Len(t, results[0].Rows, 1)
-	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+	return net.JoinHostPort(host, strconv.Itoa(int(port[0])<<8|int(port[1]))), nil
+}
 
-	ensureConnValid(t, gaussdbConn)
+func acceptTestHTTPConnect(br *bufio.Reader, conn net.Conn) (string, error) {
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return "", err
+	}
+	if req.Method != http.MethodConnect {
+		return "", fmt.Errorf("unexpected method %s", req.Method)
+	}
+	if req.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")) {
+		io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+		return "", errors.New("bad credentials")
+	}
+	return req.Host, nil
 }
 
-func TestConnExecEmpty(t *testing.T) {
+func TestConnectGetPassword(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	tests := []struct {
+		name             string
+		getPassword      string
+		expectedPassword string
+	}{
+		{"rotated password", "rotated", "rotated"},
+		{"empty falls back to Password", "", "static"},
+	}
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
 
-	multiResult := gaussdbConn.Exec(ctx, ";")
+			script := &gaussdbmock.Script{
+				Steps: []gaussdbmock.Step{
+					gaussdbmock.ExpectAnyMessage(&gaussdbproto.StartupMessage{ProtocolVersion: gaussdbproto.ProtocolVersionNumber, Parameters: map[string]string{}}),
+					gaussdbmock.SendMessage(&gaussdbproto.AuthenticationCleartextPassword{}),
+					gaussdbmock.ExpectMessage(&gaussdbproto.PasswordMessage{Password: tt.expectedPassword}),
+					gaussdbmock.SendMessage(&gaussdbproto.AuthenticationOk{}),
+					gaussdbmock.SendMessage(&gaussdbproto.BackendKeyData{ProcessID: 0, SecretKey: 0}),
+					gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+					gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+				},
+			}
+			ln, serverErrChan := serveGaussdbMockScript(t, script)
 
-	resultCount := 0
-	for multiResult.NextResult() {
-		resultCount++
-		multiResult.ResultReader().Close()
-	}
-	assert.Equal(t, 0, resultCount)
-	err = multiResult.Close()
-	assert.NoError(t, err)
+			host, port, _ := strings.Cut(ln.Addr().String(), ":")
+			config, err := gaussdbconn.ParseConfig(fmt.Sprintf("sslmode=disable host=%s port=%s user=gaussdb password=static", host, port))
+			require.NoError(t, err)
 
-	ensureConnValid(t, gaussdbConn)
+			var gotHost, gotUser string
+			var gotPort uint16
+			config.GetPassword = func(ctx context.Context, host string, port uint16, user string) (string, error) {
+				gotHost, gotPort, gotUser = host, port, user
+				return tt.getPassword, nil
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := gaussdbconn.ConnectConfig(ctx, config)
+			require.NoError(t, err)
+			assert.Equal(t, host, gotHost)
+			assert.Equal(t, port, strconv.Itoa(int(gotPort)))
+			assert.Equal(t, "gaussdb", gotUser)
+
+			closeConn(t, conn)
+			require.NoError(t, <-serverErrChan)
+		})
+	}
 }
 
-func TestConnExecMultipleQueries(t *testing.T) {
+func TestConnectGetPasswordError(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	config, err := gaussdbconn.ParseConfig("sslmode=disable host=127.0.0.1 port=1 user=gaussdb")
+	require.NoError(t, err)
+
+	dialed := false
+	config.DialFunc = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = true
+		return nil, errors.New("unexpected dial")
+	}
+	config.GetPassword = func(ctx context.Context, host string, port uint16, user string) (string, error) {
+		return "", errors.New("vault unavailable")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	_, err = gaussdbconn.ConnectConfig(ctx, config)
+	require.ErrorContains(t, err, "vault unavailable")
+	require.False(t, dialed)
+}
 
-	results, err := gaussdbConn.Exec(ctx, "select 'Hello, world'; select 1").ReadAll()
-	assert.NoError(t, err)
+func TestConnectChannelBindingRequireRejectsPasswordAuth(t *testing.T) {
+	t.Parallel()
 
-	assert.Len(t, results, 2)
+	// The password must not be sent when channel binding is required but the server asks for a cleartext password.
+	script := &gaussdbmock.Script{
+		Steps: []gaussdbmock.Step{
+			gaussdbmock.ExpectAnyMessage(&gaussdbproto.StartupMessage{ProtocolVersion: gaussdbproto.ProtocolVersionNumber, Parameters: map[string]string{}}),
+			gaussdbmock.SendMessage(&gaussdbproto.AuthenticationCleartextPassword{}),
+		},
+	}
+	ln, serverErrChan := serveGaussdbMockScript(t, script)
 
-	assert.Nil(t, results[0].Err)
-	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
-	assert.Len(t, results[0].Rows, 1)
-	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	config, err := gaussdbconn.ParseConfig(fmt.Sprintf("sslmode=disable host=%s port=%s user=gaussdb password=secret channel_binding=require", host, port))
+	require.NoError(t, err)
 
-	assert.Nil(t, results[1].Err)
-	assert.Equal(t, "SELECT 1", results[1].CommandTag.String())
-	assert.Len(t, results[1].Rows, 1)
-	assert.Equal(t, "1", string(results[1].Rows[0][0]))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	ensureConnValid(t, gaussdbConn)
+	_, err = gaussdbconn.ConnectConfig(ctx, config)
+	require.ErrorContains(t, err, "channel binding required")
+	require.NoError(t, <-serverErrChan)
 }
 
-func TestConnExecMultipleQueriesEagerFieldDescriptions(t *testing.T) {
+func TestConnectWithValidateConnect(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
 
-	mrr := gaussdbConn.Exec(ctx, "select 'Hello, world' as msg; select 1 as num")
+	dialCount := 0
+	config.DialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialCount++
+		return net.Dial(network, address)
+	}
 
-	require.True(t, mrr.NextResult())
-	require.Len(t, mrr.ResultReader().FieldDescriptions(), 1)
-	assert.Equal(t, "msg", mrr.ResultReader().FieldDescriptions()[0].Name)
-	_, err = mrr.ResultReader().Close()
-	require.NoError(t, err)
+	acceptConnCount := 0
+	config.ValidateConnect = func(ctx context.Context, conn *gaussdbconn.GaussdbConn) error {
+		acceptConnCount++
+		if acceptConnCount < 2 {
+			return errors.New("reject first conn")
+		}
+		return nil
+	}
 
-	require.True(t, mrr.NextResult())
-	require.Len(t, mrr.ResultReader().FieldDescriptions(), 1)
-	assert.Equal(t, "num", mrr.ResultReader().FieldDescriptions()[0].Name)
-	_, err = mrr.ResultReader().Close()
-	require.NoError(t, err)
+	// Append current primary config to fallbacks
+	config.Fallbacks = append(config.Fallbacks, &gaussdbconn.FallbackConfig{
+		Host:      config.Host,
+		Port:      config.Port,
+		TLSConfig: config.TLSConfig,
+	})
 
-	require.False(t, mrr.NextResult())
+	// Repeat fallbacks
+	config.Fallbacks = append(config.Fallbacks, config.Fallbacks...)
 
-	require.NoError(t, mrr.Close())
+	conn, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	closeConn(t, conn)
 
-	ensureConnValid(t, gaussdbConn)
+	assert.True(t, dialCount > 1)
+	assert.True(t, acceptConnCount > 1)
 }
 
-func TestConnExecMultipleQueriesError(t *testing.T) {
+func TestConnectWithValidateConnectTargetSessionAttrsReadWrite(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
 
-	results, err := gaussdbConn.Exec(ctx, "select 1; select 1/0; select 1").ReadAll()
-	require.NotNil(t, err)
-	if gaussdbError, ok := err.(*gaussdbconn.GaussdbError); ok {
-		assert.Equal(t, "22012", gaussdbError.Code)
-	} else {
-		t.Errorf("unexpected error: %v", err)
-	}
-	// Gaussdb sends the divide by zero and never sends the second query result set.
-	require.Len(t, results, 1)
-	assert.Len(t, results[0].Rows, 1)
-	assert.Equal(t, "1", string(results[0].Rows[0][0]))
+	config.ValidateConnect = gaussdbconn.ValidateConnectTargetSessionAttrsReadWrite
+	config.RuntimeParams["default_transaction_read_only"] = "on"
 
-	ensureConnValid(t, gaussdbConn)
+	conn, err := gaussdbconn.ConnectConfig(ctx, config)
+	if !assert.NotNil(t, err) {
+		conn.Close(ctx)
+	}
 }
 
-func TestConnExecDeferredError(t *testing.T) {
+func TestValidateConnectTargetSessionAttrsLocalRole(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	tests := []struct {
+		localRole               string
+		masterErr               bool
+		slaveErr                bool
+		preferSlaveNotPreferred bool
+	}{
+		{localRole: "Primary", slaveErr: true, preferSlaveNotPreferred: true},
+		{localRole: "Normal", slaveErr: true, preferSlaveNotPreferred: true},
+		{localRole: "Standby", masterErr: true},
+		{localRole: "Cascade Standby", masterErr: true},
+		{localRole: "Pending", masterErr: true, slaveErr: true, preferSlaveNotPreferred: true},
+	}
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	validate := func(t *testing.T, localRole string, validateConnect gaussdbconn.ValidateConnectFunc) error {
+		steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+		steps = append(steps,
+			gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "select local_role from pg_stat_get_stream_replications()"}),
+			gaussdbmock.SendMessage(&gaussdbproto.RowDescription{Fields: []gaussdbproto.FieldDescription{
+				{Name: []byte("local_role"), DataTypeOID: 25, DataTypeSize: -1, TypeModifier: -1},
+			}}),
+			gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte(localRole)}}),
+			gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT 1")}),
+			gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+			gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+		)
+		ln, serverErrChan := serveGaussdbMockScript(t, &gaussdbmock.Script{Steps: steps})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
 
-	setupSQL := `create temporary table t (
-		id text primary key,
-		n int not null,
-		unique (n) deferrable initially deferred
-	);
+		host, port, _ := strings.Cut(ln.Addr().String(), ":")
+		conn, err := gaussdbconn.Connect(ctx, fmt.Sprintf("sslmode=disable host=%s port=%s", host, port))
+		require.NoError(t, err)
 
-	insert into t (id, n) values ('a', 1), ('b', 2), ('c', 3);`
+		validateErr := validateConnect(ctx, conn)
+		closeConn(t, conn)
+		require.NoError(t, <-serverErrChan)
 
-	_, err = gaussdbConn.Exec(ctx, setupSQL).ReadAll()
-	assert.NoError(t, err)
+		return validateErr
+	}
 
-	_, err = gaussdbConn.Exec(ctx, `update t set n=n+1 where id='b' returning *`).ReadAll()
-	require.NotNil(t, err)
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.localRole, func(t *testing.T) {
+			t.Parallel()
 
-	var gaussdbError *gaussdbconn.GaussdbError
-	require.True(t, errors.As(err, &gaussdbError))
-	require.Equal(t, "23505", gaussdbError.Code)
+			err := validate(t, tt.localRole, gaussdbconn.ValidateConnectTargetSessionAttrsMaster)
+			assert.Equal(t, tt.masterErr, err != nil, err)
 
-	ensureConnValid(t, gaussdbConn)
+			err = validate(t, tt.localRole, gaussdbconn.ValidateConnectTargetSessionAttrsSlave)
+			assert.Equal(t, tt.slaveErr, err != nil, err)
+
+			err = validate(t, tt.localRole, gaussdbconn.ValidateConnectTargetSessionAttrsPreferSlave)
+			var npErr *gaussdbconn.NotPreferredError
+			assert.Equal(t, tt.preferSlaveNotPreferred, errors.As(err, &npErr), err)
+		})
+	}
 }
 
-func TestConnExecContextCanceled(t *testing.T) {
+func TestConnectWithAfterConnect(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
-	cancel()
-
-	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
-	defer cancel()
-	multiResult := gaussdbConn.Exec(ctx, "select 'Hello, world', pg_sleep(1)")
 
-	for multiResult.NextResult() {
-	}
-	err = multiResult.Close()
-	assert.True(t, gaussdbconn.Timeout(err))
-	assert.ErrorIs(t, err, context.DeadlineExceeded)
-	assert.True(t, gaussdbConn.IsClosed())
-	select {
-	case <-gaussdbConn.CleanupDone():
-	case <-time.After(5 * time.Second):
-		t.Fatal("Connection cleanup exceeded maximum time")
+	config.AfterConnect = func(ctx context.Context, conn *gaussdbconn.GaussdbConn) error {
+		_, err := conn.Exec(ctx, "set search_path to foobar;").ReadAll()
+		return err
 	}
+
+	conn, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	results, err := conn.Exec(ctx, "show search_path;").ReadAll()
+	require.NoError(t, err)
+	defer closeConn(t, conn)
+
+	assert.Equal(t, []byte("foobar"), results[0].Rows[0][0])
 }
 
-func TestConnExecContextPrecanceled(t *testing.T) {
+func TestConnectConfigRequiresConfigFromParseConfig(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
-
-	cancel()
-	_, err = gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
-	assert.Error(t, err)
-	assert.True(t, errors.Is(err, context.Canceled))
-	assert.True(t, gaussdbconn.SafeToRetry(err))
+	config := &gaussdbconn.Config{}
 
-	ensureConnValid(t, gaussdbConn)
+	require.PanicsWithValue(t, "config must be created by ParseConfig", func() { gaussdbconn.ConnectConfig(ctx, config) })
 }
 
-func TestConnExecParams(t *testing.T) {
+func TestConnPrepareSyntaxError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -980,24 +1196,14 @@ func TestConnExecParams(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	result := gaussdbConn.ExecParams(ctx, "select $1::text as msg", [][]byte{[]byte("Hello, world")}, nil, nil, nil)
-	require.Len(t, result.FieldDescriptions(), 1)
-	assert.Equal(t, "msg", result.FieldDescriptions()[0].Name)
-
-	rowCount := 0
-	for result.NextRow() {
-		rowCount += 1
-		assert.Equal(t, "Hello, world", string(result.Values()[0]))
-	}
-	assert.Equal(t, 1, rowCount)
-	commandTag, err := result.Close()
-	assert.Equal(t, "SELECT 1", commandTag.String())
-	assert.NoError(t, err)
+	psd, err := gaussdbConn.Prepare(ctx, "ps1", "SYNTAX ERROR", nil)
+	require.Nil(t, psd)
+	require.NotNil(t, err)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecParamsDeferredError(t *testing.T) {
+func TestConnPrepareContextPrecanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1007,27 +1213,18 @@ func TestConnExecParamsDeferredError(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	setupSQL := `create temporary table t (
-		id text primary key,
-		n int not null,
-		unique (n) deferrable initially deferred
-	);
-
-	insert into t (id, n) values ('a', 1), ('b', 2), ('c', 3);`
-
-	_, err = gaussdbConn.Exec(ctx, setupSQL).ReadAll()
-	assert.NoError(t, err)
+	cancel()
 
-	result := gaussdbConn.ExecParams(ctx, `update t set n=n+1 where id='b' returning *`, nil, nil, nil, nil).Read()
-	require.NotNil(t, result.Err)
-	var gaussdbErr *gaussdbconn.GaussdbError
-	require.True(t, errors.As(result.Err, &gaussdbErr))
-	require.Equal(t, "23505", gaussdbErr.Code)
+	psd, err := gaussdbConn.Prepare(ctx, "ps1", "select 1", nil)
+	assert.Nil(t, psd)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, gaussdbconn.SafeToRetry(err))
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecParamsMaxNumberOfParams(t *testing.T) {
+func TestConnDeallocate(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1037,23 +1234,25 @@ func TestConnExecParamsMaxNumberOfParams(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	paramCount := math.MaxUint16
-	params := make([]string, 0, paramCount)
-	args := make([][]byte, 0, paramCount)
-	for i := 0; i < paramCount; i++ {
-		params = append(params, fmt.Sprintf("($%d::text)", i+1))
-		args = append(args, []byte(strconv.Itoa(i)))
-	}
-	sql := "values" + strings.Join(params, ", ")
+	_, err = gaussdbConn.Prepare(ctx, "ps1", "select 1", nil)
+	require.NoError(t, err)
 
-	result := gaussdbConn.ExecParams(ctx, sql, args, nil, nil, nil).Read()
-	require.NoError(t, result.Err)
-	require.Len(t, result.Rows, paramCount)
+	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
+	require.NoError(t, err)
+
+	err = gaussdbConn.Deallocate(ctx, "ps1")
+	require.NoError(t, err)
+
+	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
+	require.Error(t, err)
+	var gaussdbError *gaussdbconn.GaussdbError
+	require.ErrorAs(t, err, &gaussdbError)
+	require.Equal(t, "26010", gaussdbError.Code)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecParamsTooManyParams(t *testing.T) {
+func TestConnDeallocateSucceedsInAbortedTransaction(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1063,23 +1262,36 @@ func TestConnExecParamsTooManyParams(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	paramCount := math.MaxUint16 + 1
-	params := make([]string, 0, paramCount)
-	args := make([][]byte, 0, paramCount)
-	for i := 0; i < paramCount; i++ {
-		params = append(params, fmt.Sprintf("($%d::text)", i+1))
-		args = append(args, []byte(strconv.Itoa(i)))
-	}
-	sql := "values" + strings.Join(params, ", ")
+	err = gaussdbConn.Exec(ctx, "begin").Close()
+	require.NoError(t, err)
 
-	result := gaussdbConn.ExecParams(ctx, sql, args, nil, nil, nil).Read()
-	require.Error(t, result.Err)
-	require.Equal(t, "extended protocol limited to 65535 parameters", result.Err.Error())
+	_, err = gaussdbConn.Prepare(ctx, "ps1", "select 1", nil)
+	require.NoError(t, err)
+
+	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
+	require.NoError(t, err)
+
+	err = gaussdbConn.Exec(ctx, "select 1/0").Close() // break transaction with divide by 0 error
+	require.Error(t, err)
+	var gaussdbError *gaussdbconn.GaussdbError
+	require.ErrorAs(t, err, &gaussdbError)
+	require.Equal(t, "22012", gaussdbError.Code)
+
+	err = gaussdbConn.Deallocate(ctx, "ps1")
+	require.NoError(t, err)
+
+	err = gaussdbConn.Exec(ctx, "rollback").Close()
+	require.NoError(t, err)
+
+	_, err = gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Close()
+	require.Error(t, err)
+	require.ErrorAs(t, err, &gaussdbError)
+	require.Equal(t, "26010", gaussdbError.Code)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecParamsCanceled(t *testing.T) {
+func TestConnDeallocateNonExistantStatementSucceeds(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1089,93 +1301,177 @@ func TestConnExecParamsCanceled(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
-	defer cancel()
-	result := gaussdbConn.ExecParams(ctx, "select current_database(), pg_sleep(1)", nil, nil, nil, nil)
-	rowCount := 0
-	for result.NextRow() {
-		rowCount += 1
-	}
-	assert.Equal(t, 0, rowCount)
-	commandTag, err := result.Close()
-	assert.Equal(t, gaussdbconn.CommandTag{}, commandTag)
-	assert.True(t, gaussdbconn.Timeout(err))
-	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	err = gaussdbConn.Deallocate(ctx, "ps1")
+	require.NoError(t, err)
 
-	assert.True(t, gaussdbConn.IsClosed())
-	select {
-	case <-gaussdbConn.CleanupDone():
-	case <-time.After(5 * time.Second):
-		t.Fatal("Connection cleanup exceeded maximum time")
-	}
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecParamsPrecanceled(t *testing.T) {
+func TestConnFunctionCall(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps,
+		gaussdbmock.ExpectMessage(&gaussdbproto.FunctionCall{
+			Function:         1234,
+			ArgFormatCodes:   []uint16{1, 0},
+			Arguments:        [][]byte{{0, 0, 0, 7}, nil},
+			ResultFormatCode: 1,
+		}),
+		gaussdbmock.SendMessage(&gaussdbproto.FunctionCallResponse{Result: []byte{0, 0, 0, 42}}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.FunctionCall{
+			Function:         1234,
+			ArgFormatCodes:   []uint16{},
+			Arguments:        [][]byte{},
+			ResultFormatCode: 0,
+		}),
+		gaussdbmock.SendMessage(&gaussdbproto.FunctionCallResponse{Result: nil}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.FunctionCall{
+			Function:         4321,
+			ArgFormatCodes:   []uint16{},
+			Arguments:        [][]byte{},
+			ResultFormatCode: 0,
+		}),
+		gaussdbmock.SendMessage(&gaussdbproto.ErrorResponse{Severity: "ERROR", Code: "42883", Message: "function 4321 does not exist"}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+	)
+	ln, serverErrChan := serveGaussdbMockScript(t, &gaussdbmock.Script{Steps: steps})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	gaussdbConn, err := gaussdbconn.Connect(ctx, fmt.Sprintf("sslmode=disable host=%s port=%s", host, port))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
 
-	cancel()
-	result := gaussdbConn.ExecParams(ctx, "select $1::text", [][]byte{[]byte("Hello, world")}, nil, nil, nil).Read()
-	require.Error(t, result.Err)
-	assert.True(t, errors.Is(result.Err, context.Canceled))
-	assert.True(t, gaussdbconn.SafeToRetry(result.Err))
+	result, err := gaussdbConn.FunctionCall(ctx, 1234, [][]byte{{0, 0, 0, 7}, nil}, []int16{1, 0}, 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 42}, result)
 
-	ensureConnValid(t, gaussdbConn)
+	result, err = gaussdbConn.FunctionCall(ctx, 1234, nil, nil, 0)
+	require.NoError(t, err)
+	require.Nil(t, result)
+
+	_, err = gaussdbConn.FunctionCall(ctx, 4321, nil, nil, 0)
+	var gaussdbErr *gaussdbconn.GaussdbError
+	require.ErrorAs(t, err, &gaussdbErr)
+	require.Equal(t, "42883", gaussdbErr.Code)
+
+	closeConn(t, gaussdbConn)
+	require.NoError(t, <-serverErrChan)
 }
 
-func TestConnExecParamsEmptySQL(t *testing.T) {
+func TestConnOnParameterStatus(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps,
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "set timezone = 'UTC'"}),
+		gaussdbmock.SendMessage(&gaussdbproto.ParameterStatus{Name: "TimeZone", Value: "UTC"}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SET")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+	)
+	ln, serverErrChan := serveGaussdbMockScript(t, &gaussdbmock.Script{Steps: steps})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	config, err := gaussdbconn.ParseConfig(fmt.Sprintf("sslmode=disable host=%s port=%s", host, port))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
 
-	result := gaussdbConn.ExecParams(ctx, "", nil, nil, nil, nil).Read()
-	assert.Equal(t, gaussdbconn.CommandTag{}, result.CommandTag)
-	assert.Len(t, result.Rows, 0)
-	assert.NoError(t, result.Err)
+	var statuses [][2]string
+	config.OnParameterStatus = func(gaussdbConn *gaussdbconn.GaussdbConn, name, value string) {
+		statuses = append(statuses, [2]string{name, value})
+	}
 
-	ensureConnValid(t, gaussdbConn)
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+
+	_, err = gaussdbConn.Exec(ctx, "set timezone = 'UTC'").ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][2]string{{"TimeZone", "UTC"}}, statuses)
+	require.Equal(t, "UTC", gaussdbConn.ParameterStatus("TimeZone"))
+	require.Equal(t, map[string]string{"TimeZone": "UTC"}, gaussdbConn.ParameterStatuses())
+
+	closeConn(t, gaussdbConn)
+	require.NoError(t, <-serverErrChan)
 }
 
-func TestResultReaderValuesHaveSameCapacityAsLength(t *testing.T) {
+func TestConnExecParamsFetch(t *testing.T) {
 	t.Parallel()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	rowDescription := &gaussdbproto.RowDescription{Fields: []gaussdbproto.FieldDescription{{Name: []byte("n"), DataTypeOID: 23, DataTypeSize: 4, TypeModifier: -1}}}
+
+	steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps,
+		gaussdbmock.ExpectMessage(&gaussdbproto.Parse{Query: "select generate_series(1,3)"}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Bind{DestinationPortal: "gaussdbconn_fetch", ResultFormatCodes: []int16{}}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Describe{ObjectType: 'P', Name: "gaussdbconn_fetch"}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Execute{Portal: "gaussdbconn_fetch", MaxRows: 2}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Flush{}),
+		gaussdbmock.SendMessage(&gaussdbproto.ParseComplete{}),
+		gaussdbmock.SendMessage(&gaussdbproto.BindComplete{}),
+		gaussdbmock.SendMessage(rowDescription),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("1")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("2")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.PortalSuspended{}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Execute{Portal: "gaussdbconn_fetch", MaxRows: 2}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Flush{}),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("3")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT 3")}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Close{ObjectType: 'P', Name: "gaussdbconn_fetch"}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Sync{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CloseComplete{}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+
+		// The rest of the portal is not fetched when the ResultReader is closed early.
+		gaussdbmock.ExpectMessage(&gaussdbproto.Parse{Query: "select generate_series(1,3)"}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Bind{DestinationPortal: "gaussdbconn_fetch", ResultFormatCodes: []int16{}}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Describe{ObjectType: 'P', Name: "gaussdbconn_fetch"}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Execute{Portal: "gaussdbconn_fetch", MaxRows: 2}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Flush{}),
+		gaussdbmock.SendMessage(&gaussdbproto.ParseComplete{}),
+		gaussdbmock.SendMessage(&gaussdbproto.BindComplete{}),
+		gaussdbmock.SendMessage(rowDescription),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("1")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("2")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.PortalSuspended{}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Close{ObjectType: 'P', Name: "gaussdbconn_fetch"}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Sync{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CloseComplete{}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+	)
+	ln, serverErrChan := serveGaussdbMockScript(t, &gaussdbmock.Script{Steps: steps})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	gaussdbConn, err := gaussdbconn.Connect(ctx, fmt.Sprintf("sslmode=disable host=%s port=%s", host, port))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
 
-	result := gaussdbConn.ExecParams(ctx, "select $1::text as msg", [][]byte{[]byte("Hello, world")}, nil, nil, nil)
-	require.Len(t, result.FieldDescriptions(), 1)
-	assert.Equal(t, "msg", result.FieldDescriptions()[0].Name)
+	result := gaussdbConn.ExecParamsFetch(ctx, "select generate_series(1,3)", nil, nil, nil, nil, 2).Read()
+	require.NoError(t, result.Err)
+	require.Equal(t, [][][]byte{{[]byte("1")}, {[]byte("2")}, {[]byte("3")}}, result.Rows)
+	require.Equal(t, "SELECT 3", result.CommandTag.String())
 
-	rowCount := 0
-	for result.NextRow() {
-		rowCount += 1
-		assert.Equal(t, "Hello, world", string(result.Values()[0]))
-		assert.Equal(t, len(result.Values()[0]), cap(result.Values()[0]))
-	}
-	assert.Equal(t, 1, rowCount)
-	commandTag, err := result.Close()
-	assert.Equal(t, "SELECT 1", commandTag.String())
-	assert.NoError(t, err)
+	rr := gaussdbConn.ExecParamsFetch(ctx, "select generate_series(1,3)", nil, nil, nil, nil, 2)
+	require.True(t, rr.NextRow())
+	require.Equal(t, [][]byte{[]byte("1")}, rr.Values())
+	_, err = rr.Close()
+	require.NoError(t, err)
 
-	ensureConnValid(t, gaussdbConn)
+	closeConn(t, gaussdbConn)
+	require.NoError(t, <-serverErrChan)
 }
 
-func TestResultReaderReadNil(t *testing.T) {
+func TestConnExec(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1185,14 +1481,19 @@ func TestResultReaderReadNil(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	result := gaussdbConn.ExecParams(ctx, "select null::text", nil, nil, nil, nil).Read()
-	require.NoError(t, result.Err)
-	require.Nil(t, result.Rows[0][0])
+	results, err := gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
+	assert.NoError(t, err)
+
+	assert.Len(t, results, 1)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
+	assert.Len(t, results[0].Rows, 1)
+	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecPrepared(t *testing.T) {
+func TestConnExecEmpty(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1202,30 +1503,21 @@ func TestConnExecPrepared(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	psd, err := gaussdbConn.Prepare(ctx, "ps1", "select $1::text as msg", nil)
-	require.NoError(t, err)
-	require.NotNil(t, psd)
-	assert.Len(t, psd.ParamOIDs, 1)
-	assert.Len(t, psd.Fields, 1)
-
-	result := gaussdbConn.ExecPrepared(ctx, "ps1", [][]byte{[]byte("Hello, world")}, nil, nil)
-	require.Len(t, result.FieldDescriptions(), 1)
-	assert.Equal(t, "msg", result.FieldDescriptions()[0].Name)
+	multiResult := gaussdbConn.Exec(ctx, ";")
 
-	rowCount := 0
-	for result.NextRow() {
-		rowCount += 1
-		assert.Equal(t, "Hello, world", string(result.Values()[0]))
+	resultCount := 0
+	for multiResult.NextResult() {
+		resultCount++
+		multiResult.ResultReader().Close()
 	}
-	assert.Equal(t, 1, rowCount)
-	commandTag, err := result.Close()
-	assert.Equal(t, "SELECT 1", commandTag.String())
+	assert.Equal(t, 0, resultCount)
+	err = multiResult.Close()
 	assert.NoError(t, err)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecPreparedMaxNumberOfParams(t *testing.T) {
+func TestConnExecMultipleQueries(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1235,29 +1527,25 @@ func TestConnExecPreparedMaxNumberOfParams(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	paramCount := math.MaxUint16
-	params := make([]string, 0, paramCount)
-	args := make([][]byte, 0, paramCount)
-	for i := 0; i < paramCount; i++ {
-		params = append(params, fmt.Sprintf("($%d::text)", i+1))
-		args = append(args, []byte(strconv.Itoa(i)))
-	}
-	sql := "values" + strings.Join(params, ", ")
+	results, err := gaussdbConn.Exec(ctx, "select 'Hello, world'; select 1").ReadAll()
+	assert.NoError(t, err)
 
-	psd, err := gaussdbConn.Prepare(ctx, "ps1", sql, nil)
-	require.NoError(t, err)
-	require.NotNil(t, psd)
-	assert.Len(t, psd.ParamOIDs, paramCount)
-	assert.Len(t, psd.Fields, 1)
+	assert.Len(t, results, 2)
 
-	result := gaussdbConn.ExecPrepared(ctx, "ps1", args, nil, nil).Read()
-	require.NoError(t, result.Err)
-	require.Len(t, result.Rows, paramCount)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
+	assert.Len(t, results[0].Rows, 1)
+	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+
+	assert.Nil(t, results[1].Err)
+	assert.Equal(t, "SELECT 1", results[1].CommandTag.String())
+	assert.Len(t, results[1].Rows, 1)
+	assert.Equal(t, "1", string(results[1].Rows[0][0]))
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecPreparedTooManyParams(t *testing.T) {
+func TestConnExecMultipleQueriesEagerFieldDescriptions(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1267,30 +1555,28 @@ func TestConnExecPreparedTooManyParams(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	paramCount := math.MaxUint16 + 1
-	params := make([]string, 0, paramCount)
-	args := make([][]byte, 0, paramCount)
-	for i := 0; i < paramCount; i++ {
-		params = append(params, fmt.Sprintf("($%d::text)", i+1))
-		args = append(args, []byte(strconv.Itoa(i)))
-	}
-	sql := "values" + strings.Join(params, ", ")
+	mrr := gaussdbConn.Exec(ctx, "select 'Hello, world' as msg; select 1 as num")
 
-	psd, err := gaussdbConn.Prepare(ctx, "ps1", sql, nil)
+	require.True(t, mrr.NextResult())
+	require.Len(t, mrr.ResultReader().FieldDescriptions(), 1)
+	assert.Equal(t, "msg", mrr.ResultReader().FieldDescriptions()[0].Name)
+	_, err = mrr.ResultReader().Close()
+	require.NoError(t, err)
 
-	// Gaussdb accepts preparing a statement with more than 65535 parameters and only fails when executing it through the extended protocol.
+	require.True(t, mrr.NextResult())
+	require.Len(t, mrr.ResultReader().FieldDescriptions(), 1)
+	assert.Equal(t, "num", mrr.ResultReader().FieldDescriptions()[0].Name)
+	_, err = mrr.ResultReader().Close()
 	require.NoError(t, err)
-	require.NotNil(t, psd)
-	assert.Len(t, psd.ParamOIDs, paramCount)
-	assert.Len(t, psd.Fields, 1)
 
-	result := gaussdbConn.ExecPrepared(ctx, "ps1", args, nil, nil).Read()
-	require.EqualError(t, result.Err, "extended protocol limited to 65535 parameters")
+	require.False(t, mrr.NextResult())
+
+	require.NoError(t, mrr.Close())
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecPreparedCanceled(t *testing.T) {
+func TestConnExecMultipleQueriesError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1300,29 +1586,22 @@ func TestConnExecPreparedCanceled(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Prepare(ctx, "ps1", "select current_database(), pg_sleep(1)", nil)
-	require.NoError(t, err)
-
-	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
-	defer cancel()
-	result := gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil)
-	rowCount := 0
-	for result.NextRow() {
-		rowCount += 1
-	}
-	assert.Equal(t, 0, rowCount)
-	commandTag, err := result.Close()
-	assert.Equal(t, gaussdbconn.CommandTag{}, commandTag)
-	assert.True(t, gaussdbconn.Timeout(err))
-	assert.True(t, gaussdbConn.IsClosed())
-	select {
-	case <-gaussdbConn.CleanupDone():
-	case <-time.After(5 * time.Second):
-		t.Fatal("Connection cleanup exceeded maximum time")
+	results, err := gaussdbConn.Exec(ctx, "select 1; select 1/0; select 1").ReadAll()
+	require.NotNil(t, err)
+	if gaussdbError, ok := err.(*gaussdbconn.GaussdbError); ok {
+		assert.Equal(t, "22012", gaussdbError.Code)
+	} else {
+		t.Errorf("unexpected error: %v", err)
 	}
+	// Gaussdb sends the divide by zero and never sends the second query result set.
+	require.Len(t, results, 1)
+	assert.Len(t, results[0].Rows, 1)
+	assert.Equal(t, "1", string(results[0].Rows[0][0]))
+
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecPreparedPrecanceled(t *testing.T) {
+func TestConnExecDeferredError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1332,19 +1611,28 @@ func TestConnExecPreparedPrecanceled(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Prepare(ctx, "ps1", "select current_database(), pg_sleep(1)", nil)
-	require.NoError(t, err)
+	setupSQL := `create temporary table t (
+		id text primary key,
+		n int not null,
+		unique (n) deferrable initially deferred
+	);
 
-	cancel()
-	result := gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Read()
-	require.Error(t, result.Err)
-	assert.True(t, errors.Is(result.Err, context.Canceled))
-	assert.True(t, gaussdbconn.SafeToRetry(result.Err))
+	insert into t (id, n) values ('a', 1), ('b', 2), ('c', 3);`
+
+	_, err = gaussdbConn.Exec(ctx, setupSQL).ReadAll()
+	assert.NoError(t, err)
+
+	_, err = gaussdbConn.Exec(ctx, `update t set n=n+1 where id='b' returning *`).ReadAll()
+	require.NotNil(t, err)
+
+	var gaussdbError *gaussdbconn.GaussdbError
+	require.True(t, errors.As(err, &gaussdbError))
+	require.Equal(t, "23505", gaussdbError.Code)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecPreparedEmptySQL(t *testing.T) {
+func TestConnExecContextCanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1353,19 +1641,26 @@ func TestConnExecPreparedEmptySQL(t *testing.T) {
 	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
+	cancel()
 
-	_, err = gaussdbConn.Prepare(ctx, "ps1", "", nil)
-	require.NoError(t, err)
-
-	result := gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Read()
-	assert.Equal(t, gaussdbconn.CommandTag{}, result.CommandTag)
-	assert.Len(t, result.Rows, 0)
-	assert.NoError(t, result.Err)
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	multiResult := gaussdbConn.Exec(ctx, "select 'Hello, world', pg_sleep(1)")
 
-	ensureConnValid(t, gaussdbConn)
+	for multiResult.NextResult() {
+	}
+	err = multiResult.Close()
+	assert.True(t, gaussdbconn.Timeout(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, gaussdbConn.IsClosed())
+	select {
+	case <-gaussdbConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
 }
 
-func TestConnExecBatch(t *testing.T) {
+func TestConnExecContextPrecanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1375,78 +1670,43 @@ func TestConnExecBatch(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Prepare(ctx, "ps1", "select $1::text", nil)
-	require.NoError(t, err)
-
-	batch := &gaussdbconn.Batch{}
-
-	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 1")}, nil, nil, nil)
-	batch.ExecPrepared("ps1", [][]byte{[]byte("ExecPrepared 1")}, nil, nil)
-	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 2")}, nil, nil, nil)
-	results, err := gaussdbConn.ExecBatch(ctx, batch).ReadAll()
-	require.NoError(t, err)
-	require.Len(t, results, 3)
-
-	require.Len(t, results[0].Rows, 1)
-	require.Equal(t, "ExecParams 1", string(results[0].Rows[0][0]))
-	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
-
-	require.Len(t, results[1].Rows, 1)
-	require.Equal(t, "ExecPrepared 1", string(results[1].Rows[0][0]))
-	assert.Equal(t, "SELECT 1", results[1].CommandTag.String())
-
-	require.Len(t, results[2].Rows, 1)
-	require.Equal(t, "ExecParams 2", string(results[2].Rows[0][0]))
-	assert.Equal(t, "SELECT 1", results[2].CommandTag.String())
-}
-
-type mockConnection struct {
-	net.Conn
-	writeLatency *time.Duration
-}
+	cancel()
+	_, err = gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, gaussdbconn.SafeToRetry(err))
 
-func (m mockConnection) Write(b []byte) (n int, err error) {
-	time.Sleep(*m.writeLatency)
-	return m.Conn.Write(b)
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecBatchWriteError(t *testing.T) {
+func TestConnExecParams(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-
-	var mockConn mockConnection
-	writeLatency := 0 * time.Second
-	config.DialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
-		conn, err := net.Dial(network, address)
-		mockConn = mockConnection{conn, &writeLatency}
-		return mockConn, err
-	}
-
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	batch := &gaussdbconn.Batch{}
-	gaussdbConn.Conn()
+	result := gaussdbConn.ExecParams(ctx, "select $1::text as msg", [][]byte{[]byte("Hello, world")}, nil, nil, nil)
+	require.Len(t, result.FieldDescriptions(), 1)
+	assert.Equal(t, "msg", result.FieldDescriptions()[0].Name)
 
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 1*time.Second)
-	defer cancel2()
+	rowCount := 0
+	for result.NextRow() {
+		rowCount += 1
+		assert.Equal(t, "Hello, world", string(result.Values()[0]))
+	}
+	assert.Equal(t, 1, rowCount)
+	commandTag, err := result.Close()
+	assert.Equal(t, "SELECT 1", commandTag.String())
+	assert.NoError(t, err)
 
-	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 1")}, nil, nil, nil)
-	writeLatency = 2 * time.Second
-	mrr := gaussdbConn.ExecBatch(ctx2, batch)
-	err = mrr.Close()
-	require.Error(t, err)
-	assert.ErrorIs(t, err, context.DeadlineExceeded)
-	require.True(t, gaussdbConn.IsClosed())
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecBatchDeferredError(t *testing.T) {
+func TestConnExecParamsDeferredError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1465,21 +1725,18 @@ func TestConnExecBatchDeferredError(t *testing.T) {
 	insert into t (id, n) values ('a', 1), ('b', 2), ('c', 3);`
 
 	_, err = gaussdbConn.Exec(ctx, setupSQL).ReadAll()
-	require.NoError(t, err)
-
-	batch := &gaussdbconn.Batch{}
+	assert.NoError(t, err)
 
-	batch.ExecParams(`update t set n=n+1 where id='b' returning *`, nil, nil, nil, nil)
-	_, err = gaussdbConn.ExecBatch(ctx, batch).ReadAll()
-	require.NotNil(t, err)
+	result := gaussdbConn.ExecParams(ctx, `update t set n=n+1 where id='b' returning *`, nil, nil, nil, nil).Read()
+	require.NotNil(t, result.Err)
 	var gaussdbErr *gaussdbconn.GaussdbError
-	require.True(t, errors.As(err, &gaussdbErr))
+	require.True(t, errors.As(result.Err, &gaussdbErr))
 	require.Equal(t, "23505", gaussdbErr.Code)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecBatchPrecanceled(t *testing.T) {
+func TestConnExecParamsMaxNumberOfParams(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1489,30 +1746,23 @@ func TestConnExecBatchPrecanceled(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Prepare(ctx, "ps1", "select $1::text", nil)
-	require.NoError(t, err)
-
-	batch := &gaussdbconn.Batch{}
-
-	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 1")}, nil, nil, nil)
-	batch.ExecPrepared("ps1", [][]byte{[]byte("ExecPrepared 1")}, nil, nil)
-	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 2")}, nil, nil, nil)
+	paramCount := math.MaxUint16
+	params := make([]string, 0, paramCount)
+	args := make([][]byte, 0, paramCount)
+	for i := 0; i < paramCount; i++ {
+		params = append(params, fmt.Sprintf("($%d::text)", i+1))
+		args = append(args, []byte(strconv.Itoa(i)))
+	}
+	sql := "values" + strings.Join(params, ", ")
 
-	cancel()
-	_, err = gaussdbConn.ExecBatch(ctx, batch).ReadAll()
-	require.Error(t, err)
-	assert.True(t, errors.Is(err, context.Canceled))
-	assert.True(t, gaussdbconn.SafeToRetry(err))
+	result := gaussdbConn.ExecParams(ctx, sql, args, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	require.Len(t, result.Rows, paramCount)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-// Without concurrent reading and writing large batches can deadlock.
-func TestConnExecBatchHuge(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping test in short mode.")
-	}
-
+func TestConnExecParamsTooManyParams(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1522,28 +1772,23 @@ func TestConnExecBatchHuge(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	batch := &gaussdbconn.Batch{}
-
-	queryCount := 100000
-	args := make([]string, queryCount)
-
-	for i := range args {
-		args[i] = strconv.Itoa(i)
-		batch.ExecParams("select $1::text", [][]byte{[]byte(args[i])}, nil, nil, nil)
+	paramCount := math.MaxUint16 + 1
+	params := make([]string, 0, paramCount)
+	args := make([][]byte, 0, paramCount)
+	for i := 0; i < paramCount; i++ {
+		params = append(params, fmt.Sprintf("($%d::text)", i+1))
+		args = append(args, []byte(strconv.Itoa(i)))
 	}
+	sql := "values" + strings.Join(params, ", ")
 
-	results, err := gaussdbConn.ExecBatch(ctx, batch).ReadAll()
-	require.NoError(t, err)
-	require.Len(t, results, queryCount)
+	result := gaussdbConn.ExecParams(ctx, sql, args, nil, nil, nil).Read()
+	require.Error(t, result.Err)
+	require.Equal(t, "extended protocol limited to 65535 parameters", result.Err.Error())
 
-	for i := range args {
-		require.Len(t, results[i].Rows, 1)
-		require.Equal(t, args[i], string(results[i].Rows[0][0]))
-		assert.Equal(t, "SELECT 1", results[i].CommandTag.String())
-	}
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnExecBatchImplicitTransaction(t *testing.T) {
+func TestConnExecParamsCanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1553,23 +1798,28 @@ func TestConnExecBatchImplicitTransaction(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, "create temporary table t(id int)").ReadAll()
-	require.NoError(t, err)
-
-	batch := &gaussdbconn.Batch{}
-
-	batch.ExecParams("insert into t(id) values(1)", nil, nil, nil, nil)
-	batch.ExecParams("insert into t(id) values(2)", nil, nil, nil, nil)
-	batch.ExecParams("insert into t(id) values(3)", nil, nil, nil, nil)
-	batch.ExecParams("select 1/0", nil, nil, nil, nil)
-	_, err = gaussdbConn.ExecBatch(ctx, batch).ReadAll()
-	require.Error(t, err)
+	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	result := gaussdbConn.ExecParams(ctx, "select current_database(), pg_sleep(1)", nil, nil, nil, nil)
+	rowCount := 0
+	for result.NextRow() {
+		rowCount += 1
+	}
+	assert.Equal(t, 0, rowCount)
+	commandTag, err := result.Close()
+	assert.Equal(t, gaussdbconn.CommandTag{}, commandTag)
+	assert.True(t, gaussdbconn.Timeout(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
 
-	result := gaussdbConn.ExecParams(ctx, "select count(*) from t", nil, nil, nil, nil).Read()
-	require.Equal(t, "0", string(result.Rows[0][0]))
+	assert.True(t, gaussdbConn.IsClosed())
+	select {
+	case <-gaussdbConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
 }
 
-func TestConnLocking(t *testing.T) {
+func TestConnExecParamsPrecanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1579,171 +1829,144 @@ func TestConnLocking(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	mrr := gaussdbConn.Exec(ctx, "select 'Hello, world'")
-	_, err = gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
-	assert.Error(t, err)
-	assert.Equal(t, "conn busy", err.Error())
-	assert.True(t, gaussdbconn.SafeToRetry(err))
-
-	results, err := mrr.ReadAll()
-	assert.NoError(t, err)
-	assert.Len(t, results, 1)
-	assert.Nil(t, results[0].Err)
-	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
-	assert.Len(t, results[0].Rows, 1)
-	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+	cancel()
+	result := gaussdbConn.ExecParams(ctx, "select $1::text", [][]byte{[]byte("Hello, world")}, nil, nil, nil).Read()
+	require.Error(t, result.Err)
+	assert.True(t, errors.Is(result.Err, context.Canceled))
+	assert.True(t, gaussdbconn.SafeToRetry(result.Err))
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnOnNotice(t *testing.T) {
+func TestConnExecParamsEmptySQL(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-
-	var notice *gaussdbconn.Notice
-	config.OnNotice = func(c *gaussdbconn.GaussdbConn, n *gaussdbconn.Notice) {
-		notice = n
-	}
-	config.RuntimeParams["client_min_messages"] = "notice" // Ensure we only get the message we expect.
-
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	multiResult := gaussdbConn.Exec(ctx, `do $$
-begin
-  raise notice 'hello, world';
-end$$;`)
-	err = multiResult.Close()
-	require.NoError(t, err)
-	// todo: gaussdb not return the 'V' field.This is identical to the 'S' field except that the contents are never localized.
-	//assert.Equal(t, "NOTICE", notice.SeverityUnlocalized)
-	assert.Equal(t, "NOTICE", notice.Severity)
-	assert.Equal(t, "hello, world", notice.Message)
+	result := gaussdbConn.ExecParams(ctx, "", nil, nil, nil, nil).Read()
+	assert.Equal(t, gaussdbconn.CommandTag{}, result.CommandTag)
+	assert.Len(t, result.Rows, 0)
+	assert.NoError(t, result.Err)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnOnNotification(t *testing.T) {
-	t.Skip("LISTEN statement is not yet supported in GaussDB")
+func TestResultReaderValuesHaveSameCapacityAsLength(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-
-	var msg string
-	config.OnNotification = func(c *gaussdbconn.GaussdbConn, n *gaussdbconn.Notification) {
-		msg = n.Payload
-	}
-
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, "listen foo").ReadAll()
-	require.NoError(t, err)
-
-	notifier, err := gaussdbconn.ConnectConfig(ctx, config)
-	require.NoError(t, err)
-	defer closeConn(t, notifier)
-	_, err = notifier.Exec(ctx, "notify foo, 'bar'").ReadAll()
-	require.NoError(t, err)
-
-	_, err = gaussdbConn.Exec(ctx, "select 1").ReadAll()
-	require.NoError(t, err)
+	result := gaussdbConn.ExecParams(ctx, "select $1::text as msg", [][]byte{[]byte("Hello, world")}, nil, nil, nil)
+	require.Len(t, result.FieldDescriptions(), 1)
+	assert.Equal(t, "msg", result.FieldDescriptions()[0].Name)
 
-	assert.Equal(t, "bar", msg)
+	rowCount := 0
+	for result.NextRow() {
+		rowCount += 1
+		assert.Equal(t, "Hello, world", string(result.Values()[0]))
+		assert.Equal(t, len(result.Values()[0]), cap(result.Values()[0]))
+	}
+	assert.Equal(t, 1, rowCount)
+	commandTag, err := result.Close()
+	assert.Equal(t, "SELECT 1", commandTag.String())
+	assert.NoError(t, err)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnWaitForNotification(t *testing.T) {
-	t.Skip("LISTEN statement is not yet supported in GaussDB")
+func TestResultReaderReadNil(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-
-	var msg string
-	config.OnNotification = func(c *gaussdbconn.GaussdbConn, n *gaussdbconn.Notification) {
-		msg = n.Payload
-	}
-
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, "listen foo").ReadAll()
-	require.NoError(t, err)
-
-	notifier, err := gaussdbconn.ConnectConfig(ctx, config)
-	require.NoError(t, err)
-	defer closeConn(t, notifier)
-	_, err = notifier.Exec(ctx, "notify foo, 'bar'").ReadAll()
-	require.NoError(t, err)
-
-	err = gaussdbConn.WaitForNotification(ctx)
-	require.NoError(t, err)
-
-	assert.Equal(t, "bar", msg)
+	result := gaussdbConn.ExecParams(ctx, "select null::text", nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	require.Nil(t, result.Rows[0][0])
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnWaitForNotificationPrecanceled(t *testing.T) {
+func TestConnExecPrepared(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	psd, err := gaussdbConn.Prepare(ctx, "ps1", "select $1::text as msg", nil)
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	require.NotNil(t, psd)
+	assert.Len(t, psd.ParamOIDs, 1)
+	assert.Len(t, psd.Fields, 1)
 
-	cancel()
-	err = gaussdbConn.WaitForNotification(ctx)
-	require.ErrorIs(t, err, context.Canceled)
+	result := gaussdbConn.ExecPrepared(ctx, "ps1", [][]byte{[]byte("Hello, world")}, nil, nil)
+	require.Len(t, result.FieldDescriptions(), 1)
+	assert.Equal(t, "msg", result.FieldDescriptions()[0].Name)
+
+	rowCount := 0
+	for result.NextRow() {
+		rowCount += 1
+		assert.Equal(t, "Hello, world", string(result.Values()[0]))
+	}
+	assert.Equal(t, 1, rowCount)
+	commandTag, err := result.Close()
+	assert.Equal(t, "SELECT 1", commandTag.String())
+	assert.NoError(t, err)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnWaitForNotificationTimeout(t *testing.T) {
+func TestConnExecPreparedMaxNumberOfParams(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	paramCount := math.MaxUint16
+	params := make([]string, 0, paramCount)
+	args := make([][]byte, 0, paramCount)
+	for i := 0; i < paramCount; i++ {
+		params = append(params, fmt.Sprintf("($%d::text)", i+1))
+		args = append(args, []byte(strconv.Itoa(i)))
+	}
+	sql := "values" + strings.Join(params, ", ")
+
+	psd, err := gaussdbConn.Prepare(ctx, "ps1", sql, nil)
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	require.NotNil(t, psd)
+	assert.Len(t, psd.ParamOIDs, paramCount)
+	assert.Len(t, psd.Fields, 1)
 
-	ctx, cancel = context.WithTimeout(ctx, 5*time.Millisecond)
-	err = gaussdbConn.WaitForNotification(ctx)
-	cancel()
-	assert.True(t, gaussdbconn.Timeout(err))
-	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	result := gaussdbConn.ExecPrepared(ctx, "ps1", args, nil, nil).Read()
+	require.NoError(t, result.Err)
+	require.Len(t, result.Rows, paramCount)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyToSmall(t *testing.T) {
+func TestConnExecPreparedTooManyParams(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1753,38 +1976,30 @@ func TestConnCopyToSmall(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int2,
-		b int4,
-		c int8,
-		d varchar,
-		e text,
-		f date,
-		g json
-	)`).ReadAll()
-	require.NoError(t, err)
-
-	_, err = gaussdbConn.Exec(ctx, `insert into foo values (0, 1, 2, 'abc', 'efg', '2000-01-01', '{"abc":"def","foo":"bar"}')`).ReadAll()
-	require.NoError(t, err)
-
-	_, err = gaussdbConn.Exec(ctx, `insert into foo values (null, null, null, null, null, null, null)`).ReadAll()
-	require.NoError(t, err)
-
-	inputBytes := []byte("0\t1\t2\tabc\tefg\t2000-01-01 00:00:00\t{\"abc\":\"def\",\"foo\":\"bar\"}\n" +
-		"\\N\t\\N\t\\N\t\\N\t\\N\t\\N\t\\N\n")
+	paramCount := math.MaxUint16 + 1
+	params := make([]string, 0, paramCount)
+	args := make([][]byte, 0, paramCount)
+	for i := 0; i < paramCount; i++ {
+		params = append(params, fmt.Sprintf("($%d::text)", i+1))
+		args = append(args, []byte(strconv.Itoa(i)))
+	}
+	sql := "values" + strings.Join(params, ", ")
 
-	outputWriter := bytes.NewBuffer(make([]byte, 0, len(inputBytes)))
+	psd, err := gaussdbConn.Prepare(ctx, "ps1", sql, nil)
 
-	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy foo to stdout")
+	// Gaussdb accepts preparing a statement with more than 65535 parameters and only fails when executing it through the extended protocol.
 	require.NoError(t, err)
+	require.NotNil(t, psd)
+	assert.Len(t, psd.ParamOIDs, paramCount)
+	assert.Len(t, psd.Fields, 1)
 
-	assert.Equal(t, int64(2), res.RowsAffected())
-	assert.Equal(t, inputBytes, outputWriter.Bytes())
+	result := gaussdbConn.ExecPrepared(ctx, "ps1", args, nil, nil).Read()
+	require.EqualError(t, result.Err, "extended protocol limited to 65535 parameters")
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyToLarge(t *testing.T) {
+func TestConnExecPreparedCanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1794,38 +2009,51 @@ func TestConnCopyToLarge(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int2,
-		b int4,
-		c int8,
-		d varchar,
-		e text,
-		f date,
-		g json,
-		h bytea
-	)`).ReadAll()
+	_, err = gaussdbConn.Prepare(ctx, "ps1", "select current_database(), pg_sleep(1)", nil)
 	require.NoError(t, err)
 
-	inputBytes := make([]byte, 0)
-
-	for i := 0; i < 1000; i++ {
-		_, err = gaussdbConn.Exec(ctx, `insert into foo values (0, 1, 2, 'abc', 'efg', '2000-01-01', '{"abc":"def","foo":"bar"}', 'oooo')`).ReadAll()
-		require.NoError(t, err)
-		inputBytes = append(inputBytes, "0\t1\t2\tabc\tefg\t2000-01-01 00:00:00\t{\"abc\":\"def\",\"foo\":\"bar\"}\t\\\\x6f6f6f6f\n"...)
+	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	result := gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil)
+	rowCount := 0
+	for result.NextRow() {
+		rowCount += 1
 	}
+	assert.Equal(t, 0, rowCount)
+	commandTag, err := result.Close()
+	assert.Equal(t, gaussdbconn.CommandTag{}, commandTag)
+	assert.True(t, gaussdbconn.Timeout(err))
+	assert.True(t, gaussdbConn.IsClosed())
+	select {
+	case <-gaussdbConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
+}
 
-	outputWriter := bytes.NewBuffer(make([]byte, 0, len(inputBytes)))
+func TestConnExecPreparedPrecanceled(t *testing.T) {
+	t.Parallel()
 
-	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy foo to stdout")
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	assert.Equal(t, int64(1000), res.RowsAffected())
-	assert.Equal(t, inputBytes, outputWriter.Bytes())
+	_, err = gaussdbConn.Prepare(ctx, "ps1", "select current_database(), pg_sleep(1)", nil)
+	require.NoError(t, err)
+
+	cancel()
+	result := gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Read()
+	require.Error(t, result.Err)
+	assert.True(t, errors.Is(result.Err, context.Canceled))
+	assert.True(t, gaussdbconn.SafeToRetry(result.Err))
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyToQueryError(t *testing.T) {
+func TestConnExecPreparedEmptySQL(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1835,17 +2063,18 @@ func TestConnCopyToQueryError(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	outputWriter := bytes.NewBuffer(make([]byte, 0))
+	_, err = gaussdbConn.Prepare(ctx, "ps1", "", nil)
+	require.NoError(t, err)
 
-	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "cropy foo to stdout")
-	require.Error(t, err)
-	assert.IsType(t, &gaussdbconn.GaussdbError{}, err)
-	assert.Equal(t, int64(0), res.RowsAffected())
+	result := gaussdbConn.ExecPrepared(ctx, "ps1", nil, nil, nil).Read()
+	assert.Equal(t, gaussdbconn.CommandTag{}, result.CommandTag)
+	assert.Len(t, result.Rows, 0)
+	assert.NoError(t, result.Err)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyToCanceled(t *testing.T) {
+func TestConnExecBatch(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1855,45 +2084,78 @@ func TestConnCopyToCanceled(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	outputWriter := &bytes.Buffer{}
+	_, err = gaussdbConn.Prepare(ctx, "ps1", "select $1::text", nil)
+	require.NoError(t, err)
 
-	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
-	defer cancel()
-	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy (select *, pg_sleep(0.01) from generate_series(1,1000)) to stdout")
-	assert.Error(t, err)
-	assert.Equal(t, gaussdbconn.CommandTag{}, res)
+	batch := &gaussdbconn.Batch{}
 
-	assert.True(t, gaussdbConn.IsClosed())
-	select {
-	case <-gaussdbConn.CleanupDone():
-	case <-time.After(5 * time.Second):
-		t.Fatal("Connection cleanup exceeded maximum time")
-	}
+	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 1")}, nil, nil, nil)
+	batch.ExecPrepared("ps1", [][]byte{[]byte("ExecPrepared 1")}, nil, nil)
+	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 2")}, nil, nil, nil)
+	results, err := gaussdbConn.ExecBatch(ctx, batch).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.Len(t, results[0].Rows, 1)
+	require.Equal(t, "ExecParams 1", string(results[0].Rows[0][0]))
+	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
+
+	require.Len(t, results[1].Rows, 1)
+	require.Equal(t, "ExecPrepared 1", string(results[1].Rows[0][0]))
+	assert.Equal(t, "SELECT 1", results[1].CommandTag.String())
+
+	require.Len(t, results[2].Rows, 1)
+	require.Equal(t, "ExecParams 2", string(results[2].Rows[0][0]))
+	assert.Equal(t, "SELECT 1", results[2].CommandTag.String())
 }
 
-func TestConnCopyToPrecanceled(t *testing.T) {
+type mockConnection struct {
+	net.Conn
+	writeLatency *time.Duration
+}
+
+func (m mockConnection) Write(b []byte) (n int, err error) {
+	time.Sleep(*m.writeLatency)
+	return m.Conn.Write(b)
+}
+
+func TestConnExecBatchWriteError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+
+	var mockConn mockConnection
+	writeLatency := 0 * time.Second
+	config.DialFunc = func(ctx context.Context, network, address string) (net.Conn, error) {
+		conn, err := net.Dial(network, address)
+		mockConn = mockConnection{conn, &writeLatency}
+		return mockConn, err
+	}
+
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	outputWriter := &bytes.Buffer{}
+	batch := &gaussdbconn.Batch{}
+	gaussdbConn.Conn()
 
-	cancel()
-	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy (select * from generate_series(1,1000)) to stdout")
-	require.Error(t, err)
-	assert.True(t, errors.Is(err, context.Canceled))
-	assert.True(t, gaussdbconn.SafeToRetry(err))
-	assert.Equal(t, gaussdbconn.CommandTag{}, res)
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel2()
 
-	ensureConnValid(t, gaussdbConn)
+	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 1")}, nil, nil, nil)
+	writeLatency = 2 * time.Second
+	mrr := gaussdbConn.ExecBatch(ctx2, batch)
+	err = mrr.Close()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, gaussdbConn.IsClosed())
 }
 
-func TestConnCopyFrom(t *testing.T) {
+func TestConnExecBatchDeferredError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1903,37 +2165,30 @@ func TestConnCopyFrom(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int4,
-		b varchar
-	)`).ReadAll()
-	require.NoError(t, err)
-
-	srcBuf := &bytes.Buffer{}
+	setupSQL := `create temporary table t (
+		id text primary key,
+		n int not null,
+		unique (n) deferrable initially deferred
+	);
 
-	inputRows := [][][]byte{}
-	for i := 0; i < 1000; i++ {
-		a := strconv.Itoa(i)
-		b := "foo " + a + " bar"
-		inputRows = append(inputRows, [][]byte{[]byte(a), []byte(b)})
-		_, err = srcBuf.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
-		require.NoError(t, err)
-	}
+	insert into t (id, n) values ('a', 1), ('b', 2), ('c', 3);`
 
-	copySql := "COPY foo FROM STDIN WITH (FORMAT csv)"
-	ct, err := gaussdbConn.CopyFrom(ctx, srcBuf, copySql)
+	_, err = gaussdbConn.Exec(ctx, setupSQL).ReadAll()
 	require.NoError(t, err)
-	assert.Equal(t, int64(len(inputRows)), ct.RowsAffected())
 
-	result := gaussdbConn.ExecParams(ctx, "select * from foo", nil, nil, nil, nil).Read()
-	require.NoError(t, result.Err)
+	batch := &gaussdbconn.Batch{}
 
-	assert.Equal(t, inputRows, result.Rows)
+	batch.ExecParams(`update t set n=n+1 where id='b' returning *`, nil, nil, nil, nil)
+	_, err = gaussdbConn.ExecBatch(ctx, batch).ReadAll()
+	require.NotNil(t, err)
+	var gaussdbErr *gaussdbconn.GaussdbError
+	require.True(t, errors.As(err, &gaussdbErr))
+	require.Equal(t, "23505", gaussdbErr.Code)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyFromBinary(t *testing.T) {
+func TestConnExecBatchPrecanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1943,53 +2198,61 @@ func TestConnCopyFromBinary(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int4,
-		b varchar
-	)`).ReadAll()
+	_, err = gaussdbConn.Prepare(ctx, "ps1", "select $1::text", nil)
 	require.NoError(t, err)
 
-	buf := []byte{}
-	buf = append(buf, "PGCOPY\n\377\r\n\000"...)
-	buf = gaussdbio.AppendInt32(buf, 0)
-	buf = gaussdbio.AppendInt32(buf, 0)
+	batch := &gaussdbconn.Batch{}
 
-	inputRows := [][][]byte{}
-	for i := 0; i < 1000; i++ {
-		// Number of elements in the tuple
-		buf = gaussdbio.AppendInt16(buf, int16(2))
-		a := i
+	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 1")}, nil, nil, nil)
+	batch.ExecPrepared("ps1", [][]byte{[]byte("ExecPrepared 1")}, nil, nil)
+	batch.ExecParams("select $1::text", [][]byte{[]byte("ExecParams 2")}, nil, nil, nil)
 
-		// Length of element for column `a int4`
-		buf = gaussdbio.AppendInt32(buf, 4)
-		buf, err = gaussdbtype.NewMap().Encode(gaussdbtype.Int4OID, gaussdbgo.BinaryFormatCode, a, buf)
-		require.NoError(t, err)
+	cancel()
+	_, err = gaussdbConn.ExecBatch(ctx, batch).ReadAll()
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, gaussdbconn.SafeToRetry(err))
 
-		b := "foo " + strconv.Itoa(a) + " bar"
-		lenB := int32(len([]byte(b)))
-		// Length of element for column `b varchar`
-		buf = gaussdbio.AppendInt32(buf, lenB)
-		buf, err = gaussdbtype.NewMap().Encode(gaussdbtype.VarcharOID, gaussdbgo.BinaryFormatCode, b, buf)
-		require.NoError(t, err)
+	ensureConnValid(t, gaussdbConn)
+}
 
-		inputRows = append(inputRows, [][]byte{[]byte(strconv.Itoa(a)), []byte(b)})
+// Without concurrent reading and writing large batches can deadlock.
+func TestConnExecBatchHuge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping test in short mode.")
 	}
 
-	srcBuf := &bytes.Buffer{}
-	srcBuf.Write(buf)
-	ct, err := gaussdbConn.CopyFrom(ctx, srcBuf, "COPY foo (a, b) FROM STDIN BINARY;")
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	assert.Equal(t, int64(len(inputRows)), ct.RowsAffected())
+	defer closeConn(t, gaussdbConn)
 
-	result := gaussdbConn.ExecParams(ctx, "select * from foo", nil, nil, nil, nil).Read()
-	require.NoError(t, result.Err)
+	batch := &gaussdbconn.Batch{}
 
-	assert.Equal(t, inputRows, result.Rows)
+	queryCount := 100000
+	args := make([]string, queryCount)
 
-	ensureConnValid(t, gaussdbConn)
+	for i := range args {
+		args[i] = strconv.Itoa(i)
+		batch.ExecParams("select $1::text", [][]byte{[]byte(args[i])}, nil, nil, nil)
+	}
+
+	results, err := gaussdbConn.ExecBatch(ctx, batch).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, results, queryCount)
+
+	for i := range args {
+		require.Len(t, results[i].Rows, 1)
+		require.Equal(t, args[i], string(results[i].Rows[0][0]))
+		assert.Equal(t, "SELECT 1", results[i].CommandTag.String())
+	}
 }
 
-func TestConnCopyFromCanceled(t *testing.T) {
+func TestConnExecBatchImplicitTransaction(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -1999,41 +2262,23 @@ func TestConnCopyFromCanceled(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int4,
-		b varchar
-	)`).ReadAll()
+	_, err = gaussdbConn.Exec(ctx, "create temporary table t(id int)").ReadAll()
 	require.NoError(t, err)
 
-	r, w := io.Pipe()
-	go func() {
-		for i := 0; i < 1000000; i++ {
-			a := strconv.Itoa(i)
-			b := "foo " + a + " bar"
-			_, err := w.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
-			if err != nil {
-				return
-			}
-			time.Sleep(time.Microsecond)
-		}
-	}()
+	batch := &gaussdbconn.Batch{}
 
-	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
-	copySql := "COPY foo FROM STDIN WITH (FORMAT csv)"
-	ct, err := gaussdbConn.CopyFrom(ctx, r, copySql)
-	cancel()
-	assert.Equal(t, int64(0), ct.RowsAffected())
-	assert.Error(t, err)
+	batch.ExecParams("insert into t(id) values(1)", nil, nil, nil, nil)
+	batch.ExecParams("insert into t(id) values(2)", nil, nil, nil, nil)
+	batch.ExecParams("insert into t(id) values(3)", nil, nil, nil, nil)
+	batch.ExecParams("select 1/0", nil, nil, nil, nil)
+	_, err = gaussdbConn.ExecBatch(ctx, batch).ReadAll()
+	require.Error(t, err)
 
-	assert.True(t, gaussdbConn.IsClosed())
-	select {
-	case <-gaussdbConn.CleanupDone():
-	case <-time.After(5 * time.Second):
-		t.Fatal("Connection cleanup exceeded maximum time")
-	}
+	result := gaussdbConn.ExecParams(ctx, "select count(*) from t", nil, nil, nil, nil).Read()
+	require.Equal(t, "0", string(result.Rows[0][0]))
 }
 
-func TestConnCopyFromPrecanceled(t *testing.T) {
+func TestConnLocking(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2043,150 +2288,171 @@ func TestConnCopyFromPrecanceled(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int4,
-		b varchar
-	)`).ReadAll()
-	require.NoError(t, err)
-
-	r, w := io.Pipe()
-	go func() {
-		for i := 0; i < 1000000; i++ {
-			a := strconv.Itoa(i)
-			b := "foo " + a + " bar"
-			_, err := w.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
-			if err != nil {
-				return
-			}
-			time.Sleep(time.Microsecond)
-		}
-	}()
-
-	ctx, cancel = context.WithCancel(ctx)
-	cancel()
-	ct, err := gaussdbConn.CopyFrom(ctx, r, "COPY foo FROM STDIN WITH (FORMAT csv)")
-	require.Error(t, err)
-	assert.True(t, errors.Is(err, context.Canceled))
+	mrr := gaussdbConn.Exec(ctx, "select 'Hello, world'")
+	_, err = gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
+	assert.Error(t, err)
+	assert.Equal(t, "conn busy", err.Error())
 	assert.True(t, gaussdbconn.SafeToRetry(err))
-	assert.Equal(t, gaussdbconn.CommandTag{}, ct)
+
+	results, err := mrr.ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
+	assert.Len(t, results[0].Rows, 1)
+	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyFromGzipReader(t *testing.T) {
+func TestConnOnNotice(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int4,
-		b varchar
-	)`).ReadAll()
+	var notice *gaussdbconn.Notice
+	config.OnNotice = func(c *gaussdbconn.GaussdbConn, n *gaussdbconn.Notice) {
+		notice = n
+	}
+	config.RuntimeParams["client_min_messages"] = "notice" // Ensure we only get the message we expect.
+
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	f, err := os.CreateTemp(t.TempDir(), "*")
+	multiResult := gaussdbConn.Exec(ctx, `do $$
+begin
+  raise notice 'hello, world';
+end$$;`)
+	err = multiResult.Close()
 	require.NoError(t, err)
-	defer f.Close()
+	// todo: gaussdb not return the 'V' field.This is identical to the 'S' field except that the contents are never localized.
+	//assert.Equal(t, "NOTICE", notice.SeverityUnlocalized)
+	assert.Equal(t, "NOTICE", notice.Severity)
+	assert.Equal(t, "hello, world", notice.Message)
 
-	gw := gzip.NewWriter(f)
+	ensureConnValid(t, gaussdbConn)
+}
 
-	inputRows := [][][]byte{}
-	for i := 0; i < 1000; i++ {
-		a := strconv.Itoa(i)
-		b := "foo " + a + " bar"
-		inputRows = append(inputRows, [][]byte{[]byte(a), []byte(b)})
-		_, err = gw.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
-		require.NoError(t, err)
-	}
+func TestConnOnNotification(t *testing.T) {
+	t.Skip("LISTEN statement is not yet supported in GaussDB")
+	t.Parallel()
 
-	err = gw.Close()
-	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
 
-	_, err = f.Seek(0, 0)
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 
-	gr, err := gzip.NewReader(f)
+	var msg string
+	config.OnNotification = func(c *gaussdbconn.GaussdbConn, n *gaussdbconn.Notification) {
+		msg = n.Payload
+	}
+
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	copySql := "COPY foo FROM STDIN WITH (FORMAT csv)"
-	ct, err := gaussdbConn.CopyFrom(ctx, gr, copySql)
+	_, err = gaussdbConn.Exec(ctx, "listen foo").ReadAll()
 	require.NoError(t, err)
-	assert.Equal(t, int64(len(inputRows)), ct.RowsAffected())
 
-	err = gr.Close()
+	notifier, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, notifier)
+	_, err = notifier.Exec(ctx, "notify foo, 'bar'").ReadAll()
 	require.NoError(t, err)
 
-	result := gaussdbConn.ExecParams(ctx, "select * from foo", nil, nil, nil, nil).Read()
-	require.NoError(t, result.Err)
+	_, err = gaussdbConn.Exec(ctx, "select 1").ReadAll()
+	require.NoError(t, err)
 
-	assert.Equal(t, inputRows, result.Rows)
+	assert.Equal(t, "bar", msg)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyFromQuerySyntaxError(t *testing.T) {
+func TestConnWaitForNotification(t *testing.T) {
+	t.Skip("LISTEN statement is not yet supported in GaussDB")
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+
+	var msg string
+	config.OnNotification = func(c *gaussdbconn.GaussdbConn, n *gaussdbconn.Notification) {
+		msg = n.Payload
+	}
+
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
-		a int4,
-		b varchar
-	)`).ReadAll()
+	_, err = gaussdbConn.Exec(ctx, "listen foo").ReadAll()
 	require.NoError(t, err)
 
-	srcBuf := &bytes.Buffer{}
+	notifier, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, notifier)
+	_, err = notifier.Exec(ctx, "notify foo, 'bar'").ReadAll()
+	require.NoError(t, err)
 
-	// Send data even though the COPY FROM command will be rejected with a syntax error. This ensures that this does not
-	// break the connection.
-	inputRows := [][][]byte{}
-	for i := 0; i < 1000; i++ {
-		a := strconv.Itoa(i)
-		b := "foo " + a + " bar"
-		inputRows = append(inputRows, [][]byte{[]byte(a), []byte(b)})
-		_, err = srcBuf.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
-		require.NoError(t, err)
-	}
+	err = gaussdbConn.WaitForNotification(ctx)
+	require.NoError(t, err)
 
-	res, err := gaussdbConn.CopyFrom(ctx, srcBuf, "cropy foo FROM STDIN WITH (FORMAT csv)")
-	require.Error(t, err)
-	assert.IsType(t, &gaussdbconn.GaussdbError{}, err)
-	assert.Equal(t, int64(0), res.RowsAffected())
+	assert.Equal(t, "bar", msg)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyFromQueryNoTableError(t *testing.T) {
+func TestConnWaitForNotificationPrecanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	srcBuf := &bytes.Buffer{}
+	cancel()
+	err = gaussdbConn.WaitForNotification(ctx)
+	require.ErrorIs(t, err, context.Canceled)
 
-	res, err := gaussdbConn.CopyFrom(ctx, srcBuf, "copy foo to stdout")
-	require.Error(t, err)
-	assert.IsType(t, &gaussdbconn.GaussdbError{}, err)
-	assert.Equal(t, int64(0), res.RowsAffected())
+	ensureConnValid(t, gaussdbConn)
+}
+
+func TestConnWaitForNotificationTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	ctx, cancel = context.WithTimeout(ctx, 5*time.Millisecond)
+	err = gaussdbConn.WaitForNotification(ctx)
+	cancel()
+	assert.True(t, gaussdbconn.Timeout(err))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyFromNoticeResponseReceivedMidStream(t *testing.T) {
+func TestConnCopyToSmall(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2195,115 +2461,80 @@ func TestConnCopyFromNoticeResponseReceivedMidStream(t *testing.T) {
 	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
-	// todo: gaussdb can't use temporary table here, complaints: Only support CREATE TRIGGER on regular row table. (SQLSTATE 0A000)
-	_, err = gaussdbConn.Exec(ctx, `
-	drop table if exists sentences;
-	create table sentences(
-		t text,
-		ts tsvector
+
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int2,
+		b int4,
+		c int8,
+		d varchar,
+		e text,
+		f date,
+		g json
 	)`).ReadAll()
 	require.NoError(t, err)
 
-	_, err = gaussdbConn.Exec(ctx, `create function pg_temp.sentences_trigger() returns trigger as $$
-	begin
-	  new.ts := to_tsvector(new.t);
-		return new;
-	end
-	$$ language plpgsql;`).ReadAll()
+	_, err = gaussdbConn.Exec(ctx, `insert into foo values (0, 1, 2, 'abc', 'efg', '2000-01-01', '{"abc":"def","foo":"bar"}')`).ReadAll()
 	require.NoError(t, err)
 
-	_, err = gaussdbConn.Exec(ctx, `create trigger sentences_update before insert on sentences for each row execute procedure pg_temp.sentences_trigger();`).ReadAll()
+	_, err = gaussdbConn.Exec(ctx, `insert into foo values (null, null, null, null, null, null, null)`).ReadAll()
 	require.NoError(t, err)
 
-	longString := make([]byte, 10001)
-	for i := range longString {
-		longString[i] = 'x'
-	}
+	inputBytes := []byte("0\t1\t2\tabc\tefg\t2000-01-01 00:00:00\t{\"abc\":\"def\",\"foo\":\"bar\"}\n" +
+		"\\N\t\\N\t\\N\t\\N\t\\N\t\\N\t\\N\n")
 
-	buf := &bytes.Buffer{}
-	for i := 0; i < 1000; i++ {
-		buf.Write([]byte(fmt.Sprintf("%s\n", string(longString))))
-	}
+	outputWriter := bytes.NewBuffer(make([]byte, 0, len(inputBytes)))
 
-	_, err = gaussdbConn.CopyFrom(ctx, buf, "COPY sentences(t) FROM STDIN WITH (FORMAT csv)")
+	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy foo to stdout")
 	require.NoError(t, err)
-}
 
-type delayedReader struct {
-	r io.Reader
-}
+	assert.Equal(t, int64(2), res.RowsAffected())
+	assert.Equal(t, inputBytes, outputWriter.Bytes())
 
-func (d delayedReader) Read(p []byte) (int, error) {
-	// W/o sleep test passes, with sleep it fails.
-	time.Sleep(time.Millisecond)
-	return d.r.Read(p)
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCopyFromDataWriteAfterErrorAndReturn(t *testing.T) {
+func TestConnCopyToLarge(t *testing.T) {
+	t.Parallel()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	connString := os.Getenv(gaussdbgo.EnvGaussdbTestDatabase)
-	if connString == "" {
-		t.Skipf("Skipping due to missing environment variable %v", gaussdbgo.EnvGaussdbTestDatabase)
-	}
-
-	config, err := gaussdbconn.ParseConfig(connString)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int2,
+		b int4,
+		c int8,
+		d varchar,
+		e text,
+		f date,
+		g json,
+		h bytea
+	)`).ReadAll()
 	require.NoError(t, err)
 
-	setupSQL := `create temporary table t (
-		id text primary key,
-		n int not null
-	);`
-
-	_, err = gaussdbConn.Exec(ctx, setupSQL).ReadAll()
-	assert.NoError(t, err)
-
-	r1 := delayedReader{r: strings.NewReader(`id	0\n`)}
-	// Generate an error with a bogus COPY command
-	_, err = gaussdbConn.CopyFrom(ctx, r1, "COPY nosuchtable FROM STDIN ")
-	assert.Error(t, err)
-
-	r2 := delayedReader{r: strings.NewReader(`id	0\n`)}
-	_, err = gaussdbConn.CopyFrom(ctx, r2, "COPY t FROM STDIN")
-	assert.NoError(t, err)
-}
+	inputBytes := make([]byte, 0)
 
-func TestConnEscapeString(t *testing.T) {
-	t.Parallel()
+	for i := 0; i < 1000; i++ {
+		_, err = gaussdbConn.Exec(ctx, `insert into foo values (0, 1, 2, 'abc', 'efg', '2000-01-01', '{"abc":"def","foo":"bar"}', 'oooo')`).ReadAll()
+		require.NoError(t, err)
+		inputBytes = append(inputBytes, "0\t1\t2\tabc\tefg\t2000-01-01 00:00:00\t{\"abc\":\"def\",\"foo\":\"bar\"}\t\\\\x6f6f6f6f\n"...)
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	outputWriter := bytes.NewBuffer(make([]byte, 0, len(inputBytes)))
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy foo to stdout")
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
-
-	tests := []struct {
-		in  string
-		out string
-	}{
-		{in: "", out: ""},
-		{in: "42", out: "42"},
-		{in: "'", out: "''"},
-		{in: "hi'there", out: "hi''there"},
-		{in: "'hi there'", out: "''hi there''"},
-	}
 
-	for i, tt := range tests {
-		value, err := gaussdbConn.EscapeString(tt.in)
-		if assert.NoErrorf(t, err, "%d.", i) {
-			assert.Equalf(t, tt.out, value, "%d.", i)
-		}
-	}
+	assert.Equal(t, int64(1000), res.RowsAffected())
+	assert.Equal(t, inputBytes, outputWriter.Bytes())
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCancelRequest(t *testing.T) {
+func TestConnCopyToQueryError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2313,135 +2544,105 @@ func TestConnCancelRequest(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	multiResult := gaussdbConn.Exec(ctx, "select 'Hello, world', pg_sleep(25)")
-
-	errChan := make(chan error)
-	go func() {
-		// The query is actually sent when multiResult.NextResult() is called. So wait to ensure it is sent.
-		// Once Flush is available this could use that instead.
-		time.Sleep(1 * time.Second)
-
-		err := gaussdbConn.CancelRequest(ctx)
-		errChan <- err
-	}()
-
-	for multiResult.NextResult() {
-	}
-	err = multiResult.Close()
-
-	require.IsType(t, &gaussdbconn.GaussdbError{}, err)
-	require.Equal(t, "57014", err.(*gaussdbconn.GaussdbError).Code)
+	outputWriter := bytes.NewBuffer(make([]byte, 0))
 
-	err = <-errChan
-	require.NoError(t, err)
+	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "cropy foo to stdout")
+	require.Error(t, err)
+	assert.IsType(t, &gaussdbconn.GaussdbError{}, err)
+	assert.Equal(t, int64(0), res.RowsAffected())
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnContextCanceledCancelsRunningQueryOnServer(t *testing.T) {
+func TestConnCopyToCanceled(t *testing.T) {
 	t.Parallel()
 
-	t.Run("postgres", func(t *testing.T) {
-		t.Parallel()
-
-		testConnContextCanceledCancelsRunningQueryOnServer(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase), "postgres")
-	})
-}
-
-func testConnContextCanceledCancelsRunningQueryOnServer(t *testing.T, connString, dbType string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, connString)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
+	outputWriter := &bytes.Buffer{}
+
 	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
 	defer cancel()
+	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy (select *, pg_sleep(0.01) from generate_series(1,1000)) to stdout")
+	assert.Error(t, err)
+	assert.Equal(t, gaussdbconn.CommandTag{}, res)
 
-	// Getting the actual GaussDB server process ID (PID) from a query executed through pgbouncer is not straightforward
-	// because pgbouncer abstracts the underlying database connections, and it doesn't expose the PID of the GaussDB
-	// server process to clients. However, we can check if the query is running by checking the generated query ID.
-	queryID := fmt.Sprintf("%s testConnContextCanceled %d", dbType, time.Now().UnixNano())
-
-	multiResult := gaussdbConn.Exec(ctx, fmt.Sprintf(`
-	-- %v
-	select 'Hello, world', pg_sleep(30)
-	`, queryID))
-
-	for multiResult.NextResult() {
-	}
-	err = multiResult.Close()
-	assert.True(t, gaussdbconn.Timeout(err))
 	assert.True(t, gaussdbConn.IsClosed())
 	select {
 	case <-gaussdbConn.CleanupDone():
 	case <-time.After(5 * time.Second):
 		t.Fatal("Connection cleanup exceeded maximum time")
 	}
+}
 
-	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+func TestConnCopyToPrecanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	otherConn, err := gaussdbconn.Connect(ctx, connString)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	defer closeConn(t, otherConn)
+	defer closeConn(t, gaussdbConn)
 
-	ctx, cancel = context.WithTimeout(ctx, time.Second*5)
-	defer cancel()
+	outputWriter := &bytes.Buffer{}
 
-	for {
-		result := otherConn.ExecParams(ctx,
-			`select 1 from pg_stat_activity where query like $1`,
-			[][]byte{[]byte("%" + queryID + "%")},
-			nil,
-			nil,
-			nil,
-		).Read()
-		require.NoError(t, result.Err)
+	cancel()
+	res, err := gaussdbConn.CopyTo(ctx, outputWriter, "copy (select * from generate_series(1,1000)) to stdout")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, gaussdbconn.SafeToRetry(err))
+	assert.Equal(t, gaussdbconn.CommandTag{}, res)
 
-		if len(result.Rows) == 0 {
-			break
-		}
-	}
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestHijackAndConstruct(t *testing.T) {
+func TestConnCopyFrom(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	origConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	err = origConn.SyncConn(ctx)
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int4,
+		b varchar
+	)`).ReadAll()
 	require.NoError(t, err)
 
-	hc, err := origConn.Hijack()
-	require.NoError(t, err)
+	srcBuf := &bytes.Buffer{}
 
-	_, err = origConn.Exec(ctx, "select 'Hello, world'").ReadAll()
-	require.Error(t, err)
+	inputRows := [][][]byte{}
+	for i := 0; i < 1000; i++ {
+		a := strconv.Itoa(i)
+		b := "foo " + a + " bar"
+		inputRows = append(inputRows, [][]byte{[]byte(a), []byte(b)})
+		_, err = srcBuf.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
+		require.NoError(t, err)
+	}
 
-	newConn, err := gaussdbconn.Construct(hc)
+	copySql := "COPY foo FROM STDIN WITH (FORMAT csv)"
+	ct, err := gaussdbConn.CopyFrom(ctx, srcBuf, copySql)
 	require.NoError(t, err)
+	assert.Equal(t, int64(len(inputRows)), ct.RowsAffected())
 
-	defer closeConn(t, newConn)
-
-	results, err := newConn.Exec(ctx, "select 'Hello, world'").ReadAll()
-	assert.NoError(t, err)
+	result := gaussdbConn.ExecParams(ctx, "select * from foo", nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
 
-	assert.Len(t, results, 1)
-	assert.Nil(t, results[0].Err)
-	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
-	assert.Len(t, results[0].Rows, 1)
-	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+	assert.Equal(t, inputRows, result.Rows)
 
-	ensureConnValid(t, newConn)
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCloseWhileCancellableQueryInProgress(t *testing.T) {
+func TestConnCopyFromBinary(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2449,84 +2650,99 @@ func TestConnCloseWhileCancellableQueryInProgress(t *testing.T) {
 
 	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	gaussdbConn.Exec(ctx, "select n from generate_series(1,10) n")
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int4,
+		b varchar
+	)`).ReadAll()
+	require.NoError(t, err)
 
-	closeCtx, _ := context.WithCancel(ctx)
-	gaussdbConn.Close(closeCtx)
-	select {
-	case <-gaussdbConn.CleanupDone():
-	case <-time.After(5 * time.Second):
-		t.Fatal("Connection cleanup exceeded maximum time")
-	}
-}
+	buf := []byte{}
+	buf = append(buf, "PGCOPY\n\377\r\n\000"...)
+	buf = gaussdbio.AppendInt32(buf, 0)
+	buf = gaussdbio.AppendInt32(buf, 0)
 
-func TestFatalErrorReceivedAfterCommandComplete(t *testing.T) {
-	t.Parallel()
+	inputRows := [][][]byte{}
+	for i := 0; i < 1000; i++ {
+		// Number of elements in the tuple
+		buf = gaussdbio.AppendInt16(buf, int16(2))
+		a := i
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+		// Length of element for column `a int4`
+		buf = gaussdbio.AppendInt32(buf, 4)
+		buf, err = gaussdbtype.NewMap().Encode(gaussdbtype.Int4OID, gaussdbgo.BinaryFormatCode, a, buf)
+		require.NoError(t, err)
 
-	steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
-	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Parse{}))
-	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Bind{}))
-	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Describe{}))
-	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Execute{}))
-	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Sync{}))
-	steps = append(steps, gaussdbmock.SendMessage(&gaussdbproto.RowDescription{Fields: []gaussdbproto.FieldDescription{
-		{Name: []byte("mock")},
-	}}))
-	steps = append(steps, gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT 0")}))
-	steps = append(steps, gaussdbmock.SendMessage(&gaussdbproto.ErrorResponse{Severity: "FATAL", Code: "57P01"}))
+		b := "foo " + strconv.Itoa(a) + " bar"
+		lenB := int32(len([]byte(b)))
+		// Length of element for column `b varchar`
+		buf = gaussdbio.AppendInt32(buf, lenB)
+		buf, err = gaussdbtype.NewMap().Encode(gaussdbtype.VarcharOID, gaussdbgo.BinaryFormatCode, b, buf)
+		require.NoError(t, err)
 
-	script := &gaussdbmock.Script{Steps: steps}
+		inputRows = append(inputRows, [][]byte{[]byte(strconv.Itoa(a)), []byte(b)})
+	}
 
-	ln, err := net.Listen("tcp", "127.0.0.1:")
+	srcBuf := &bytes.Buffer{}
+	srcBuf.Write(buf)
+	ct, err := gaussdbConn.CopyFrom(ctx, srcBuf, "COPY foo (a, b) FROM STDIN BINARY;")
 	require.NoError(t, err)
-	defer ln.Close()
-
-	serverErrChan := make(chan error, 1)
-	go func() {
-		defer close(serverErrChan)
+	assert.Equal(t, int64(len(inputRows)), ct.RowsAffected())
 
-		conn, err := ln.Accept()
-		if err != nil {
-			serverErrChan <- err
-			return
-		}
-		defer conn.Close()
+	result := gaussdbConn.ExecParams(ctx, "select * from foo", nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
 
-		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
-		if err != nil {
-			serverErrChan <- err
-			return
-		}
+	assert.Equal(t, inputRows, result.Rows)
 
-		err = script.Run(gaussdbproto.NewBackend(conn, conn))
-		if err != nil {
-			serverErrChan <- err
-			return
-		}
-	}()
+	ensureConnValid(t, gaussdbConn)
+}
 
-	host, port, _ := strings.Cut(ln.Addr().String(), ":")
-	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+func TestConnCopyFromCanceled(t *testing.T) {
+	t.Parallel()
 
-	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
-	conn, err := gaussdbconn.Connect(ctx, connStr)
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	rr := conn.ExecParams(ctx, "mocked...", nil, nil, nil, nil)
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int4,
+		b varchar
+	)`).ReadAll()
+	require.NoError(t, err)
 
-	for rr.NextRow() {
-	}
+	r, w := io.Pipe()
+	go func() {
+		for i := 0; i < 1000000; i++ {
+			a := strconv.Itoa(i)
+			b := "foo " + a + " bar"
+			_, err := w.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
+			if err != nil {
+				return
+			}
+			time.Sleep(time.Microsecond)
+		}
+	}()
 
-	_, err = rr.Close()
-	require.Error(t, err)
+	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+	copySql := "COPY foo FROM STDIN WITH (FORMAT csv)"
+	ct, err := gaussdbConn.CopyFrom(ctx, r, copySql)
+	cancel()
+	assert.Equal(t, int64(0), ct.RowsAffected())
+	assert.Error(t, err)
+
+	assert.True(t, gaussdbConn.IsClosed())
+	select {
+	case <-gaussdbConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
 }
 
-func TestConnLargeResponseWhileWritingDoesNotDeadlock(t *testing.T) {
+func TestConnCopyFromPrecanceled(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2536,107 +2752,130 @@ func TestConnLargeResponseWhileWritingDoesNotDeadlock(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	_, err = gaussdbConn.Exec(ctx, "set client_min_messages = debug5").ReadAll()
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int4,
+		b varchar
+	)`).ReadAll()
 	require.NoError(t, err)
 
-	// The actual contents of this test aren't important. What's important is a large amount of data to be written and
-	// because of client_min_messages = debug5 the server will return a large amount of data.
-
-	paramCount := math.MaxUint16
-	params := make([]string, 0, paramCount)
-	args := make([][]byte, 0, paramCount)
-	for i := 0; i < paramCount; i++ {
-		params = append(params, fmt.Sprintf("($%d::text)", i+1))
-		args = append(args, []byte(strconv.Itoa(i)))
-	}
-	sql := "values" + strings.Join(params, ", ")
+	r, w := io.Pipe()
+	go func() {
+		for i := 0; i < 1000000; i++ {
+			a := strconv.Itoa(i)
+			b := "foo " + a + " bar"
+			_, err := w.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
+			if err != nil {
+				return
+			}
+			time.Sleep(time.Microsecond)
+		}
+	}()
 
-	result := gaussdbConn.ExecParams(ctx, sql, args, nil, nil, nil).Read()
-	require.NoError(t, result.Err)
-	require.Len(t, result.Rows, paramCount)
+	ctx, cancel = context.WithCancel(ctx)
+	cancel()
+	ct, err := gaussdbConn.CopyFrom(ctx, r, "COPY foo FROM STDIN WITH (FORMAT csv)")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, gaussdbconn.SafeToRetry(err))
+	assert.Equal(t, gaussdbconn.CommandTag{}, ct)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCheckConn(t *testing.T) {
-	t.Skip("GaussDB does not return the correct PID")
+func TestConnCopyFromGzipReader(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	// Intentionally using TCP connection for more predictable close behavior. (Not sure if Unix domain sockets would behave subtly different.)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
 
-	connString := os.Getenv(gaussdbgo.EnvGaussdbTestTcpConnString)
-	if connString == "" {
-		t.Skipf("Skipping due to missing environment variable %v", gaussdbgo.EnvGaussdbTestTcpConnString)
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int4,
+		b varchar
+	)`).ReadAll()
+	require.NoError(t, err)
+
+	f, err := os.CreateTemp(t.TempDir(), "*")
+	require.NoError(t, err)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+
+	inputRows := [][][]byte{}
+	for i := 0; i < 1000; i++ {
+		a := strconv.Itoa(i)
+		b := "foo " + a + " bar"
+		inputRows = append(inputRows, [][]byte{[]byte(a), []byte(b)})
+		_, err = gw.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
+		require.NoError(t, err)
 	}
 
-	c1, err := gaussdbconn.Connect(ctx, connString)
+	err = gw.Close()
 	require.NoError(t, err)
-	defer c1.Close(ctx)
 
-	err = c1.CheckConn()
+	_, err = f.Seek(0, 0)
 	require.NoError(t, err)
 
-	c2, err := gaussdbconn.Connect(ctx, connString)
+	gr, err := gzip.NewReader(f)
 	require.NoError(t, err)
-	defer c2.Close(ctx)
 
-	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
+	copySql := "COPY foo FROM STDIN WITH (FORMAT csv)"
+	ct, err := gaussdbConn.CopyFrom(ctx, gr, copySql)
 	require.NoError(t, err)
+	assert.Equal(t, int64(len(inputRows)), ct.RowsAffected())
 
-	// It may take a while for the server to kill the backend. Retry until the error is detected or the test context is
-	// canceled.
-	for err == nil && ctx.Err() == nil {
-		time.Sleep(50 * time.Millisecond)
-		err = c1.CheckConn()
-	}
-	require.Error(t, err)
+	err = gr.Close()
+	require.NoError(t, err)
+
+	result := gaussdbConn.ExecParams(ctx, "select * from foo", nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+
+	assert.Equal(t, inputRows, result.Rows)
+
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnPing(t *testing.T) {
+func TestConnCopyFromQuerySyntaxError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	// Intentionally using TCP connection for more predictable close behavior. (Not sure if Unix domain sockets would behave subtly different.)
-
-	connString := os.Getenv(gaussdbgo.EnvGaussdbTestTcpConnString)
-	if connString == "" {
-		t.Skipf("Skipping due to missing environment variable %v", gaussdbgo.EnvGaussdbTestTcpConnString)
-	}
-
-	c1, err := gaussdbconn.Connect(ctx, connString)
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	defer c1.Close(ctx)
+	defer closeConn(t, gaussdbConn)
 
-	err = c1.Exec(ctx, "set log_statement = 'all'").Close()
+	_, err = gaussdbConn.Exec(ctx, `create temporary table foo(
+		a int4,
+		b varchar
+	)`).ReadAll()
 	require.NoError(t, err)
 
-	err = c1.Ping(ctx)
-	require.NoError(t, err)
+	srcBuf := &bytes.Buffer{}
 
-	c2, err := gaussdbconn.Connect(ctx, connString)
-	require.NoError(t, err)
-	defer c2.Close(ctx)
+	// Send data even though the COPY FROM command will be rejected with a syntax error. This ensures that this does not
+	// break the connection.
+	inputRows := [][][]byte{}
+	for i := 0; i < 1000; i++ {
+		a := strconv.Itoa(i)
+		b := "foo " + a + " bar"
+		inputRows = append(inputRows, [][]byte{[]byte(a), []byte(b)})
+		_, err = srcBuf.Write([]byte(fmt.Sprintf("%s,\"%s\"\n", a, b)))
+		require.NoError(t, err)
+	}
 
-	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
-	require.NoError(t, err)
+	res, err := gaussdbConn.CopyFrom(ctx, srcBuf, "cropy foo FROM STDIN WITH (FORMAT csv)")
+	require.Error(t, err)
+	assert.IsType(t, &gaussdbconn.GaussdbError{}, err)
+	assert.Equal(t, int64(0), res.RowsAffected())
 
-	// Give a little time for the signal to actually kill the backend.
-	time.Sleep(500 * time.Millisecond)
-	//todo: gauss will reset
-	//omm=# select pg_terminate_backend(139855820748544);
-	//FATAL:  terminating connection due to administrator command
-	//FATAL:  terminating connection due to administrator command
-	//	The connection to the server was lost. Attempting reset: Succeeded.
-	//err = c1.Ping(ctx)
-	//require.Error(t, err)
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestPipelinePrepare(t *testing.T) {
+func TestConnCopyFromQueryNoTableError(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2646,117 +2885,134 @@ func TestPipelinePrepare(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	result := gaussdbConn.ExecParams(ctx, `create temporary table t (id text primary key)`, nil, nil, nil, nil).Read()
-	require.NoError(t, result.Err)
-
-	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendPrepare("selectInt", "select $1::bigint as a", nil)
-	pipeline.SendPrepare("selectText", "select $1::text as b", nil)
-	pipeline.SendPrepare("selectNoParams", "select 42 as c", nil)
-	pipeline.SendPrepare("insertNoResults", "insert into t (id) values ($1)", nil)
-	pipeline.SendPrepare("insertNoParamsOrResults", "insert into t (id) values ('foo')", nil)
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	srcBuf := &bytes.Buffer{}
 
-	results, err := pipeline.GetResults()
-	require.NoError(t, err)
-	sd, ok := results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "a", string(sd.Fields[0].Name))
-	require.Equal(t, []uint32{gaussdbtype.Int8OID}, sd.ParamOIDs)
+	res, err := gaussdbConn.CopyFrom(ctx, srcBuf, "copy foo to stdout")
+	require.Error(t, err)
+	assert.IsType(t, &gaussdbconn.GaussdbError{}, err)
+	assert.Equal(t, int64(0), res.RowsAffected())
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	sd, ok = results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "b", string(sd.Fields[0].Name))
-	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
+	ensureConnValid(t, gaussdbConn)
+}
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	sd, ok = results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "c", string(sd.Fields[0].Name))
-	require.Equal(t, []uint32{}, sd.ParamOIDs)
+func TestConnCopyFromNoticeResponseReceivedMidStream(t *testing.T) {
+	t.Parallel()
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	sd, ok = results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 0)
-	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
 
-	results, err = pipeline.GetResults()
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+	// todo: gaussdb can't use temporary table here, complaints: Only support CREATE TRIGGER on regular row table. (SQLSTATE 0A000)
+	_, err = gaussdbConn.Exec(ctx, `
+	drop table if exists sentences;
+	create table sentences(
+		t text,
+		ts tsvector
+	)`).ReadAll()
 	require.NoError(t, err)
-	sd, ok = results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 0)
-	require.Len(t, sd.ParamOIDs, 0)
 
-	results, err = pipeline.GetResults()
+	_, err = gaussdbConn.Exec(ctx, `create function pg_temp.sentences_trigger() returns trigger as $$
+	begin
+	  new.ts := to_tsvector(new.t);
+		return new;
+	end
+	$$ language plpgsql;`).ReadAll()
 	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
-	results, err = pipeline.GetResults()
+	_, err = gaussdbConn.Exec(ctx, `create trigger sentences_update before insert on sentences for each row execute procedure pg_temp.sentences_trigger();`).ReadAll()
 	require.NoError(t, err)
-	require.Nil(t, results)
 
-	err = pipeline.Close()
+	longString := make([]byte, 10001)
+	for i := range longString {
+		longString[i] = 'x'
+	}
+
+	buf := &bytes.Buffer{}
+	for i := 0; i < 1000; i++ {
+		buf.Write([]byte(fmt.Sprintf("%s\n", string(longString))))
+	}
+
+	_, err = gaussdbConn.CopyFrom(ctx, buf, "COPY sentences(t) FROM STDIN WITH (FORMAT csv)")
 	require.NoError(t, err)
+}
 
-	ensureConnValid(t, gaussdbConn)
+type delayedReader struct {
+	r io.Reader
 }
 
-func TestPipelinePrepareError(t *testing.T) {
-	t.Parallel()
+func (d delayedReader) Read(p []byte) (int, error) {
+	// W/o sleep test passes, with sleep it fails.
+	time.Sleep(time.Millisecond)
+	return d.r.Read(p)
+}
 
+func TestConnCopyFromDataWriteAfterErrorAndReturn(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	connString := os.Getenv(gaussdbgo.EnvGaussdbTestDatabase)
+	if connString == "" {
+		t.Skipf("Skipping due to missing environment variable %v", gaussdbgo.EnvGaussdbTestDatabase)
+	}
 
-	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendPrepare("selectInt", "select $1::bigint as a", nil)
-	pipeline.SendPrepare("selectError", "bad", nil)
-	pipeline.SendPrepare("selectText", "select $1::text as b", nil)
-	err = pipeline.Sync()
+	config, err := gaussdbconn.ParseConfig(connString)
 	require.NoError(t, err)
 
-	results, err := pipeline.GetResults()
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
 	require.NoError(t, err)
-	sd, ok := results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "a", string(sd.Fields[0].Name))
-	require.Equal(t, []uint32{gaussdbtype.Int8OID}, sd.ParamOIDs)
 
-	results, err = pipeline.GetResults()
-	var gaussdbErr *gaussdbconn.GaussdbError
-	require.ErrorAs(t, err, &gaussdbErr)
-	require.Nil(t, results)
+	setupSQL := `create temporary table t (
+		id text primary key,
+		n int not null
+	);`
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+	_, err = gaussdbConn.Exec(ctx, setupSQL).ReadAll()
+	assert.NoError(t, err)
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	require.Nil(t, results)
+	r1 := delayedReader{r: strings.NewReader(`id	0\n`)}
+	// Generate an error with a bogus COPY command
+	_, err = gaussdbConn.CopyFrom(ctx, r1, "COPY nosuchtable FROM STDIN ")
+	assert.Error(t, err)
 
-	err = pipeline.Close()
+	r2 := delayedReader{r: strings.NewReader(`id	0\n`)}
+	_, err = gaussdbConn.CopyFrom(ctx, r2, "COPY t FROM STDIN")
+	assert.NoError(t, err)
+}
+
+func TestConnEscapeString(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{in: "", out: ""},
+		{in: "42", out: "42"},
+		{in: "'", out: "''"},
+		{in: "hi'there", out: "hi''there"},
+		{in: "'hi there'", out: "''hi there''"},
+	}
+
+	for i, tt := range tests {
+		value, err := gaussdbConn.EscapeString(tt.in)
+		if assert.NoErrorf(t, err, "%d.", i) {
+			assert.Equalf(t, tt.out, value, "%d.", i)
+		}
+	}
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestPipelinePrepareAndDeallocate(t *testing.T) {
+func TestConnCancelRequest(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2766,133 +3022,135 @@ func TestPipelinePrepareAndDeallocate(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendPrepare("selectInt", "select $1::bigint as a", nil)
-	pipeline.SendDeallocate("selectInt")
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	multiResult := gaussdbConn.Exec(ctx, "select 'Hello, world', pg_sleep(25)")
 
-	results, err := pipeline.GetResults()
-	require.NoError(t, err)
-	sd, ok := results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "a", string(sd.Fields[0].Name))
-	require.Equal(t, []uint32{gaussdbtype.Int8OID}, sd.ParamOIDs)
+	errChan := make(chan error)
+	go func() {
+		// The query is actually sent when multiResult.NextResult() is called. So wait to ensure it is sent.
+		// Once Flush is available this could use that instead.
+		time.Sleep(1 * time.Second)
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.CloseComplete)
-	require.Truef(t, ok, "expected CloseComplete, got: %#v", results)
+		err := gaussdbConn.CancelRequest(ctx)
+		errChan <- err
+	}()
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+	for multiResult.NextResult() {
+	}
+	err = multiResult.Close()
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	require.Nil(t, results)
+	require.IsType(t, &gaussdbconn.GaussdbError{}, err)
+	require.Equal(t, "57014", err.(*gaussdbconn.GaussdbError).Code)
 
-	err = pipeline.Close()
+	err = <-errChan
 	require.NoError(t, err)
 
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestPipelineQuery(t *testing.T) {
+func TestConnContextCanceledCancelsRunningQueryOnServer(t *testing.T) {
 	t.Parallel()
 
+	t.Run("postgres", func(t *testing.T) {
+		t.Parallel()
+
+		testConnContextCanceledCancelsRunningQueryOnServer(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase), "postgres")
+	})
+}
+
+func testConnContextCanceledCancelsRunningQueryOnServer(t *testing.T, connString, dbType string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
 	defer cancel()
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	gaussdbConn, err := gaussdbconn.Connect(ctx, connString)
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
-	err = pipeline.Sync()
-	require.NoError(t, err)
-
-	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	ctx, cancel = context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
 
-	results, err := pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok := results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult := rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "1", string(readResult.Rows[0][0]))
+	// Getting the actual GaussDB server process ID (PID) from a query executed through pgbouncer is not straightforward
+	// because pgbouncer abstracts the underlying database connections, and it doesn't expose the PID of the GaussDB
+	// server process to clients. However, we can check if the query is running by checking the generated query ID.
+	queryID := fmt.Sprintf("%s testConnContextCanceled %d", dbType, time.Now().UnixNano())
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "2", string(readResult.Rows[0][0]))
+	multiResult := gaussdbConn.Exec(ctx, fmt.Sprintf(`
+	-- %v
+	select 'Hello, world', pg_sleep(30)
+	`, queryID))
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "3", string(readResult.Rows[0][0]))
+	for multiResult.NextResult() {
+	}
+	err = multiResult.Close()
+	assert.True(t, gaussdbconn.Timeout(err))
+	assert.True(t, gaussdbConn.IsClosed())
+	select {
+	case <-gaussdbConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	results, err = pipeline.GetResults()
+	otherConn, err := gaussdbconn.Connect(ctx, connString)
 	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "4", string(readResult.Rows[0][0]))
+	defer closeConn(t, otherConn)
 
-	results, err = pipeline.GetResults()
+	ctx, cancel = context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+
+	for {
+		result := otherConn.ExecParams(ctx,
+			`select 1 from pg_stat_activity where query like $1`,
+			[][]byte{[]byte("%" + queryID + "%")},
+			nil,
+			nil,
+			nil,
+		).Read()
+		require.NoError(t, result.Err)
+
+		if len(result.Rows) == 0 {
+			break
+		}
+	}
+}
+
+func TestHijackAndConstruct(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	origConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "5", string(readResult.Rows[0][0]))
 
-	results, err = pipeline.GetResults()
+	err = origConn.SyncConn(ctx)
 	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
-	results, err = pipeline.GetResults()
+	hc, err := origConn.Hijack()
 	require.NoError(t, err)
-	require.Nil(t, results)
 
-	err = pipeline.Close()
+	_, err = origConn.Exec(ctx, "select 'Hello, world'").ReadAll()
+	require.Error(t, err)
+
+	newConn, err := gaussdbconn.Construct(hc)
 	require.NoError(t, err)
 
-	ensureConnValid(t, gaussdbConn)
+	defer closeConn(t, newConn)
+
+	results, err := newConn.Exec(ctx, "select 'Hello, world'").ReadAll()
+	assert.NoError(t, err)
+
+	assert.Len(t, results, 1)
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, "SELECT 1", results[0].CommandTag.String())
+	assert.Len(t, results[0].Rows, 1)
+	assert.Equal(t, "Hello, world", string(results[0].Rows[0][0]))
+
+	ensureConnValid(t, newConn)
 }
 
-func TestPipelinePrepareQuery(t *testing.T) {
+func TestConnCloseWhileCancellableQueryInProgress(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2900,59 +3158,84 @@ func TestPipelinePrepareQuery(t *testing.T) {
 
 	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
 
-	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendPrepare("ps", "select $1::text as msg", nil)
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("hello")}, nil, nil)
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("goodbye")}, nil, nil)
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	gaussdbConn.Exec(ctx, "select n from generate_series(1,10) n")
 
-	results, err := pipeline.GetResults()
-	require.NoError(t, err)
-	sd, ok := results.(*gaussdbconn.StatementDescription)
-	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
-	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "msg", string(sd.Fields[0].Name))
-	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
+	closeCtx, _ := context.WithCancel(ctx)
+	gaussdbConn.Close(closeCtx)
+	select {
+	case <-gaussdbConn.CleanupDone():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connection cleanup exceeded maximum time")
+	}
+}
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok := results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult := rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "hello", string(readResult.Rows[0][0]))
+func TestFatalErrorReceivedAfterCommandComplete(t *testing.T) {
+	t.Parallel()
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "goodbye", string(readResult.Rows[0][0]))
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+	steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Parse{}))
+	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Bind{}))
+	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Describe{}))
+	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Execute{}))
+	steps = append(steps, gaussdbmock.ExpectAnyMessage(&gaussdbproto.Sync{}))
+	steps = append(steps, gaussdbmock.SendMessage(&gaussdbproto.RowDescription{Fields: []gaussdbproto.FieldDescription{
+		{Name: []byte("mock")},
+	}}))
+	steps = append(steps, gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT 0")}))
+	steps = append(steps, gaussdbmock.SendMessage(&gaussdbproto.ErrorResponse{Severity: "FATAL", Code: "57P01"}))
 
-	results, err = pipeline.GetResults()
+	script := &gaussdbmock.Script{Steps: steps}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
 	require.NoError(t, err)
-	require.Nil(t, results)
+	defer ln.Close()
 
-	err = pipeline.Close()
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(gaussdbproto.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s", host, port)
+
+	ctx, cancel = context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	conn, err := gaussdbconn.Connect(ctx, connStr)
 	require.NoError(t, err)
 
-	ensureConnValid(t, gaussdbConn)
+	rr := conn.ExecParams(ctx, "mocked...", nil, nil, nil, nil)
+
+	for rr.NextRow() {
+	}
+
+	_, err = rr.Close()
+	require.Error(t, err)
 }
 
-func TestPipelineQueryErrorBetweenSyncs(t *testing.T) {
+func TestConnLargeResponseWhileWritingDoesNotDeadlock(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -2962,104 +3245,107 @@ func TestPipelineQueryErrorBetweenSyncs(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
-	err = pipeline.Sync()
+	_, err = gaussdbConn.Exec(ctx, "set client_min_messages = debug5").ReadAll()
 	require.NoError(t, err)
 
-	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 1/(3-n) from generate_series(1,10) n`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	// The actual contents of this test aren't important. What's important is a large amount of data to be written and
+	// because of client_min_messages = debug5 the server will return a large amount of data.
 
-	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 6`, nil, nil, nil, nil)
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	paramCount := math.MaxUint16
+	params := make([]string, 0, paramCount)
+	args := make([][]byte, 0, paramCount)
+	for i := 0; i < paramCount; i++ {
+		params = append(params, fmt.Sprintf("($%d::text)", i+1))
+		args = append(args, []byte(strconv.Itoa(i)))
+	}
+	sql := "values" + strings.Join(params, ", ")
 
-	results, err := pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok := results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult := rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "1", string(readResult.Rows[0][0]))
+	result := gaussdbConn.ExecParams(ctx, sql, args, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
+	require.Len(t, result.Rows, paramCount)
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "2", string(readResult.Rows[0][0]))
+	ensureConnValid(t, gaussdbConn)
+}
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+func TestConnCheckConn(t *testing.T) {
+	t.Skip("GaussDB does not return the correct PID")
+	t.Parallel()
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "3", string(readResult.Rows[0][0]))
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
 
-	results, err = pipeline.GetResults()
+	// Intentionally using TCP connection for more predictable close behavior. (Not sure if Unix domain sockets would behave subtly different.)
+
+	connString := os.Getenv(gaussdbgo.EnvGaussdbTestTcpConnString)
+	if connString == "" {
+		t.Skipf("Skipping due to missing environment variable %v", gaussdbgo.EnvGaussdbTestTcpConnString)
+	}
+
+	c1, err := gaussdbconn.Connect(ctx, connString)
 	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	var gaussdbErr *gaussdbconn.GaussdbError
-	require.ErrorAs(t, readResult.Err, &gaussdbErr)
-	require.Equal(t, "22012", gaussdbErr.Code)
+	defer c1.Close(ctx)
 
-	results, err = pipeline.GetResults()
+	err = c1.CheckConn()
 	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
-	results, err = pipeline.GetResults()
+	c2, err := gaussdbconn.Connect(ctx, connString)
 	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "5", string(readResult.Rows[0][0]))
+	defer c2.Close(ctx)
 
-	results, err = pipeline.GetResults()
+	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
 	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "6", string(readResult.Rows[0][0]))
 
-	results, err = pipeline.GetResults()
+	// It may take a while for the server to kill the backend. Retry until the error is detected or the test context is
+	// canceled.
+	for err == nil && ctx.Err() == nil {
+		time.Sleep(50 * time.Millisecond)
+		err = c1.CheckConn()
+	}
+	require.Error(t, err)
+}
+
+func TestConnPing(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	// Intentionally using TCP connection for more predictable close behavior. (Not sure if Unix domain sockets would behave subtly different.)
+
+	connString := os.Getenv(gaussdbgo.EnvGaussdbTestTcpConnString)
+	if connString == "" {
+		t.Skipf("Skipping due to missing environment variable %v", gaussdbgo.EnvGaussdbTestTcpConnString)
+	}
+
+	c1, err := gaussdbconn.Connect(ctx, connString)
 	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.PipelineSync)
-	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+	defer c1.Close(ctx)
 
-	err = pipeline.Close()
+	err = c1.Exec(ctx, "set log_statement = 'all'").Close()
 	require.NoError(t, err)
 
-	ensureConnValid(t, gaussdbConn)
+	err = c1.Ping(ctx)
+	require.NoError(t, err)
+
+	c2, err := gaussdbconn.Connect(ctx, connString)
+	require.NoError(t, err)
+	defer c2.Close(ctx)
+
+	_, err = c2.Exec(ctx, fmt.Sprintf("select pg_terminate_backend(%d)", c1.PID())).ReadAll()
+	require.NoError(t, err)
+
+	// Give a little time for the signal to actually kill the backend.
+	time.Sleep(500 * time.Millisecond)
+	//todo: gauss will reset
+	//omm=# select pg_terminate_backend(139855820748544);
+	//FATAL:  terminating connection due to administrator command
+	//FATAL:  terminating connection due to administrator command
+	//	The connection to the server was lost. Attempting reset: Succeeded.
+	//err = c1.Ping(ctx)
+	//require.Error(t, err)
 }
 
-func TestPipelineFlushForSingleRequests(t *testing.T) {
+func TestPipelinePrepare(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -3069,11 +3355,16 @@ func TestPipelineFlushForSingleRequests(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	pipeline := gaussdbConn.StartPipeline(ctx)
+	result := gaussdbConn.ExecParams(ctx, `create temporary table t (id text primary key)`, nil, nil, nil, nil).Read()
+	require.NoError(t, result.Err)
 
-	pipeline.SendPrepare("ps", "select $1::text as msg", nil)
-	pipeline.SendFlushRequest()
-	err = pipeline.Flush()
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendPrepare("selectInt", "select $1::bigint as a", nil)
+	pipeline.SendPrepare("selectText", "select $1::text as b", nil)
+	pipeline.SendPrepare("selectNoParams", "select 42 as c", nil)
+	pipeline.SendPrepare("insertNoResults", "insert into t (id) values ($1)", nil)
+	pipeline.SendPrepare("insertNoParamsOrResults", "insert into t (id) values ('foo')", nil)
+	err = pipeline.Sync()
 	require.NoError(t, err)
 
 	results, err := pipeline.GetResults()
@@ -3081,68 +3372,84 @@ func TestPipelineFlushForSingleRequests(t *testing.T) {
 	sd, ok := results.(*gaussdbconn.StatementDescription)
 	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
 	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "msg", string(sd.Fields[0].Name))
-	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
+	require.Equal(t, "a", string(sd.Fields[0].Name))
+	require.Equal(t, []uint32{gaussdbtype.Int8OID}, sd.ParamOIDs)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	require.Nil(t, results)
-
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("hello")}, nil, nil)
-	pipeline.SendFlushRequest()
-	err = pipeline.Flush()
-	require.NoError(t, err)
+	sd, ok = results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 1)
+	require.Equal(t, "b", string(sd.Fields[0].Name))
+	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	rr, ok := results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult := rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "hello", string(readResult.Rows[0][0]))
+	sd, ok = results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 1)
+	require.Equal(t, "c", string(sd.Fields[0].Name))
+	require.Equal(t, []uint32{}, sd.ParamOIDs)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	require.Nil(t, results)
+	sd, ok = results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 0)
+	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
 
-	pipeline.SendDeallocate("ps")
-	pipeline.SendFlushRequest()
-	err = pipeline.Flush()
+	results, err = pipeline.GetResults()
 	require.NoError(t, err)
+	sd, ok = results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 0)
+	require.Len(t, sd.ParamOIDs, 0)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	_, ok = results.(*gaussdbconn.CloseComplete)
-	require.Truef(t, ok, "expected CloseComplete, got: %#v", results)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
 	require.Nil(t, results)
 
-	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
-	pipeline.SendFlushRequest()
-	err = pipeline.Flush()
+	err = pipeline.Close()
 	require.NoError(t, err)
 
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
-	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
-	require.NoError(t, readResult.Err)
-	require.Len(t, readResult.Rows, 1)
-	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "1", string(readResult.Rows[0][0]))
+	ensureConnValid(t, gaussdbConn)
+}
 
-	results, err = pipeline.GetResults()
+func TestPipelinePrepareError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
 	require.NoError(t, err)
-	require.Nil(t, results)
+	defer closeConn(t, gaussdbConn)
 
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendPrepare("selectInt", "select $1::bigint as a", nil)
+	pipeline.SendPrepare("selectError", "bad", nil)
+	pipeline.SendPrepare("selectText", "select $1::text as b", nil)
 	err = pipeline.Sync()
 	require.NoError(t, err)
 
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	sd, ok := results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 1)
+	require.Equal(t, "a", string(sd.Fields[0].Name))
+	require.Equal(t, []uint32{gaussdbtype.Int8OID}, sd.ParamOIDs)
+
+	results, err = pipeline.GetResults()
+	var gaussdbErr *gaussdbconn.GaussdbError
+	require.ErrorAs(t, err, &gaussdbErr)
+	require.Nil(t, results)
+
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
 	_, ok = results.(*gaussdbconn.PipelineSync)
@@ -3158,7 +3465,7 @@ func TestPipelineFlushForSingleRequests(t *testing.T) {
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestPipelineFlushForRequestSeries(t *testing.T) {
+func TestPipelinePrepareAndDeallocate(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -3169,7 +3476,8 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 	defer closeConn(t, gaussdbConn)
 
 	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendPrepare("ps", "select $1::bigint as num", nil)
+	pipeline.SendPrepare("selectInt", "select $1::bigint as a", nil)
+	pipeline.SendDeallocate("selectInt")
 	err = pipeline.Sync()
 	require.NoError(t, err)
 
@@ -3178,23 +3486,52 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 	sd, ok := results.(*gaussdbconn.StatementDescription)
 	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
 	require.Len(t, sd.Fields, 1)
-	require.Equal(t, "num", string(sd.Fields[0].Name))
+	require.Equal(t, "a", string(sd.Fields[0].Name))
 	require.Equal(t, []uint32{gaussdbtype.Int8OID}, sd.ParamOIDs)
 
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.CloseComplete)
+	require.Truef(t, ok, "expected CloseComplete, got: %#v", results)
+
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
 	_, ok = results.(*gaussdbconn.PipelineSync)
 	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("1")}, nil, nil)
-	pipeline.SendPipelineSync()
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("2")}, nil, nil)
-	pipeline.SendPipelineSync()
-	err = pipeline.Flush()
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
 
+	err = pipeline.Close()
 	require.NoError(t, err)
 
-	results, err = pipeline.GetResults()
+	ensureConnValid(t, gaussdbConn)
+}
+
+func TestPipelineQuery(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	results, err := pipeline.GetResults()
 	require.NoError(t, err)
 	rr, ok := results.(*gaussdbconn.ResultReader)
 	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
@@ -3203,8 +3540,6 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 	require.Len(t, readResult.Rows, 1)
 	require.Len(t, readResult.Rows[0], 1)
 	require.Equal(t, "1", string(readResult.Rows[0][0]))
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
@@ -3218,18 +3553,18 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("3")}, nil, nil)
-	pipeline.SendPipelineSync()
-	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "3", string(readResult.Rows[0][0]))
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("4")}, nil, nil)
-	pipeline.SendPipelineSync()
-	err = pipeline.Flush()
-	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
@@ -3239,9 +3574,7 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 	require.NoError(t, readResult.Err)
 	require.Len(t, readResult.Rows, 1)
 	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "3", string(readResult.Rows[0][0]))
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
+	require.Equal(t, "4", string(readResult.Rows[0][0]))
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
@@ -3251,34 +3584,57 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 	require.NoError(t, readResult.Err)
 	require.Len(t, readResult.Rows, 1)
 	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "4", string(readResult.Rows[0][0]))
+	require.Equal(t, "5", string(readResult.Rows[0][0]))
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("5")}, nil, nil)
-	pipeline.SendPipelineSync()
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
 	require.Nil(t, results)
 
-	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("6")}, nil, nil)
-	pipeline.SendPipelineSync()
-	err = pipeline.Flush()
+	err = pipeline.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, gaussdbConn)
+}
+
+func TestPipelinePrepareQuery(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendPrepare("ps", "select $1::text as msg", nil)
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("hello")}, nil, nil)
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("goodbye")}, nil, nil)
+	err = pipeline.Sync()
 	require.NoError(t, err)
 
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	sd, ok := results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 1)
+	require.Equal(t, "msg", string(sd.Fields[0].Name))
+	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
+
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	rr, ok = results.(*gaussdbconn.ResultReader)
+	rr, ok := results.(*gaussdbconn.ResultReader)
 	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult = rr.Read()
+	readResult := rr.Read()
 	require.NoError(t, readResult.Err)
 	require.Len(t, readResult.Rows, 1)
 	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "5", string(readResult.Rows[0][0]))
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
+	require.Equal(t, "hello", string(readResult.Rows[0][0]))
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
@@ -3288,13 +3644,7 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 	require.NoError(t, readResult.Err)
 	require.Len(t, readResult.Rows, 1)
 	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "6", string(readResult.Rows[0][0]))
-
-	results, err = pipeline.GetResults()
-	require.NoError(t, err)
-
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	require.Equal(t, "goodbye", string(readResult.Rows[0][0]))
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
@@ -3311,7 +3661,7 @@ func TestPipelineFlushForRequestSeries(t *testing.T) {
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestPipelineFlushWithError(t *testing.T) {
+func TestPipelineQueryErrorBetweenSyncs(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -3323,10 +3673,19 @@ func TestPipelineFlushWithError(t *testing.T) {
 
 	pipeline := gaussdbConn.StartPipeline(ctx)
 	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 1/(3-n) from generate_series(1,10) n`, nil, nil, nil, nil)
 	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
-	pipeline.SendFlushRequest()
-	err = pipeline.Flush()
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 1/(3-n) from generate_series(1,10) n`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 6`, nil, nil, nil, nil)
+	err = pipeline.Sync()
 	require.NoError(t, err)
 
 	results, err := pipeline.GetResults()
@@ -3344,29 +3703,34 @@ func TestPipelineFlushWithError(t *testing.T) {
 	rr, ok = results.(*gaussdbconn.ResultReader)
 	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
 	readResult = rr.Read()
-	var gaussdbErr *gaussdbconn.GaussdbError
-	require.ErrorAs(t, readResult.Err, &gaussdbErr)
-	require.Equal(t, "22012", gaussdbErr.Code)
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "2", string(readResult.Rows[0][0]))
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	require.Nil(t, results)
-
-	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
-	pipeline.SendFlushRequest()
-	err = pipeline.Flush()
-	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	require.Nil(t, results)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "3", string(readResult.Rows[0][0]))
 
-	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
-	pipeline.SendPipelineSync()
-	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
-	pipeline.SendFlushRequest()
-	err = pipeline.Flush()
+	results, err = pipeline.GetResults()
 	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	var gaussdbErr *gaussdbconn.GaussdbError
+	require.ErrorAs(t, readResult.Err, &gaussdbErr)
+	require.Equal(t, "22012", gaussdbErr.Code)
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
@@ -3385,10 +3749,13 @@ func TestPipelineFlushWithError(t *testing.T) {
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	require.Nil(t, results)
-
-	err = pipeline.Sync()
-	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "6", string(readResult.Rows[0][0]))
 
 	results, err = pipeline.GetResults()
 	require.NoError(t, err)
@@ -3401,7 +3768,7 @@ func TestPipelineFlushWithError(t *testing.T) {
 	ensureConnValid(t, gaussdbConn)
 }
 
-func TestPipelineCloseReadsUnreadResults(t *testing.T) {
+func TestPipelineFlushForSingleRequests(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -3412,103 +3779,95 @@ func TestPipelineCloseReadsUnreadResults(t *testing.T) {
 	defer closeConn(t, gaussdbConn)
 
 	pipeline := gaussdbConn.StartPipeline(ctx)
-	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
-	err = pipeline.Sync()
-	require.NoError(t, err)
 
-	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
-	err = pipeline.Sync()
+	pipeline.SendPrepare("ps", "select $1::text as msg", nil)
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
 	require.NoError(t, err)
 
 	results, err := pipeline.GetResults()
 	require.NoError(t, err)
+	sd, ok := results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 1)
+	require.Equal(t, "msg", string(sd.Fields[0].Name))
+	require.Equal(t, []uint32{gaussdbtype.TextOID}, sd.ParamOIDs)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
+
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("hello")}, nil, nil)
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
 	rr, ok := results.(*gaussdbconn.ResultReader)
 	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
 	readResult := rr.Read()
 	require.NoError(t, readResult.Err)
 	require.Len(t, readResult.Rows, 1)
 	require.Len(t, readResult.Rows[0], 1)
-	require.Equal(t, "1", string(readResult.Rows[0][0]))
+	require.Equal(t, "hello", string(readResult.Rows[0][0]))
 
-	err = pipeline.Close()
+	results, err = pipeline.GetResults()
 	require.NoError(t, err)
+	require.Nil(t, results)
 
-	ensureConnValid(t, gaussdbConn)
-}
-
-func TestPipelineCloseDetectsUnsyncedRequests(t *testing.T) {
-	t.Parallel()
+	pipeline.SendDeallocate("ps")
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
+	require.NoError(t, err)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.CloseComplete)
+	require.Truef(t, ok, "expected CloseComplete, got: %#v", results)
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	require.Nil(t, results)
 
-	pipeline := gaussdbConn.StartPipeline(ctx)
 	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
-	err = pipeline.Sync()
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
 	require.NoError(t, err)
 
-	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
-	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
-
-	results, err := pipeline.GetResults()
+	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	rr, ok := results.(*gaussdbconn.ResultReader)
+	rr, ok = results.(*gaussdbconn.ResultReader)
 	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
-	readResult := rr.Read()
+	readResult = rr.Read()
 	require.NoError(t, readResult.Err)
 	require.Len(t, readResult.Rows, 1)
 	require.Len(t, readResult.Rows[0], 1)
 	require.Equal(t, "1", string(readResult.Rows[0][0]))
 
-	err = pipeline.Close()
-	require.EqualError(t, err, "pipeline has unsynced requests")
-}
-
-func TestConnOngaussdbError(t *testing.T) {
-	t.Parallel()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
 
-	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	err = pipeline.Sync()
 	require.NoError(t, err)
-	config.OnGaussdbError = func(c *gaussdbconn.GaussdbConn, gaussdbErr *gaussdbconn.GaussdbError) bool {
-		require.NotNil(t, c)
-		require.NotNil(t, gaussdbErr)
-		// close connection on undefined tables only
-		if gaussdbErr.Code == "42P01" {
-			return false
-		}
-		return true
-	}
 
-	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	results, err = pipeline.GetResults()
 	require.NoError(t, err)
-	defer closeConn(t, gaussdbConn)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
 
-	_, err = gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
-	assert.NoError(t, err)
-	assert.False(t, gaussdbConn.IsClosed())
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
 
-	_, err = gaussdbConn.Exec(ctx, "select 1/0").ReadAll()
-	assert.Error(t, err)
-	assert.False(t, gaussdbConn.IsClosed())
+	err = pipeline.Close()
+	require.NoError(t, err)
 
-	_, err = gaussdbConn.Exec(ctx, "select * from non_existant_table").ReadAll()
-	assert.Error(t, err)
-	assert.True(t, gaussdbConn.IsClosed())
+	ensureConnValid(t, gaussdbConn)
 }
 
-func TestConnCustomData(t *testing.T) {
+func TestPipelineFlushForRequestSeries(t *testing.T) {
 	t.Parallel()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
@@ -3518,139 +3877,1066 @@ func TestConnCustomData(t *testing.T) {
 	require.NoError(t, err)
 	defer closeConn(t, gaussdbConn)
 
-	gaussdbConn.CustomData()["foo"] = "bar"
-	assert.Equal(t, "bar", gaussdbConn.CustomData()["foo"])
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendPrepare("ps", "select $1::bigint as num", nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	sd, ok := results.(*gaussdbconn.StatementDescription)
+	require.Truef(t, ok, "expected StatementDescription, got: %#v", results)
+	require.Len(t, sd.Fields, 1)
+	require.Equal(t, "num", string(sd.Fields[0].Name))
+	require.Equal(t, []uint32{gaussdbtype.Int8OID}, sd.ParamOIDs)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("1")}, nil, nil)
+	pipeline.SendPipelineSync()
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("2")}, nil, nil)
+	pipeline.SendPipelineSync()
+	err = pipeline.Flush()
+
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok := results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult := rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "1", string(readResult.Rows[0][0]))
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "2", string(readResult.Rows[0][0]))
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("3")}, nil, nil)
+	pipeline.SendPipelineSync()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("4")}, nil, nil)
+	pipeline.SendPipelineSync()
+	err = pipeline.Flush()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "3", string(readResult.Rows[0][0]))
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "4", string(readResult.Rows[0][0]))
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("5")}, nil, nil)
+	pipeline.SendPipelineSync()
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
+
+	pipeline.SendQueryPrepared(`ps`, [][]byte{[]byte("6")}, nil, nil)
+	pipeline.SendPipelineSync()
+	err = pipeline.Flush()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "5", string(readResult.Rows[0][0]))
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "6", string(readResult.Rows[0][0]))
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
+
+	err = pipeline.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, gaussdbConn)
+}
+
+func TestPipelineFlushWithError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 1/(3-n) from generate_series(1,10) n`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
+	require.NoError(t, err)
+
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok := results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult := rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "1", string(readResult.Rows[0][0]))
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	var gaussdbErr *gaussdbconn.GaussdbError
+	require.ErrorAs(t, readResult.Err, &gaussdbErr)
+	require.Equal(t, "22012", gaussdbErr.Code)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
+
+	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
+
+	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
+	pipeline.SendPipelineSync()
+	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
+	pipeline.SendFlushRequest()
+	err = pipeline.Flush()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok = results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult = rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "5", string(readResult.Rows[0][0]))
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	require.Nil(t, results)
+
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	results, err = pipeline.GetResults()
+	require.NoError(t, err)
+	_, ok = results.(*gaussdbconn.PipelineSync)
+	require.Truef(t, ok, "expected PipelineSync, got: %#v", results)
+
+	err = pipeline.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, gaussdbConn)
+}
+
+func TestPipelineCloseReadsUnreadResults(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok := results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult := rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "1", string(readResult.Rows[0][0]))
+
+	err = pipeline.Close()
+	require.NoError(t, err)
+
+	ensureConnValid(t, gaussdbConn)
+}
+
+func TestPipelineCloseDetectsUnsyncedRequests(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	pipeline := gaussdbConn.StartPipeline(ctx)
+	pipeline.SendQueryParams(`select 1`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 2`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 3`, nil, nil, nil, nil)
+	err = pipeline.Sync()
+	require.NoError(t, err)
+
+	pipeline.SendQueryParams(`select 4`, nil, nil, nil, nil)
+	pipeline.SendQueryParams(`select 5`, nil, nil, nil, nil)
+
+	results, err := pipeline.GetResults()
+	require.NoError(t, err)
+	rr, ok := results.(*gaussdbconn.ResultReader)
+	require.Truef(t, ok, "expected ResultReader, got: %#v", results)
+	readResult := rr.Read()
+	require.NoError(t, readResult.Err)
+	require.Len(t, readResult.Rows, 1)
+	require.Len(t, readResult.Rows[0], 1)
+	require.Equal(t, "1", string(readResult.Rows[0][0]))
+
+	err = pipeline.Close()
+	require.EqualError(t, err, "pipeline has unsynced requests")
+}
+
+func TestConnOngaussdbError(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := gaussdbconn.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	config.OnGaussdbError = func(c *gaussdbconn.GaussdbConn, gaussdbErr *gaussdbconn.GaussdbError) bool {
+		require.NotNil(t, c)
+		require.NotNil(t, gaussdbErr)
+		// close connection on undefined tables only
+		if gaussdbErr.Code == "42P01" {
+			return false
+		}
+		return true
+	}
+
+	gaussdbConn, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	_, err = gaussdbConn.Exec(ctx, "select 'Hello, world'").ReadAll()
+	assert.NoError(t, err)
+	assert.False(t, gaussdbConn.IsClosed())
+
+	_, err = gaussdbConn.Exec(ctx, "select 1/0").ReadAll()
+	assert.Error(t, err)
+	assert.False(t, gaussdbConn.IsClosed())
+
+	_, err = gaussdbConn.Exec(ctx, "select * from non_existant_table").ReadAll()
+	assert.Error(t, err)
+	assert.True(t, gaussdbConn.IsClosed())
+}
+
+func TestConnCustomData(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer closeConn(t, gaussdbConn)
+
+	gaussdbConn.CustomData()["foo"] = "bar"
+	assert.Equal(t, "bar", gaussdbConn.CustomData()["foo"])
+
+	ensureConnValid(t, gaussdbConn)
+}
+
+func Example() {
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer gaussdbConn.Close(ctx)
+
+	result := gaussdbConn.ExecParams(ctx, "select generate_series(1,3)", nil, nil, nil, nil).Read()
+	if result.Err != nil {
+		log.Fatalln(result.Err)
+	}
+
+	for _, row := range result.Rows {
+		fmt.Println(string(row[0]))
+	}
+
+	fmt.Println(result.CommandTag)
+	// Output:
+	// 1
+	// 2
+	// 3
+	// SELECT 3
+}
+
+func GetSSLPassword(ctx context.Context) string {
+	connString := os.Getenv(gaussdbgo.EnvGaussdbSslPassword)
+	return connString
+}
+
+var rsaCertPEM = `-----BEGIN CERTIFICATE-----
+MIIDCTCCAfGgAwIBAgIUQDlN1g1bzxIJ8KWkayNcQY5gzMEwDQYJKoZIhvcNAQEL
+BQAwFDESMBAGA1UEAwwJbG9jYWxob3N0MB4XDTIyMDgxNTIxNDgyNloXDTIzMDgx
+NTIxNDgyNlowFDESMBAGA1UEAwwJbG9jYWxob3N0MIIBIjANBgkqhkiG9w0BAQEF
+AAOCAQ8AMIIBCgKCAQEA0vOppiT8zE+076acRORzD5JVbRYKMK3XlWLVrHua4+ct
+Rm54WyP+3XsYU4JGGGKgb8E+u2UosGJYcSM+b+U1/5XPTcpuumS+pCiD9WP++A39
+tsukYwR7m65cgpiI4dlLEZI3EWpAW+Bb3230KiYW4sAmQ0Ih4PrN+oPvzcs86F4d
+9Y03CqVUxRKLBLaClZQAg8qz2Pawwj1FKKjDX7u2fRVR0wgOugpCMOBJMcCgz9pp
+0HSa4x3KZDHEZY7Pah5XwWrCfAEfRWsSTGcNaoN8gSxGFM1JOEJa8SAuPGjFcYIv
+MmVWdw0FXCgYlSDL02fzLE0uyvXBDibzSqOk770JhQIDAQABo1MwUTAdBgNVHQ4E
+FgQUiJ8JLENJ+2k1Xl4o6y2Lc/qHTh0wHwYDVR0jBBgwFoAUiJ8JLENJ+2k1Xl4o
+6y2Lc/qHTh0wDwYDVR0TAQH/BAUwAwEB/zANBgkqhkiG9w0BAQsFAAOCAQEAwjn2
+gnNAhFvh58VqLIjU6ftvn6rhz5B9dg2+XyY8sskLhhkO1nL9339BVZsRt+eI3a7I
+81GNIm9qHVM3MUAcQv3SZy+0UPVUT8DNH2LwHT3CHnYTBP8U+8n8TDNGSTMUhIBB
+Rx+6KwODpwLdI79VGT3IkbU9bZwuepB9I9nM5t/tt5kS4gHmJFlO0aLJFCTO4Scf
+hp/WLPv4XQUH+I3cPfaJRxz2j0Kc8iOzMhFmvl1XOGByjX6X33LnOzY/LVeTSGyS
+VgC32BGtnMwuy5XZYgFAeUx9HKy4tG4OH2Ux6uPF/WAhsug6PXSjV7BK6wYT5i27
+MlascjupnaptKX/wMA==
+-----END CERTIFICATE-----
+`
+
+var rsaKeyPEM = testingKey(`-----BEGIN TESTING KEY-----
+MIIEvwIBADANBgkqhkiG9w0BAQEFAASCBKkwggSlAgEAAoIBAQDS86mmJPzMT7Tv
+ppxE5HMPklVtFgowrdeVYtWse5rj5y1GbnhbI/7dexhTgkYYYqBvwT67ZSiwYlhx
+Iz5v5TX/lc9Nym66ZL6kKIP1Y/74Df22y6RjBHubrlyCmIjh2UsRkjcRakBb4Fvf
+bfQqJhbiwCZDQiHg+s36g+/NyzzoXh31jTcKpVTFEosEtoKVlACDyrPY9rDCPUUo
+qMNfu7Z9FVHTCA66CkIw4EkxwKDP2mnQdJrjHcpkMcRljs9qHlfBasJ8AR9FaxJM
+Zw1qg3yBLEYUzUk4QlrxIC48aMVxgi8yZVZ3DQVcKBiVIMvTZ/MsTS7K9cEOJvNK
+o6TvvQmFAgMBAAECggEAKzTK54Ol33bn2TnnwdiElIjlRE2CUswYXrl6iDRc2hbs
+WAOiVRB/T/+5UMla7/2rXJhY7+rdNZs/ABU24ZYxxCJ77jPrD/Q4c8j0lhsgCtBa
+ycjV543wf0dsHTd+ubtWu8eVzdRUUD0YtB+CJevdPh4a+CWgaMMV0xyYzi61T+Yv
+Z7Uc3awIAiT4Kw9JRmJiTnyMJg5vZqW3BBAX4ZIvS/54ipwEU+9sWLcuH7WmCR0B
+QCTqS6hfJDLm//dGC89Iyno57zfYuiT3PYCWH5crr/DH3LqnwlNaOGSBkhkXuIL+
+QvOaUMe2i0pjqxDrkBx05V554vyy9jEvK7i330HL4QKBgQDUJmouEr0+o7EMBApC
+CPPu58K04qY5t9aGciG/pOurN42PF99yNZ1CnynH6DbcnzSl8rjc6Y65tzTlWods
+bjwVfcmcokG7sPcivJvVjrjKpSQhL8xdZwSAjcqjN4yoJ/+ghm9w+SRmZr6oCQZ3
+1jREfJKT+PGiWTEjYcExPWUD2QKBgQD+jdgq4c3tFavU8Hjnlf75xbStr5qu+fp2
+SGLRRbX+msQwVbl2ZM9AJLoX9MTCl7D9zaI3ONhheMmfJ77lDTa3VMFtr3NevGA6
+MxbiCEfRtQpNkJnsqCixLckx3bskj5+IF9BWzw7y7nOzdhoWVFv/+TltTm3RB51G
+McdlmmVjjQKBgQDSFAw2/YV6vtu2O1XxGC591/Bd8MaMBziev+wde3GHhaZfGVPC
+I8dLTpMwCwowpFKdNeLLl1gnHX161I+f1vUWjw4TVjVjaBUBx+VEr2Tb/nXtiwiD
+QV0a883CnGJjreAblKRMKdpasMmBWhaWmn39h6Iad3zHuCzJjaaiXNpn2QKBgQCf
+k1Q8LanmQnuh1c41f7aD5gjKCRezMUpt9BrejhD1NxheJJ9LNQ8nat6uPedLBcUS
+lmJms+AR2qKqf0QQWyQ98YgAtshgTz8TvQtPT1mWgSOgVFHqJdC8obNK63FyDgc4
+TZVxlgQNDqbBjfv0m5XA9f+mIlB9hYR2iKYzb4K30QKBgQC+LEJYZh00zsXttGHr
+5wU1RzbgDIEsNuu+nZ4MxsaCik8ILNRHNXdeQbnADKuo6ATfhdmDIQMVZLG8Mivi
+UwnwLd1GhizvqvLHa3ULnFphRyMGFxaLGV48axTT2ADoMX67ILrIY/yjycLqRZ3T
+z3w+CgS20UrbLIR1YXfqUXge1g==
+-----END TESTING KEY-----
+`)
+
+func testingKey(s string) string { return strings.ReplaceAll(s, "TESTING KEY", "PRIVATE KEY") }
+
+func TestSNISupport(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		sni_param string
+		sni_set   bool
+	}{
+		{
+			name:      "SNI is passed by default",
+			sni_param: "",
+			sni_set:   true,
+		},
+		{
+			name:      "SNI is passed when asked for",
+			sni_param: "sslsni=1",
+			sni_set:   true,
+		},
+		{
+			name:      "SNI is not passed when disabled",
+			sni_param: "sslsni=0",
+			sni_set:   false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+			defer cancel()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:")
+			require.NoError(t, err)
+			defer ln.Close()
+
+			serverErrChan := make(chan error, 1)
+			serverSNINameChan := make(chan string, 1)
+			defer close(serverErrChan)
+			defer close(serverSNINameChan)
+
+			go func() {
+				var sniHost string
+
+				conn, err := ln.Accept()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+				defer conn.Close()
+
+				err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+
+				backend := gaussdbproto.NewBackend(conn, conn)
+				startupMessage, err := backend.ReceiveStartupMessage()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+
+				switch startupMessage.(type) {
+				case *gaussdbproto.SSLRequest:
+					_, err = conn.Write([]byte("S"))
+					if err != nil {
+						serverErrChan <- err
+						return
+					}
+				default:
+					serverErrChan <- fmt.Errorf("unexpected startup message: %#v", startupMessage)
+					return
+				}
+
+				cert, err := tls.X509KeyPair([]byte(rsaCertPEM), []byte(rsaKeyPEM))
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+
+				srv := tls.Server(conn, &tls.Config{
+					Certificates: []tls.Certificate{cert},
+					GetConfigForClient: func(argHello *tls.ClientHelloInfo) (*tls.Config, error) {
+						sniHost = argHello.ServerName
+						return nil, nil
+					},
+				})
+				defer srv.Close()
+
+				if err := srv.Handshake(); err != nil {
+					serverErrChan <- fmt.Errorf("handshake: %w", err)
+					return
+				}
+
+				srv.Write(mustEncode((&gaussdbproto.AuthenticationOk{}).Encode(nil)))
+				srv.Write(mustEncode((&gaussdbproto.BackendKeyData{ProcessID: 0, SecretKey: 0}).Encode(nil)))
+				srv.Write(mustEncode((&gaussdbproto.ReadyForQuery{TxStatus: 'I'}).Encode(nil)))
+
+				serverSNINameChan <- sniHost
+			}()
+
+			_, port, _ := strings.Cut(ln.Addr().String(), ":")
+			connStr := fmt.Sprintf("sslmode=require host=localhost port=%s %s", port, tt.sni_param)
+			_, err = gaussdbconn.Connect(ctx, connStr)
+
+			select {
+			case sniHost := <-serverSNINameChan:
+				if tt.sni_set {
+					require.Equal(t, "localhost", sniHost)
+				} else {
+					require.Equal(t, "", sniHost)
+				}
+			case err = <-serverErrChan:
+				t.Fatalf("server failed with error: %+v", err)
+			case <-time.After(time.Millisecond * 100):
+				t.Fatal("exceeded connection timeout without erroring out")
+			}
+		})
+	}
+}
+
+// testPKI is a CA with a server certificate for localhost and a CRL revoking the server certificate.
+type testPKI struct {
+	caPEM         []byte
+	serverCert    tls.Certificate
+	emptyCRLPEM   []byte
+	revokedCRLPEM []byte
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serverTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	serverDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caCert, &serverKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	createCRL := func(number int64, revoked []x509.RevocationListEntry) []byte {
+		der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+			Number:                    big.NewInt(number),
+			ThisUpdate:                time.Now().Add(-time.Hour),
+			NextUpdate:                time.Now().Add(time.Hour),
+			RevokedCertificateEntries: revoked,
+		}, caCert, caKey)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der})
+	}
+
+	return &testPKI{
+		caPEM:         pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		serverCert:    tls.Certificate{Certificate: [][]byte{serverDER}, PrivateKey: serverKey},
+		emptyCRLPEM:   createCRL(1, nil),
+		revokedCRLPEM: createCRL(2, []x509.RevocationListEntry{{SerialNumber: big.NewInt(2), RevocationTime: time.Now()}}),
+	}
+}
+
+// serveTLSAcceptingConn accepts a single connection, completes the TLS handshake with cert and an unauthenticated
+// startup.
+func serveTLSAcceptingConn(t *testing.T, cert tls.Certificate) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		backend := gaussdbproto.NewBackend(conn, conn)
+		if _, err := backend.ReceiveStartupMessage(); err != nil {
+			return
+		}
+		if _, err := conn.Write([]byte("S")); err != nil {
+			return
+		}
+
+		srv := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+		defer srv.Close()
+		if err := srv.Handshake(); err != nil {
+			return
+		}
+
+		backend = gaussdbproto.NewBackend(srv, srv)
+		if _, err := backend.ReceiveStartupMessage(); err != nil {
+			return
+		}
+		srv.Write(mustEncode((&gaussdbproto.AuthenticationOk{}).Encode(nil)))
+		srv.Write(mustEncode((&gaussdbproto.BackendKeyData{ProcessID: 0, SecretKey: 0}).Encode(nil)))
+		srv.Write(mustEncode((&gaussdbproto.ReadyForQuery{TxStatus: 'I'}).Encode(nil)))
+		backend.Receive()
+	}()
+
+	return ln
+}
+
+func TestConnectTLSCertificateRevocation(t *testing.T) {
+	t.Parallel()
+
+	pki := newTestPKI(t)
+	dir := t.TempDir()
+	rootCertPath := filepath.Join(dir, "root.crt")
+	require.NoError(t, os.WriteFile(rootCertPath, pki.caPEM, 0600))
+	emptyCRLPath := filepath.Join(dir, "empty.crl")
+	require.NoError(t, os.WriteFile(emptyCRLPath, pki.emptyCRLPEM, 0600))
+	revokedCRLPath := filepath.Join(dir, "revoked.crl")
+	require.NoError(t, os.WriteFile(revokedCRLPath, pki.revokedCRLPEM, 0600))
+	revokedCRLDir := filepath.Join(dir, "crls")
+	require.NoError(t, os.Mkdir(revokedCRLDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(revokedCRLDir, "revoked.crl"), pki.revokedCRLPEM, 0600))
+	unrelatedCRLPath := filepath.Join(dir, "unrelated.crl")
+	require.NoError(t, os.WriteFile(unrelatedCRLPath, newTestPKI(t).emptyCRLPEM, 0600))
+	emptyCRLDir := filepath.Join(dir, "empty")
+	require.NoError(t, os.Mkdir(emptyCRLDir, 0700))
+
+	tests := []struct {
+		name     string
+		settings string
+		err      string
+	}{
+		{"verify-ca not revoked", "sslmode=verify-ca sslcrl=" + emptyCRLPath, ""},
+		{"verify-ca revoked", "sslmode=verify-ca sslcrl=" + revokedCRLPath, "has been revoked"},
+		{"verify-full revoked by sslcrldir", "sslmode=verify-full sslcrldir=" + revokedCRLDir, "has been revoked"},
+		{"require with root cert revoked", "sslmode=require sslcrl=" + revokedCRLPath, "has been revoked"},
+		{"prefer ignores CRL", "sslmode=prefer sslcrl=" + revokedCRLPath, ""},
+		{"verify-ca CRL of unrelated CA", "sslmode=verify-ca sslcrl=" + unrelatedCRLPath, "no CRL for issuer"},
+		{"verify-ca empty sslcrldir", "sslmode=verify-ca sslcrldir=" + emptyCRLDir, "no CRL for issuer"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ln := serveTLSAcceptingConn(t, pki.serverCert)
+			_, port, _ := strings.Cut(ln.Addr().String(), ":")
+			config, err := gaussdbconn.ParseConfig(fmt.Sprintf("host=localhost port=%s sslrootcert=%s %s", port, rootCertPath, tt.settings))
+			require.NoError(t, err)
+			// Only try TLS so an sslmode=prefer fallback does not hide a verification failure.
+			config.Fallbacks = nil
+			config.LookupFunc = func(ctx context.Context, host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := gaussdbconn.ConnectConfig(ctx, config)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			conn.Close(ctx)
+		})
+	}
+}
+
+func TestConnectTLSVerifyPeerCertificate(t *testing.T) {
+	t.Parallel()
+
+	pki := newTestPKI(t)
+	dir := t.TempDir()
+	rootCertPath := filepath.Join(dir, "root.crt")
+	require.NoError(t, os.WriteFile(rootCertPath, pki.caPEM, 0600))
+
+	for _, sslmode := range []string{"require", "verify-full"} {
+		sslmode := sslmode
+		t.Run(sslmode, func(t *testing.T) {
+			t.Parallel()
+
+			ln := serveTLSAcceptingConn(t, pki.serverCert)
+			_, port, _ := strings.Cut(ln.Addr().String(), ":")
+
+			var calls []string
+			var verifiedChains int
+			options := gaussdbconn.ParseConfigOptions{
+				VerifyPeerCertificate: func(host string, certificates []*x509.Certificate, chains [][]*x509.Certificate) error {
+					calls = append(calls, host)
+					verifiedChains = len(chains)
+					if certificates[0].Subject.CommonName != "pinned" {
+						return errors.New("certificate not pinned")
+					}
+					return nil
+				},
+			}
+
+			connStr := fmt.Sprintf("host=localhost,localhost port=%s,%s sslmode=%s", port, port, sslmode)
+			if sslmode == "verify-full" {
+				connStr += " sslrootcert=" + rootCertPath
+			}
+			config, err := gaussdbconn.ParseConfigWithOptions(connStr, options)
+			require.NoError(t, err)
+			require.Len(t, config.Fallbacks, 1)
+			require.NotNil(t, config.Fallbacks[0].TLSConfig.VerifyPeerCertificate)
+			config.Fallbacks = nil
+			config.LookupFunc = func(ctx context.Context, host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err = gaussdbconn.ConnectConfig(ctx, config)
+			require.ErrorContains(t, err, "certificate not pinned")
+			require.Equal(t, []string{"localhost"}, calls)
+			if sslmode == "verify-full" {
+				require.Equal(t, 1, verifiedChains)
+			} else {
+				require.Equal(t, 0, verifiedChains)
+			}
+		})
+	}
+}
+
+// testSM2PKI is an SM2 CA with TLCP signing and encryption certificates for localhost.
+type testSM2PKI struct {
+	caPEM    []byte
+	signCert gmtls.Certificate
+	encCert  gmtls.Certificate
+}
+
+func newTestSM2PKI(t *testing.T) *testSM2PKI {
+	caKey, err := sm2.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &gmx509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test SM2 CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              gmx509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		SignatureAlgorithm:    gmx509.SM2WithSM3,
+	}
+	caDER, err := gmx509.CreateCertificate(caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	caCert, err := gmx509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	newCert := func(serial int64, keyUsage gmx509.KeyUsage) gmtls.Certificate {
+		key, err := sm2.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		template := &gmx509.Certificate{
+			SerialNumber:       big.NewInt(serial),
+			Subject:            pkix.Name{CommonName: "localhost"},
+			DNSNames:           []string{"localhost"},
+			NotBefore:          time.Now().Add(-time.Hour),
+			NotAfter:           time.Now().Add(time.Hour),
+			KeyUsage:           keyUsage,
+			ExtKeyUsage:        []gmx509.ExtKeyUsage{gmx509.ExtKeyUsageServerAuth},
+			SignatureAlgorithm: gmx509.SM2WithSM3,
+		}
+		der, err := gmx509.CreateCertificate(template, caCert, &key.PublicKey, caKey)
+		require.NoError(t, err)
+		return gmtls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+	}
+
+	return &testSM2PKI{
+		caPEM:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}),
+		signCert: newCert(2, gmx509.KeyUsageDigitalSignature),
+		encCert:  newCert(3, gmx509.KeyUsageKeyEncipherment|gmx509.KeyUsageDataEncipherment),
+	}
+}
+
+func TestConnectTLCP(t *testing.T) {
+	t.Parallel()
+
+	pki := newTestSM2PKI(t)
+	otherPKI := newTestSM2PKI(t)
+	dir := t.TempDir()
+	rootCertPath := filepath.Join(dir, "root.crt")
+	require.NoError(t, os.WriteFile(rootCertPath, pki.caPEM, 0600))
+	otherRootCertPath := filepath.Join(dir, "other_root.crt")
+	require.NoError(t, os.WriteFile(otherRootCertPath, otherPKI.caPEM, 0600))
+
+	tests := []struct {
+		name     string
+		settings string
+		err      string
+	}{
+		{"require", "sslmode=require", ""},
+		{"verify-full", "sslmode=verify-full sslrootcert=" + rootCertPath, ""},
+		{"verify-ca", "sslmode=verify-ca sslrootcert=" + rootCertPath, ""},
+		{"verify-ca with other CA", "sslmode=verify-ca sslrootcert=" + otherRootCertPath, "tlcp error"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ln, err := net.Listen("tcp", "127.0.0.1:")
+			require.NoError(t, err)
+			defer ln.Close()
+
+			serverErrChan := make(chan error, 1)
+			go func() {
+				defer close(serverErrChan)
+
+				conn, err := ln.Accept()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+				backend := gaussdbproto.NewBackend(conn, conn)
+				startupMessage, err := backend.ReceiveStartupMessage()
+				if err != nil {
+					serverErrChan <- err
+					return
+				}
+				if _, ok := startupMessage.(*gaussdbproto.SSLRequest); !ok {
+					serverErrChan <- fmt.Errorf("unexpected startup message: %#v", startupMessage)
+					return
+				}
+				if _, err := conn.Write([]byte("S")); err != nil {
+					serverErrChan <- err
+					return
+				}
+
+				srv := gmtls.Server(conn, &gmtls.Config{
+					GMSupport:    &gmtls.GMSupport{},
+					Certificates: []gmtls.Certificate{pki.signCert, pki.encCert},
+				})
+				defer srv.Close()
+				if err := srv.Handshake(); err != nil {
+					// The client rejected the server certificate.
+					return
+				}
+
+				steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+				steps = append(steps, gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}))
+				serverErrChan <- (&gaussdbmock.Script{Steps: steps}).Run(gaussdbproto.NewBackend(srv, srv))
+			}()
+
+			_, port, _ := strings.Cut(ln.Addr().String(), ":")
+			config, err := gaussdbconn.ParseConfig(fmt.Sprintf("host=localhost port=%s ssltlcp=on %s", port, tt.settings))
+			require.NoError(t, err)
+			require.Nil(t, config.TLSConfig)
+			require.NotNil(t, config.TLCPConfig)
+			config.LookupFunc = func(ctx context.Context, host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := gaussdbconn.ConnectConfig(ctx, config)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			_, ok := conn.Conn().(*gmtls.Conn)
+			require.True(t, ok)
+
+			closeConn(t, conn)
+			require.NoError(t, <-serverErrChan)
+		})
+	}
+}
+
+// xorGSS is a fake GSSEncrypter that exchanges one token in each direction and "encrypts" by XORing every byte.
+type xorGSS struct{}
+
+func (xorGSS) GetInitToken(host string, service string) ([]byte, error) {
+	return []byte("init " + service + "@" + host), nil
+}
+
+func (xorGSS) GetInitTokenFromSPN(spn string) ([]byte, error) {
+	return []byte("init " + spn), nil
+}
 
-	ensureConnValid(t, gaussdbConn)
+func (xorGSS) Continue(inToken []byte) (bool, []byte, error) {
+	if string(inToken) != "accept" {
+		return false, nil, fmt.Errorf("unexpected server token %q", inToken)
+	}
+	return true, nil, nil
 }
 
-func Example() {
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-	defer cancel()
+func (xorGSS) Wrap(plaintext []byte) ([]byte, error) { return xorGSSBytes(plaintext), nil }
 
-	gaussdbConn, err := gaussdbconn.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
-	if err != nil {
-		log.Fatalln(err)
+func (xorGSS) Unwrap(ciphertext []byte) ([]byte, error) { return xorGSSBytes(ciphertext), nil }
+
+func xorGSSBytes(src []byte) []byte {
+	dst := make([]byte, len(src))
+	for i, b := range src {
+		dst[i] = b ^ 0x5a
 	}
-	defer gaussdbConn.Close(ctx)
+	return dst
+}
 
-	result := gaussdbConn.ExecParams(ctx, "select generate_series(1,3)", nil, nil, nil, nil).Read()
-	if result.Err != nil {
-		log.Fatalln(result.Err)
+// xorGSSServerConn is the server side of a GSSAPI encrypted connection using xorGSS.
+type xorGSSServerConn struct {
+	net.Conn
+	readBuf []byte
+}
+
+func (c *xorGSSServerConn) Read(p []byte) (int, error) {
+	for len(c.readBuf) == 0 {
+		packet, err := readTestGSSPacket(c.Conn)
+		if err != nil {
+			return 0, err
+		}
+		c.readBuf = xorGSSBytes(packet)
 	}
+	n := copy(p, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
 
-	for _, row := range result.Rows {
-		fmt.Println(string(row[0]))
+func (c *xorGSSServerConn) Write(p []byte) (int, error) {
+	if err := writeTestGSSPacket(c.Conn, xorGSSBytes(p)); err != nil {
+		return 0, err
 	}
+	return len(p), nil
+}
 
-	fmt.Println(result.CommandTag)
-	// Output:
-	// 1
-	// 2
-	// 3
-	// SELECT 3
+func readTestGSSPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	packet := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+	return packet, nil
 }
 
-func GetSSLPassword(ctx context.Context) string {
-	connString := os.Getenv(gaussdbgo.EnvGaussdbSslPassword)
-	return connString
+func writeTestGSSPacket(w io.Writer, packet []byte) error {
+	buf := binary.BigEndian.AppendUint32(nil, uint32(len(packet)))
+	_, err := w.Write(append(buf, packet...))
+	return err
 }
 
-var rsaCertPEM = `-----BEGIN CERTIFICATE-----
-MIIDCTCCAfGgAwIBAgIUQDlN1g1bzxIJ8KWkayNcQY5gzMEwDQYJKoZIhvcNAQEL
-BQAwFDESMBAGA1UEAwwJbG9jYWxob3N0MB4XDTIyMDgxNTIxNDgyNloXDTIzMDgx
-NTIxNDgyNlowFDESMBAGA1UEAwwJbG9jYWxob3N0MIIBIjANBgkqhkiG9w0BAQEF
-AAOCAQ8AMIIBCgKCAQEA0vOppiT8zE+076acRORzD5JVbRYKMK3XlWLVrHua4+ct
-Rm54WyP+3XsYU4JGGGKgb8E+u2UosGJYcSM+b+U1/5XPTcpuumS+pCiD9WP++A39
-tsukYwR7m65cgpiI4dlLEZI3EWpAW+Bb3230KiYW4sAmQ0Ih4PrN+oPvzcs86F4d
-9Y03CqVUxRKLBLaClZQAg8qz2Pawwj1FKKjDX7u2fRVR0wgOugpCMOBJMcCgz9pp
-0HSa4x3KZDHEZY7Pah5XwWrCfAEfRWsSTGcNaoN8gSxGFM1JOEJa8SAuPGjFcYIv
-MmVWdw0FXCgYlSDL02fzLE0uyvXBDibzSqOk770JhQIDAQABo1MwUTAdBgNVHQ4E
-FgQUiJ8JLENJ+2k1Xl4o6y2Lc/qHTh0wHwYDVR0jBBgwFoAUiJ8JLENJ+2k1Xl4o
-6y2Lc/qHTh0wDwYDVR0TAQH/BAUwAwEB/zANBgkqhkiG9w0BAQsFAAOCAQEAwjn2
-gnNAhFvh58VqLIjU6ftvn6rhz5B9dg2+XyY8sskLhhkO1nL9339BVZsRt+eI3a7I
-81GNIm9qHVM3MUAcQv3SZy+0UPVUT8DNH2LwHT3CHnYTBP8U+8n8TDNGSTMUhIBB
-Rx+6KwODpwLdI79VGT3IkbU9bZwuepB9I9nM5t/tt5kS4gHmJFlO0aLJFCTO4Scf
-hp/WLPv4XQUH+I3cPfaJRxz2j0Kc8iOzMhFmvl1XOGByjX6X33LnOzY/LVeTSGyS
-VgC32BGtnMwuy5XZYgFAeUx9HKy4tG4OH2Ux6uPF/WAhsug6PXSjV7BK6wYT5i27
-MlascjupnaptKX/wMA==
------END CERTIFICATE-----
-`
+// acceptTestGSSEnc reads a GSSEncRequest from conn. If accept is false it is refused. Otherwise the xorGSS token
+// exchange is performed and a backend over the encrypted connection is returned.
+func acceptTestGSSEnc(conn net.Conn, accept bool) (*gaussdbproto.Backend, error) {
+	startupMessage, err := gaussdbproto.NewBackend(conn, conn).ReceiveStartupMessage()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := startupMessage.(*gaussdbproto.GSSEncRequest); !ok {
+		return nil, fmt.Errorf("unexpected startup message: %#v", startupMessage)
+	}
+	if !accept {
+		_, err := conn.Write([]byte("N"))
+		return nil, err
+	}
+	if _, err := conn.Write([]byte("G")); err != nil {
+		return nil, err
+	}
 
-var rsaKeyPEM = testingKey(`-----BEGIN TESTING KEY-----
-MIIEvwIBADANBgkqhkiG9w0BAQEFAASCBKkwggSlAgEAAoIBAQDS86mmJPzMT7Tv
-ppxE5HMPklVtFgowrdeVYtWse5rj5y1GbnhbI/7dexhTgkYYYqBvwT67ZSiwYlhx
-Iz5v5TX/lc9Nym66ZL6kKIP1Y/74Df22y6RjBHubrlyCmIjh2UsRkjcRakBb4Fvf
-bfQqJhbiwCZDQiHg+s36g+/NyzzoXh31jTcKpVTFEosEtoKVlACDyrPY9rDCPUUo
-qMNfu7Z9FVHTCA66CkIw4EkxwKDP2mnQdJrjHcpkMcRljs9qHlfBasJ8AR9FaxJM
-Zw1qg3yBLEYUzUk4QlrxIC48aMVxgi8yZVZ3DQVcKBiVIMvTZ/MsTS7K9cEOJvNK
-o6TvvQmFAgMBAAECggEAKzTK54Ol33bn2TnnwdiElIjlRE2CUswYXrl6iDRc2hbs
-WAOiVRB/T/+5UMla7/2rXJhY7+rdNZs/ABU24ZYxxCJ77jPrD/Q4c8j0lhsgCtBa
-ycjV543wf0dsHTd+ubtWu8eVzdRUUD0YtB+CJevdPh4a+CWgaMMV0xyYzi61T+Yv
-Z7Uc3awIAiT4Kw9JRmJiTnyMJg5vZqW3BBAX4ZIvS/54ipwEU+9sWLcuH7WmCR0B
-QCTqS6hfJDLm//dGC89Iyno57zfYuiT3PYCWH5crr/DH3LqnwlNaOGSBkhkXuIL+
-QvOaUMe2i0pjqxDrkBx05V554vyy9jEvK7i330HL4QKBgQDUJmouEr0+o7EMBApC
-CPPu58K04qY5t9aGciG/pOurN42PF99yNZ1CnynH6DbcnzSl8rjc6Y65tzTlWods
-bjwVfcmcokG7sPcivJvVjrjKpSQhL8xdZwSAjcqjN4yoJ/+ghm9w+SRmZr6oCQZ3
-1jREfJKT+PGiWTEjYcExPWUD2QKBgQD+jdgq4c3tFavU8Hjnlf75xbStr5qu+fp2
-SGLRRbX+msQwVbl2ZM9AJLoX9MTCl7D9zaI3ONhheMmfJ77lDTa3VMFtr3NevGA6
-MxbiCEfRtQpNkJnsqCixLckx3bskj5+IF9BWzw7y7nOzdhoWVFv/+TltTm3RB51G
-McdlmmVjjQKBgQDSFAw2/YV6vtu2O1XxGC591/Bd8MaMBziev+wde3GHhaZfGVPC
-I8dLTpMwCwowpFKdNeLLl1gnHX161I+f1vUWjw4TVjVjaBUBx+VEr2Tb/nXtiwiD
-QV0a883CnGJjreAblKRMKdpasMmBWhaWmn39h6Iad3zHuCzJjaaiXNpn2QKBgQCf
-k1Q8LanmQnuh1c41f7aD5gjKCRezMUpt9BrejhD1NxheJJ9LNQ8nat6uPedLBcUS
-lmJms+AR2qKqf0QQWyQ98YgAtshgTz8TvQtPT1mWgSOgVFHqJdC8obNK63FyDgc4
-TZVxlgQNDqbBjfv0m5XA9f+mIlB9hYR2iKYzb4K30QKBgQC+LEJYZh00zsXttGHr
-5wU1RzbgDIEsNuu+nZ4MxsaCik8ILNRHNXdeQbnADKuo6ATfhdmDIQMVZLG8Mivi
-UwnwLd1GhizvqvLHa3ULnFphRyMGFxaLGV48axTT2ADoMX67ILrIY/yjycLqRZ3T
-z3w+CgS20UrbLIR1YXfqUXge1g==
------END TESTING KEY-----
-`)
+	token, err := readTestGSSPacket(conn)
+	if err != nil {
+		return nil, err
+	}
+	if string(token) != "init postgres@localhost" {
+		return nil, fmt.Errorf("unexpected client token %q", token)
+	}
+	if err := writeTestGSSPacket(conn, []byte("accept")); err != nil {
+		return nil, err
+	}
 
-func testingKey(s string) string { return strings.ReplaceAll(s, "TESTING KEY", "PRIVATE KEY") }
+	gssConn := &xorGSSServerConn{Conn: conn}
+	return gaussdbproto.NewBackend(gssConn, gssConn), nil
+}
+
+// TestConnectGSSEnc is not parallel because it registers a global GSS provider.
+func TestConnectGSSEnc(t *testing.T) {
+	gaussdbconn.RegisterGSSProvider(func() (gaussdbconn.GSS, error) { return xorGSS{}, nil })
+	t.Cleanup(func() { gaussdbconn.RegisterGSSProvider(nil) })
 
-func TestSNISupport(t *testing.T) {
-	t.Parallel()
 	tests := []struct {
-		name      string
-		sni_param string
-		sni_set   bool
+		name       string
+		gssencmode string
+		acceptGSS  bool
+		gssEnc     bool // the connection is expected to be GSSAPI encrypted
+		err        string
 	}{
-		{
-			name:      "SNI is passed by default",
-			sni_param: "",
-			sni_set:   true,
-		},
-		{
-			name:      "SNI is passed when asked for",
-			sni_param: "sslsni=1",
-			sni_set:   true,
-		},
-		{
-			name:      "SNI is not passed when disabled",
-			sni_param: "sslsni=0",
-			sni_set:   false,
-		},
+		{"require", "require", true, true, ""},
+		{"prefer", "prefer", true, true, ""},
+		{"prefer refused", "prefer", false, false, ""},
+		{"require refused", "require", false, false, "server refused GSSAPI encryption"},
 	}
+
 	for _, tt := range tests {
-		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-
-			ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
-			defer cancel()
-
 			ln, err := net.Listen("tcp", "127.0.0.1:")
 			require.NoError(t, err)
 			defer ln.Close()
 
 			serverErrChan := make(chan error, 1)
-			serverSNINameChan := make(chan string, 1)
-			defer close(serverErrChan)
-			defer close(serverSNINameChan)
-
 			go func() {
-				var sniHost string
+				defer close(serverErrChan)
 
 				conn, err := ln.Accept()
 				if err != nil {
@@ -3658,79 +4944,194 @@ func TestSNISupport(t *testing.T) {
 					return
 				}
 				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
 
-				err = conn.SetDeadline(time.Now().Add(5 * time.Second))
-				if err != nil {
-					serverErrChan <- err
-					return
-				}
-
-				backend := gaussdbproto.NewBackend(conn, conn)
-				startupMessage, err := backend.ReceiveStartupMessage()
+				backend, err := acceptTestGSSEnc(conn, tt.acceptGSS)
 				if err != nil {
 					serverErrChan <- err
 					return
 				}
-
-				switch startupMessage.(type) {
-				case *gaussdbproto.SSLRequest:
-					_, err = conn.Write([]byte("S"))
+				if backend == nil {
+					if tt.err != "" {
+						return
+					}
+					// The client falls back to an unencrypted connection.
+					conn, err = ln.Accept()
 					if err != nil {
 						serverErrChan <- err
 						return
 					}
-				default:
-					serverErrChan <- fmt.Errorf("unexpected startup message: %#v", startupMessage)
-					return
-				}
-
-				cert, err := tls.X509KeyPair([]byte(rsaCertPEM), []byte(rsaKeyPEM))
-				if err != nil {
-					serverErrChan <- err
-					return
-				}
-
-				srv := tls.Server(conn, &tls.Config{
-					Certificates: []tls.Certificate{cert},
-					GetConfigForClient: func(argHello *tls.ClientHelloInfo) (*tls.Config, error) {
-						sniHost = argHello.ServerName
-						return nil, nil
-					},
-				})
-				defer srv.Close()
-
-				if err := srv.Handshake(); err != nil {
-					serverErrChan <- fmt.Errorf("handshake: %w", err)
-					return
+					defer conn.Close()
+					conn.SetDeadline(time.Now().Add(5 * time.Second))
+					backend = gaussdbproto.NewBackend(conn, conn)
 				}
 
-				srv.Write(mustEncode((&gaussdbproto.AuthenticationOk{}).Encode(nil)))
-				srv.Write(mustEncode((&gaussdbproto.BackendKeyData{ProcessID: 0, SecretKey: 0}).Encode(nil)))
-				srv.Write(mustEncode((&gaussdbproto.ReadyForQuery{TxStatus: 'I'}).Encode(nil)))
-
-				serverSNINameChan <- sniHost
+				steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+				steps = append(steps, gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}))
+				serverErrChan <- (&gaussdbmock.Script{Steps: steps}).Run(backend)
 			}()
 
 			_, port, _ := strings.Cut(ln.Addr().String(), ":")
-			connStr := fmt.Sprintf("sslmode=require host=localhost port=%s %s", port, tt.sni_param)
-			_, err = gaussdbconn.Connect(ctx, connStr)
+			config, err := gaussdbconn.ParseConfig(fmt.Sprintf("host=localhost port=%s sslmode=disable gssencmode=%s", port, tt.gssencmode))
+			require.NoError(t, err)
+			require.True(t, config.GSSEncryption)
+			config.LookupFunc = func(ctx context.Context, host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
 
-			select {
-			case sniHost := <-serverSNINameChan:
-				if tt.sni_set {
-					require.Equal(t, "localhost", sniHost)
-				} else {
-					require.Equal(t, "", sniHost)
-				}
-			case err = <-serverErrChan:
-				t.Fatalf("server failed with error: %+v", err)
-			case <-time.After(time.Millisecond * 100):
-				t.Fatal("exceeded connection timeout without erroring out")
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := gaussdbconn.ConnectConfig(ctx, config)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				require.NoError(t, <-serverErrChan)
+				return
 			}
+			require.NoError(t, err)
+			_, isTCPConn := conn.Conn().(*net.TCPConn)
+			require.Equal(t, tt.gssEnc, !isTCPConn)
+
+			closeConn(t, conn)
+			require.NoError(t, <-serverErrChan)
 		})
 	}
 }
 
+// TestConnectGSSEncPreferFallsBackToTLS is not parallel because it registers a global GSS provider.
+func TestConnectGSSEncPreferFallsBackToTLS(t *testing.T) {
+	gaussdbconn.RegisterGSSProvider(func() (gaussdbconn.GSS, error) { return xorGSS{}, nil })
+	t.Cleanup(func() { gaussdbconn.RegisterGSSProvider(nil) })
+
+	pki := newTestPKI(t)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		_, err = acceptTestGSSEnc(conn, false)
+		conn.Close()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		conn, err = ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		startupMessage, err := gaussdbproto.NewBackend(conn, conn).ReceiveStartupMessage()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		if _, ok := startupMessage.(*gaussdbproto.SSLRequest); !ok {
+			serverErrChan <- fmt.Errorf("unexpected startup message: %#v", startupMessage)
+			return
+		}
+		if _, err := conn.Write([]byte("S")); err != nil {
+			serverErrChan <- err
+			return
+		}
+		srv := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{pki.serverCert}})
+		defer srv.Close()
+
+		steps := gaussdbmock.AcceptUnauthenticatedConnRequestSteps()
+		steps = append(steps, gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}))
+		serverErrChan <- (&gaussdbmock.Script{Steps: steps}).Run(gaussdbproto.NewBackend(srv, srv))
+	}()
+
+	_, port, _ := strings.Cut(ln.Addr().String(), ":")
+	config, err := gaussdbconn.ParseConfig(fmt.Sprintf("host=localhost port=%s sslmode=require gssencmode=prefer", port))
+	require.NoError(t, err)
+	require.True(t, config.GSSEncryption)
+	require.Nil(t, config.TLSConfig)
+	require.Len(t, config.Fallbacks, 1)
+	require.NotNil(t, config.Fallbacks[0].TLSConfig)
+	config.LookupFunc = func(ctx context.Context, host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := gaussdbconn.ConnectConfig(ctx, config)
+	require.NoError(t, err)
+	_, isTLSConn := conn.Conn().(*tls.Conn)
+	require.True(t, isTLSConn)
+
+	closeConn(t, conn)
+	require.NoError(t, <-serverErrChan)
+}
+
+// scriptedReadConn is a net.Conn whose Reads return the results of reads in order.
+type scriptedReadConn struct {
+	net.Conn
+	reads []scriptedRead
+}
+
+type scriptedRead struct {
+	data []byte
+	err  error
+}
+
+func (c *scriptedReadConn) Read(p []byte) (int, error) {
+	if len(c.reads) == 0 {
+		return 0, io.EOF
+	}
+	r := &c.reads[0]
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	if len(r.data) > 0 {
+		return n, nil
+	}
+	c.reads = c.reads[1:]
+	return n, r.err
+}
+
+func TestGSSEncConnReadResumesAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	var stream []byte
+	for _, s := range []string{"hello", "world"} {
+		stream = binary.BigEndian.AppendUint32(stream, uint32(len(s)))
+		stream = append(stream, xorGSSBytes([]byte(s))...)
+	}
+
+	// Time out in the middle of the first header, the first body and the second body.
+	conn := gaussdbconn.NewGSSEncConn(&scriptedReadConn{reads: []scriptedRead{
+		{data: stream[:2], err: os.ErrDeadlineExceeded},
+		{data: stream[2:6], err: os.ErrDeadlineExceeded},
+		{data: stream[6:9]},
+		{data: stream[9:15], err: os.ErrDeadlineExceeded},
+		{data: stream[15:]},
+	}}, xorGSS{})
+
+	buf := make([]byte, 16)
+	var received []byte
+	var timeouts int
+	for len(received) < len("helloworld") {
+		n, err := conn.Read(buf)
+		received = append(received, buf[:n]...)
+		if err != nil {
+			require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+			timeouts++
+		}
+	}
+	require.Equal(t, "helloworld", string(received))
+	require.Equal(t, 3, timeouts)
+}
+
 func TestFatalErrorReceivedInPipelineMode(t *testing.T) {
 	t.Parallel()
 
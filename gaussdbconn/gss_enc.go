@@ -0,0 +1,263 @@
+package gaussdbconn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+)
+
+// gssEncMaxPacketSize is the maximum size of a GSSAPI encrypted packet including its 4 byte length. It matches
+// PQ_GSS_MAX_PACKET_SIZE of the server.
+const gssEncMaxPacketSize = 16384
+
+// gssEncMaxPlaintextSize is the maximum amount of data wrapped into a single packet. It leaves room for the overhead
+// added by the GSSAPI mechanism.
+const gssEncMaxPlaintextSize = gssEncMaxPacketSize - 4 - 1024
+
+// GSSEncrypter is implemented by GSS providers that support GSSAPI transport encryption (gssencmode). Wrap and Unwrap
+// are only called after the security context has been established with GetInitToken or GetInitTokenFromSPN and
+// Continue. Wrap and Unwrap may be called concurrently with each other.
+type GSSEncrypter interface {
+	GSS
+	// Wrap encrypts plaintext with confidentiality (gss_wrap).
+	Wrap(plaintext []byte) ([]byte, error)
+	// Unwrap decrypts a token produced by the server's Wrap (gss_unwrap).
+	Unwrap(ciphertext []byte) ([]byte, error)
+}
+
+func parseGSSEncModeSetting(s string) (string, error) {
+	switch s {
+	case "disable", "prefer", "require":
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown gssencmode value: %v", s)
+	}
+}
+
+// startGSSEnc requests GSSAPI encryption, establishes a security context with the registered GSS provider, and returns
+// conn wrapped in the GSSAPI encrypted packet framing.
+func startGSSEnc(conn net.Conn, config *Config, host string) (net.Conn, error) {
+	if newGSS == nil {
+		return nil, errors.New("kerberos error: no GSSAPI provider registered, see https://github.com/otan/gopgkrb5")
+	}
+	gss, err := newGSS()
+	if err != nil {
+		return nil, err
+	}
+	enc, ok := gss.(GSSEncrypter)
+	if !ok {
+		return nil, errors.New("registered GSSAPI provider does not support encryption")
+	}
+
+	buf, err := (&gaussdbproto.GSSEncRequest{}).Encode(nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(buf); err != nil {
+		return nil, err
+	}
+
+	response := make([]byte, 1)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, err
+	}
+	if response[0] != 'G' {
+		return nil, errors.New("server refused GSSAPI encryption")
+	}
+
+	var token []byte
+	if config.KerberosSpn != "" {
+		token, err = enc.GetInitTokenFromSPN(config.KerberosSpn)
+	} else {
+		service := "postgres"
+		if config.KerberosSrvName != "" {
+			service = config.KerberosSrvName
+		}
+		token, err = enc.GetInitToken(host, service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if len(token) > 0 {
+			if err := writeGSSPacket(conn, token); err != nil {
+				return nil, err
+			}
+		}
+
+		serverToken, err := readGSSPacket(conn)
+		if err != nil {
+			return nil, err
+		}
+
+		var done bool
+		done, token, err = enc.Continue(serverToken)
+		if err != nil {
+			return nil, err
+		}
+		if done {
+			if len(token) > 0 {
+				if err := writeGSSPacket(conn, token); err != nil {
+					return nil, err
+				}
+			}
+			break
+		}
+	}
+
+	return &gssEncConn{Conn: conn, enc: enc}, nil
+}
+
+func writeGSSPacket(w io.Writer, data []byte) error {
+	if len(data) > gssEncMaxPacketSize-4 {
+		return fmt.Errorf("GSSAPI packet too large: %d bytes", len(data))
+	}
+	buf := make([]byte, 4, 4+len(data))
+	binary.BigEndian.PutUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func readGSSPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	// An ErrorResponse may be sent instead of a packet if the server fails before encryption is established. Its first
+	// byte 'E' would be read as a length beyond the maximum packet size.
+	if header[0] == 'E' {
+		return nil, readGSSErrorResponse(r, header)
+	}
+
+	n := binary.BigEndian.Uint32(header)
+	if n > gssEncMaxPacketSize-4 {
+		return nil, fmt.Errorf("GSSAPI packet too large: %d bytes", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func readGSSErrorResponse(r io.Reader, header []byte) error {
+	// The message length is the 4 bytes following 'E', of which 3 have already been read.
+	lenBuf := make([]byte, 4)
+	copy(lenBuf, header[1:])
+	if _, err := io.ReadFull(r, lenBuf[3:]); err != nil {
+		return err
+	}
+	msgLen := binary.BigEndian.Uint32(lenBuf)
+	if msgLen < 4 || msgLen > gssEncMaxPacketSize {
+		return errors.New("server sent an invalid error response during GSSAPI negotiation")
+	}
+	body := make([]byte, msgLen-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+
+	var msg gaussdbproto.ErrorResponse
+	if err := msg.Decode(body); err != nil {
+		return err
+	}
+	return ErrorResponseToGuassdbError(&msg)
+}
+
+// gssEncConn is a net.Conn that encrypts all data with GSSAPI. Every packet is a 4 byte big endian length followed by
+// the wrapped data.
+type gssEncConn struct {
+	net.Conn
+	enc GSSEncrypter
+
+	readBuf []byte // unwrapped data not yet returned by Read
+
+	// The packet being read is kept across Read calls. A Read interrupted by a deadline resumes it without losing the
+	// packet framing.
+	header     [4]byte
+	headerRead int
+	body       []byte
+	bodyRead   int
+}
+
+func (c *gssEncConn) Read(p []byte) (int, error) {
+	for len(c.readBuf) == 0 {
+		packet, err := c.readPacket()
+		if err != nil {
+			return 0, err
+		}
+		c.readBuf, err = c.enc.Unwrap(packet)
+		if err != nil {
+			return 0, fmt.Errorf("GSSAPI unwrap: %w", err)
+		}
+	}
+
+	n := copy(p, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+// readPacket reads the next packet. If it returns an error the part of the packet read so far is kept and the next
+// call continues with the rest.
+func (c *gssEncConn) readPacket() ([]byte, error) {
+	for c.headerRead < len(c.header) {
+		n, err := c.Conn.Read(c.header[c.headerRead:])
+		c.headerRead += n
+		if err != nil && c.headerRead < len(c.header) {
+			return nil, err
+		}
+	}
+
+	if c.body == nil {
+		n := binary.BigEndian.Uint32(c.header[:])
+		if n > gssEncMaxPacketSize-4 {
+			return nil, fmt.Errorf("GSSAPI packet too large: %d bytes", n)
+		}
+		c.body = make([]byte, n)
+	}
+
+	for c.bodyRead < len(c.body) {
+		n, err := c.Conn.Read(c.body[c.bodyRead:])
+		c.bodyRead += n
+		if err != nil && c.bodyRead < len(c.body) {
+			return nil, err
+		}
+	}
+
+	body := c.body
+	c.headerRead, c.body, c.bodyRead = 0, nil, 0
+	return body, nil
+}
+
+func (c *gssEncConn) Write(p []byte) (int, error) {
+	var buf []byte
+	for written := 0; written < len(p); {
+		chunk := p[written:]
+		if len(chunk) > gssEncMaxPlaintextSize {
+			chunk = chunk[:gssEncMaxPlaintextSize]
+		}
+
+		wrapped, err := c.enc.Wrap(chunk)
+		if err != nil {
+			return written, fmt.Errorf("GSSAPI wrap: %w", err)
+		}
+		if len(wrapped) > gssEncMaxPacketSize-4 {
+			return written, fmt.Errorf("GSSAPI wrapped packet too large: %d bytes", len(wrapped))
+		}
+
+		buf = binary.BigEndian.AppendUint32(buf[:0], uint32(len(wrapped)))
+		buf = append(buf, wrapped...)
+		if _, err := c.Conn.Write(buf); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+	}
+
+	return len(p), nil
+}
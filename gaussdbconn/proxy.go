@@ -0,0 +1,302 @@
+package gaussdbconn
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn/ctxwatch"
+)
+
+// maxProxyResponseHeaderSize limits how much of an HTTP CONNECT response is read before giving up.
+const maxProxyResponseHeaderSize = 8192
+
+// parseProxySetting parses the proxy setting. Supported schemes are socks5, socks5h and http. With socks5 host names
+// are resolved locally by Config.LookupFunc. With socks5h and http they are resolved by the proxy.
+func parseProxySetting(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		// url.Error contains the URL and with it the proxy password.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return nil, urlErr.Err
+		}
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "socks5", "socks5h", "http":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+
+	if u.Hostname() == "" {
+		return nil, errors.New("missing proxy host")
+	}
+	if u.Port() == "" {
+		port := "1080"
+		if u.Scheme == "http" {
+			port = "8080"
+		}
+		u.Host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	return u, nil
+}
+
+// proxyResolvesHostNames reports whether host names should be passed to the proxy instead of being resolved locally.
+func proxyResolvesHostNames(proxyURL *url.URL) bool {
+	return proxyURL.Scheme != "socks5"
+}
+
+// makeProxyDialFunc returns a DialFunc that tunnels TCP connections through the proxy at proxyURL. dial is used to
+// connect to the proxy itself. Unix domain sockets are dialed directly with dial.
+//
+// The returned net.Conn reports the tunneled address as its RemoteAddr so that CancelRequest is sent through the proxy
+// to the same server.
+func makeProxyDialFunc(proxyURL *url.URL, dial DialFunc) DialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		if network == "unix" {
+			return dial(ctx, network, address)
+		}
+
+		conn, err := dial(ctx, "tcp", proxyURL.Host)
+		if err != nil {
+			return nil, &proxyError{proxy: proxyURL.Redacted(), msg: "dial error", err: err}
+		}
+
+		if ctx != context.Background() {
+			contextWatcher := ctxwatch.NewContextWatcher(&DeadlineContextWatcherHandler{Conn: conn})
+			contextWatcher.Watch(ctx)
+			defer contextWatcher.Unwatch()
+		}
+
+		switch proxyURL.Scheme {
+		case "http":
+			err = httpConnect(conn, proxyURL, address)
+		default:
+			err = socks5Connect(conn, proxyURL, address)
+		}
+		if err != nil {
+			conn.Close()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				err = ctxErr
+			}
+			return nil, &proxyError{proxy: proxyURL.Redacted(), msg: "connect to " + address + " failed", err: err}
+		}
+
+		return &proxyConn{Conn: conn, remoteAddr: proxyAddr{network: network, address: address}}, nil
+	}
+}
+
+type proxyError struct {
+	proxy string
+	msg   string
+	err   error
+}
+
+func (e *proxyError) Error() string {
+	return fmt.Sprintf("proxy %s: %s: %v", e.proxy, e.msg, e.err)
+}
+
+func (e *proxyError) Unwrap() error {
+	return e.err
+}
+
+// proxyConn is a connection tunneled through a proxy.
+type proxyConn struct {
+	net.Conn
+	remoteAddr proxyAddr
+}
+
+// RemoteAddr returns the address of the server at the other end of the tunnel rather than the address of the proxy.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	return c.remoteAddr
+}
+
+type proxyAddr struct {
+	network string
+	address string
+}
+
+func (a proxyAddr) Network() string { return a.network }
+func (a proxyAddr) String() string  { return a.address }
+
+// socks5Connect performs a SOCKS5 (RFC 1928) CONNECT handshake for address on conn. Username / password
+// authentication (RFC 1929) is offered when proxyURL contains user information.
+func socks5Connect(conn net.Conn, proxyURL *url.URL, address string) error {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port: %w", err)
+	}
+
+	methods := []byte{0x00}
+	if proxyURL.User != nil {
+		methods = append(methods, 0x02)
+	}
+	greeting := append([]byte{0x05, byte(len(methods))}, methods...)
+	if _, err := conn.Write(greeting); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return err
+	}
+	if buf[0] != 0x05 {
+		return fmt.Errorf("unexpected SOCKS version %d", buf[0])
+	}
+
+	switch buf[1] {
+	case 0x00:
+	case 0x02:
+		if proxyURL.User == nil {
+			return errors.New("proxy requires username / password authentication")
+		}
+		username := proxyURL.User.Username()
+		password, _ := proxyURL.User.Password()
+		if len(username) > 255 || len(password) > 255 {
+			return errors.New("proxy username or password too long")
+		}
+		auth := []byte{0x01, byte(len(username))}
+		auth = append(auth, username...)
+		auth = append(auth, byte(len(password)))
+		auth = append(auth, password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, buf); err != nil {
+			return err
+		}
+		if buf[1] != 0x00 {
+			return errors.New("proxy username / password authentication failed")
+		}
+	default:
+		return errors.New("no acceptable proxy authentication method")
+	}
+
+	req := []byte{0x05, 0x01, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(req, 0x01)
+			req = append(req, ip4...)
+		} else {
+			req = append(req, 0x04)
+			req = append(req, ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return errors.New("host name too long")
+		}
+		req = append(req, 0x03, byte(len(host)))
+		req = append(req, host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[1] != 0x00 {
+		return fmt.Errorf("SOCKS CONNECT failed: %s", socks5ReplyText(reply[1]))
+	}
+
+	var addrLen int
+	switch reply[3] {
+	case 0x01:
+		addrLen = net.IPv4len
+	case 0x04:
+		addrLen = net.IPv6len
+	case 0x03:
+		if _, err := io.ReadFull(conn, buf[:1]); err != nil {
+			return err
+		}
+		addrLen = int(buf[0])
+	default:
+		return fmt.Errorf("unexpected SOCKS address type %d", reply[3])
+	}
+
+	// Discard the bound address and port.
+	if _, err := io.ReadFull(conn, make([]byte, addrLen+2)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func socks5ReplyText(code byte) string {
+	switch code {
+	case 0x01:
+		return "general SOCKS server failure"
+	case 0x02:
+		return "connection not allowed by ruleset"
+	case 0x03:
+		return "network unreachable"
+	case 0x04:
+		return "host unreachable"
+	case 0x05:
+		return "connection refused"
+	case 0x06:
+		return "TTL expired"
+	case 0x07:
+		return "command not supported"
+	case 0x08:
+		return "address type not supported"
+	default:
+		return "unknown error " + strconv.Itoa(int(code))
+	}
+}
+
+// httpConnect establishes a tunnel to address on conn with an HTTP CONNECT request. The response is read byte by byte
+// so that nothing sent by the server after the response header is consumed.
+func httpConnect(conn net.Conn, proxyURL *url.URL, address string) error {
+	var req strings.Builder
+	fmt.Fprintf(&req, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n", address, address)
+	if proxyURL.User != nil {
+		password, _ := proxyURL.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+		fmt.Fprintf(&req, "Proxy-Authorization: Basic %s\r\n", credentials)
+	}
+	req.WriteString("\r\n")
+	if _, err := io.WriteString(conn, req.String()); err != nil {
+		return err
+	}
+
+	header := make([]byte, 0, 512)
+	b := make([]byte, 1)
+	for !bytes.HasSuffix(header, []byte("\r\n\r\n")) {
+		if len(header) >= maxProxyResponseHeaderSize {
+			return errors.New("proxy response header too large")
+		}
+		if _, err := io.ReadFull(conn, b); err != nil {
+			return err
+		}
+		header = append(header, b[0])
+	}
+
+	statusLine, _, _ := bytes.Cut(header, []byte("\r\n"))
+	fields := strings.SplitN(string(statusLine), " ", 3)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/") {
+		return fmt.Errorf("malformed proxy response %q", statusLine)
+	}
+	if fields[1] != "200" {
+		return fmt.Errorf("proxy responded %q", strings.Join(fields[1:], " "))
+	}
+
+	return nil
+}
@@ -3,7 +3,7 @@ package gaussdbconn
 import (
 	"crypto/hmac"
 	"crypto/sha1"
-	"fmt"
+	"encoding/hex"
 	"strings"
 
 	"crypto/sha256"
@@ -40,18 +40,7 @@ func generateKFromPBKDF2(password string, random64code string, serverIteration i
 }
 
 func bytesToHexString(src []byte) string {
-	s := ""
-	for i := 0; i < len(src); i++ {
-		v := src[i] & 0xFF
-		hv := fmt.Sprintf("%x", v)
-		if len(hv) < 2 {
-			s += hv
-			s += "0"
-		} else {
-			s += hv
-		}
-	}
-	return s
+	return hex.EncodeToString(src)
 }
 
 func getKeyFromHmac(key []byte, data []byte) []byte {
@@ -113,7 +102,7 @@ func RFC5802Algorithm(password string, random64code string, token string, server
 	}
 	tokenByte := hexStringToBytes(token)
 	clientSignature := getKeyFromHmac(serverKey, tokenByte)
-	if serverSignature != "" && serverSignature != bytesToHexString(clientSignature) {
+	if serverSignature != "" && !hmac.Equal([]byte(strings.ToLower(serverSignature)), []byte(bytesToHexString(clientSignature))) {
 		return []byte("")
 	}
 	hmacResult := getKeyFromHmac(storedKey, tokenByte)
@@ -0,0 +1,119 @@
+package gaussdbconn
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// tcpOptions are the libpq compatible TCP socket settings keepalives, keepalives_idle, keepalives_interval,
+// keepalives_count and tcp_user_timeout. Zero values mean the operating system default.
+type tcpOptions struct {
+	set bool // any setting was present
+
+	keepAlivesDisabled bool
+	keepAlivesIdle     time.Duration
+	keepAlivesInterval time.Duration
+	keepAlivesCount    int
+	userTimeout        time.Duration
+}
+
+func parseTCPOptions(settings map[string]string) (tcpOptions, error) {
+	var opts tcpOptions
+
+	parseNonNegative := func(key string) (int64, bool, error) {
+		s, present := settings[key]
+		if !present || s == "" {
+			return 0, false, nil
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+		if err != nil {
+			return 0, false, &tcpOptionError{key: key, err: err}
+		}
+		if n < 0 {
+			return 0, false, &tcpOptionError{key: key, err: errors.New("must not be negative")}
+		}
+		opts.set = true
+		return n, true, nil
+	}
+
+	n, present, err := parseNonNegative("keepalives")
+	if err != nil {
+		return opts, err
+	}
+	opts.keepAlivesDisabled = present && n == 0
+
+	if n, _, err = parseNonNegative("keepalives_idle"); err != nil {
+		return opts, err
+	}
+	opts.keepAlivesIdle = time.Duration(n) * time.Second
+
+	if n, _, err = parseNonNegative("keepalives_interval"); err != nil {
+		return opts, err
+	}
+	opts.keepAlivesInterval = time.Duration(n) * time.Second
+
+	if n, _, err = parseNonNegative("keepalives_count"); err != nil {
+		return opts, err
+	}
+	opts.keepAlivesCount = int(n)
+
+	if n, _, err = parseNonNegative("tcp_user_timeout"); err != nil {
+		return opts, err
+	}
+	opts.userTimeout = time.Duration(n) * time.Millisecond
+
+	return opts, nil
+}
+
+type tcpOptionError struct {
+	key string
+	err error
+}
+
+func (e *tcpOptionError) Error() string {
+	return "invalid " + e.key + ": " + e.err.Error()
+}
+
+func (e *tcpOptionError) Unwrap() error {
+	return e.err
+}
+
+// configureDialer applies opts to d. When no setting was present d is left unchanged so Go's default keepalive
+// behavior is used.
+func (opts tcpOptions) configureDialer(d *net.Dialer) {
+	if !opts.set {
+		return
+	}
+
+	if opts.keepAlivesDisabled {
+		d.KeepAlive = -1
+	} else if opts.keepAlivesIdle > 0 {
+		d.KeepAlive = opts.keepAlivesIdle
+	}
+
+	if !tcpOptionsSupported {
+		return
+	}
+
+	// The keepalive settings are applied to the socket by the control function. Disable Go's own keepalive handling as
+	// it would overwrite them after the connection is established.
+	d.KeepAlive = -1
+	d.Control = func(network, address string, c syscall.RawConn) error {
+		if !strings.HasPrefix(network, "tcp") {
+			return nil
+		}
+
+		var sockoptErr error
+		err := c.Control(func(fd uintptr) {
+			sockoptErr = setTCPOptions(fd, opts)
+		})
+		if err != nil {
+			return err
+		}
+		return sockoptErr
+	}
+}
@@ -0,0 +1,53 @@
+package gaussdbconn
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+const tcpOptionsSupported = true
+
+// TCP_KEEPINTVL and TCP_KEEPCNT are not defined by the syscall package on darwin.
+const (
+	tcpKeepIntvl = 0x101
+	tcpKeepCnt   = 0x102
+)
+
+// defaultKeepAlivesPeriod matches the keepalive period Go uses when net.Dialer.KeepAlive is zero.
+const defaultKeepAlivesPeriod = 15 * time.Second
+
+// setTCPOptions applies opts to the socket. tcp_user_timeout is not supported on macOS and is ignored.
+func setTCPOptions(fd uintptr, opts tcpOptions) error {
+	s := int(fd)
+
+	if opts.keepAlivesDisabled {
+		return nil
+	}
+
+	idle := opts.keepAlivesIdle
+	if idle == 0 {
+		idle = defaultKeepAlivesPeriod
+	}
+	interval := opts.keepAlivesInterval
+	if interval == 0 {
+		interval = defaultKeepAlivesPeriod
+	}
+
+	if err := syscall.SetsockoptInt(s, syscall.SOL_SOCKET, syscall.SO_KEEPALIVE, 1); err != nil {
+		return os.NewSyscallError("setsockopt SO_KEEPALIVE", err)
+	}
+	if err := syscall.SetsockoptInt(s, syscall.IPPROTO_TCP, syscall.TCP_KEEPALIVE, int(idle/time.Second)); err != nil {
+		return os.NewSyscallError("setsockopt TCP_KEEPALIVE", err)
+	}
+	if err := syscall.SetsockoptInt(s, syscall.IPPROTO_TCP, tcpKeepIntvl, int(interval/time.Second)); err != nil {
+		return os.NewSyscallError("setsockopt TCP_KEEPINTVL", err)
+	}
+	if opts.keepAlivesCount > 0 {
+		if err := syscall.SetsockoptInt(s, syscall.IPPROTO_TCP, tcpKeepCnt, opts.keepAlivesCount); err != nil {
+			return os.NewSyscallError("setsockopt TCP_KEEPCNT", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package gaussdbconn
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+const tcpOptionsSupported = true
+
+// TCP_USER_TIMEOUT is not defined by the syscall package on all architectures.
+const tcpUserTimeout = 0x12
+
+// defaultKeepAlivesPeriod matches the keepalive period Go uses when net.Dialer.KeepAlive is zero.
+const defaultKeepAlivesPeriod = 15 * time.Second
+
+func setTCPOptions(fd uintptr, opts tcpOptions) error {
+	s := int(fd)
+
+	if !opts.keepAlivesDisabled {
+		idle := opts.keepAlivesIdle
+		if idle == 0 {
+			idle = defaultKeepAlivesPeriod
+		}
+		interval := opts.keepAlivesInterval
+		if interval == 0 {
+			interval = defaultKeepAlivesPeriod
+		}
+
+		if err := syscall.SetsockoptInt(s, syscall.SOL_SOCKET, syscall.SO_KEEPALIVE, 1); err != nil {
+			return os.NewSyscallError("setsockopt SO_KEEPALIVE", err)
+		}
+		if err := syscall.SetsockoptInt(s, syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE, int(idle/time.Second)); err != nil {
+			return os.NewSyscallError("setsockopt TCP_KEEPIDLE", err)
+		}
+		if err := syscall.SetsockoptInt(s, syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL, int(interval/time.Second)); err != nil {
+			return os.NewSyscallError("setsockopt TCP_KEEPINTVL", err)
+		}
+		if opts.keepAlivesCount > 0 {
+			if err := syscall.SetsockoptInt(s, syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT, opts.keepAlivesCount); err != nil {
+				return os.NewSyscallError("setsockopt TCP_KEEPCNT", err)
+			}
+		}
+	}
+
+	if opts.userTimeout > 0 {
+		if err := syscall.SetsockoptInt(s, syscall.IPPROTO_TCP, tcpUserTimeout, int(opts.userTimeout/time.Millisecond)); err != nil {
+			return os.NewSyscallError("setsockopt TCP_USER_TIMEOUT", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package gaussdbconn
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPOptionsAppliedToSocket(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	config, err := ParseConfig("host=127.0.0.1 keepalives_idle=30 keepalives_interval=5 keepalives_count=3 tcp_user_timeout=10000")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := config.DialFunc(ctx, "tcp", ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	rawConn, err := conn.(*net.TCPConn).SyscallConn()
+	require.NoError(t, err)
+
+	getsockopt := func(level, opt int) int {
+		var value int
+		var sockoptErr error
+		err := rawConn.Control(func(fd uintptr) {
+			value, sockoptErr = syscall.GetsockoptInt(int(fd), level, opt)
+		})
+		require.NoError(t, err)
+		require.NoError(t, sockoptErr)
+		return value
+	}
+
+	assert.Equal(t, 1, getsockopt(syscall.SOL_SOCKET, syscall.SO_KEEPALIVE))
+	assert.Equal(t, 30, getsockopt(syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE))
+	assert.Equal(t, 5, getsockopt(syscall.IPPROTO_TCP, syscall.TCP_KEEPINTVL))
+	assert.Equal(t, 3, getsockopt(syscall.IPPROTO_TCP, syscall.TCP_KEEPCNT))
+	assert.Equal(t, 10000, getsockopt(syscall.IPPROTO_TCP, tcpUserTimeout))
+}
+
+func TestTCPOptionsKeepalivesDisabled(t *testing.T) {
+	t.Parallel()
+
+	opts, err := parseTCPOptions(map[string]string{"keepalives": "0"})
+	require.NoError(t, err)
+
+	d := &net.Dialer{}
+	opts.configureDialer(d)
+	assert.Equal(t, time.Duration(-1), d.KeepAlive)
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin
+// +build !linux,!darwin
+
+package gaussdbconn
+
+// On other platforms only keepalives and keepalives_idle are supported through net.Dialer.KeepAlive.
+const tcpOptionsSupported = false
+
+func setTCPOptions(fd uintptr, opts tcpOptions) error {
+	return nil
+}
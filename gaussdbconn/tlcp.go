@@ -0,0 +1,227 @@
+package gaussdbconn
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/tjfoc/gmsm/gmtls"
+	"github.com/tjfoc/gmsm/x509"
+)
+
+// parseTLCPSetting parses the ssltlcp setting.
+func parseTLCPSetting(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "off", "false", "0":
+		return false, nil
+	case "on", "true", "1":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown ssltlcp value: %v", s)
+	}
+}
+
+// configTLCP uses the libpq TLS parameters to construct []*gmtls.Config for TLCP (GM/T 0024) connections with SM2
+// certificates. sslmode has the same meaning as for configTLS. sslcert and sslkey are the SM2 signing certificate and
+// key of the client and sslenccert and sslenckey its SM2 encryption certificate and key.
+func configTLCP(settings map[string]string, thisHost string, parseConfigOptions ParseConfigOptions) ([]*gmtls.Config, error) {
+	host := thisHost
+	sslmode := settings["sslmode"]
+	sslrootcert := settings["sslrootcert"]
+	sslcert := settings["sslcert"]
+	sslkey := settings["sslkey"]
+	sslenccert := settings["sslenccert"]
+	sslenckey := settings["sslenckey"]
+	sslpassword := settings["sslpassword"]
+
+	// Match libpq default behavior
+	if sslmode == "" {
+		sslmode = "prefer"
+	}
+
+	if settings["sslcrl"] != "" || settings["sslcrldir"] != "" {
+		return nil, errors.New("sslcrl and sslcrldir are not supported with ssltlcp")
+	}
+	if parseConfigOptions.VerifyPeerCertificate != nil {
+		return nil, errors.New("ParseConfigOptions.VerifyPeerCertificate is not supported with ssltlcp")
+	}
+
+	tlcpConfig := &gmtls.Config{GMSupport: &gmtls.GMSupport{}}
+
+	if sslrootcert != "" {
+		if sslrootcert == "system" {
+			return nil, errors.New(`sslrootcert "system" is not supported with ssltlcp`)
+		}
+
+		caCert, err := os.ReadFile(sslrootcert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA file: %w", err)
+		}
+
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, errors.New("unable to add CA to cert pool")
+		}
+		tlcpConfig.RootCAs = caCertPool
+	}
+
+	switch sslmode {
+	case "disable":
+		return []*gmtls.Config{nil}, nil
+	case "allow", "prefer":
+		tlcpConfig.InsecureSkipVerify = true
+	case "require":
+		tlcpConfig.InsecureSkipVerify = true
+		// if a root CA file exists,
+		// the behavior of sslmode=require should be the same as that of verify-ca
+		if sslrootcert != "" {
+			tlcpConfig.VerifyPeerCertificate = makeTLCPVerifyCAFunc(tlcpConfig.RootCAs)
+		}
+	case "verify-ca":
+		// Verify the certificate chain but not the host name like configTLS does.
+		tlcpConfig.InsecureSkipVerify = true
+		tlcpConfig.VerifyPeerCertificate = makeTLCPVerifyCAFunc(tlcpConfig.RootCAs)
+	case "verify-full":
+		tlcpConfig.ServerName = host
+	default:
+		return nil, errors.New("sslmode is invalid")
+	}
+
+	if (sslcert != "" && sslkey == "") || (sslcert == "" && sslkey != "") {
+		return nil, errors.New(`both "sslcert" and "sslkey" are required`)
+	}
+	if (sslenccert != "" && sslenckey == "") || (sslenccert == "" && sslenckey != "") {
+		return nil, errors.New(`both "sslenccert" and "sslenckey" are required`)
+	}
+	if sslenccert != "" && sslcert == "" {
+		return nil, errors.New(`"sslenccert" requires "sslcert"`)
+	}
+
+	if sslcert != "" {
+		cert, err := loadSM2KeyPair(sslcert, sslkey, sslpassword, parseConfigOptions.GetSSLPassword)
+		if err != nil {
+			return nil, err
+		}
+		tlcpConfig.Certificates = append(tlcpConfig.Certificates, cert)
+	}
+	if sslenccert != "" {
+		cert, err := loadSM2KeyPair(sslenccert, sslenckey, sslpassword, parseConfigOptions.GetSSLPassword)
+		if err != nil {
+			return nil, err
+		}
+		tlcpConfig.Certificates = append(tlcpConfig.Certificates, cert)
+	}
+
+	// Per RFC 6066, do not set the server name if the host is a literal IP address.
+	if tlcpConfig.ServerName == "" && settings["sslsni"] != "0" && net.ParseIP(host) == nil {
+		tlcpConfig.ServerName = host
+	}
+
+	switch sslmode {
+	case "allow":
+		return []*gmtls.Config{nil, tlcpConfig}, nil
+	case "prefer":
+		return []*gmtls.Config{tlcpConfig, nil}, nil
+	default:
+		return []*gmtls.Config{tlcpConfig}, nil
+	}
+}
+
+func makeTLCPVerifyCAFunc(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(certificates [][]byte, _ [][]*x509.Certificate) error {
+		certs := make([]*x509.Certificate, len(certificates))
+		for i, asn1Data := range certificates {
+			cert, err := x509.ParseCertificate(asn1Data)
+			if err != nil {
+				return errors.New("failed to parse certificate from server: " + err.Error())
+			}
+			certs[i] = cert
+		}
+		if len(certs) == 0 {
+			return errors.New("server did not present a certificate")
+		}
+
+		// Leave DNSName empty to skip hostname verification.
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(opts)
+		return err
+	}
+}
+
+// loadSM2KeyPair reads a PEM encoded certificate chain and a PKCS #8 SM2 private key which is decrypted with password
+// if it is encrypted. Like configTLS it falls back to the password returned by getSSLPassword if password is empty or
+// fails to decrypt the key.
+func loadSM2KeyPair(certFile, keyFile, password string, getSSLPassword GetSSLPasswordFunc) (gmtls.Certificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return gmtls.Certificate{}, fmt.Errorf("unable to read cert: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return gmtls.Certificate{}, fmt.Errorf("unable to read sslkey: %w", err)
+	}
+
+	var cert gmtls.Certificate
+	for {
+		var block *pem.Block
+		block, certPEM = pem.Decode(certPEM)
+		if block == nil {
+			break
+		}
+		if block.Type == "CERTIFICATE" {
+			cert.Certificate = append(cert.Certificate, block.Bytes)
+		}
+	}
+	if len(cert.Certificate) == 0 {
+		return gmtls.Certificate{}, fmt.Errorf("unable to load cert: no certificate found in %s", certFile)
+	}
+
+	var pwd []byte
+	encrypted := false
+	if block, _ := pem.Decode(keyPEM); block != nil && block.Type == "ENCRYPTED PRIVATE KEY" {
+		encrypted = true
+		if password == "" && getSSLPassword != nil {
+			password = getSSLPassword(context.Background())
+		}
+		if password == "" {
+			return gmtls.Certificate{}, errors.New("unable to find sslpassword")
+		}
+		pwd = []byte(password)
+	}
+	key, err := x509.ReadPrivateKeyFromPem(keyPEM, pwd)
+	if err != nil && encrypted && getSSLPassword != nil {
+		// password was given but did not decrypt the key.
+		if callbackPassword := getSSLPassword(context.Background()); callbackPassword != "" && callbackPassword != password {
+			key, err = x509.ReadPrivateKeyFromPem(keyPEM, []byte(callbackPassword))
+		}
+	}
+	if err != nil {
+		return gmtls.Certificate{}, fmt.Errorf("unable to load SM2 key: %w", err)
+	}
+	cert.PrivateKey = key
+
+	return cert, nil
+}
+
+// startTLCP requests TLS like startTLS and then performs a TLCP handshake.
+func startTLCP(conn net.Conn, tlcpConfig *gmtls.Config) (net.Conn, error) {
+	if err := requestSSL(conn); err != nil {
+		return nil, err
+	}
+
+	tlcpConn := gmtls.Client(conn, tlcpConfig)
+	if err := tlcpConn.Handshake(); err != nil {
+		return nil, err
+	}
+	return tlcpConn, nil
+}
@@ -0,0 +1,150 @@
+package gaussdbproto
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/gaussdbio"
+)
+
+const (
+	sm3Random64CodeLen    = 64
+	sm3TokenLen           = 8
+	sm3ServerSignatureLen = 64
+)
+
+// AuthenticationSM3 is a message sent from the backend indicating that SM3 hashed password authentication is required.
+//
+// Random64Code, Token, Iteration and ServerSignature are only present when the password is stored in plain or SM3
+// form. ServerSignature is optional and may be empty.
+type AuthenticationSM3 struct {
+	PasswordStoredMethod uint32
+	Random64Code         []byte
+	Token                []byte
+	Iteration            uint32
+	ServerSignature      []byte
+}
+
+// Backend identifies this message as sendable by the GaussDB backend.
+func (*AuthenticationSM3) Backend() {}
+
+// AuthenticationResponse identifies this message as an authentication response.
+func (*AuthenticationSM3) AuthenticationResponse() {}
+
+// Decode decodes src into dst. src must contain the complete message with the exception of the initial 1 byte message
+// type identifier and 4 byte message length.
+func (dst *AuthenticationSM3) Decode(src []byte) error {
+	if len(src) < 8 {
+		return errors.New("authentication message too short")
+	}
+
+	authType := binary.BigEndian.Uint32(src)
+
+	if authType != AuthTypeSM3 {
+		return errors.New("bad auth type")
+	}
+
+	*dst = AuthenticationSM3{PasswordStoredMethod: binary.BigEndian.Uint32(src[4:])}
+	rp := 8
+	if len(src) == rp {
+		return nil
+	}
+
+	if len(src) < rp+sm3Random64CodeLen+sm3TokenLen+4 {
+		return &invalidMessageFormatErr{messageType: "AuthenticationSM3", details: "too short"}
+	}
+
+	dst.Random64Code = src[rp : rp+sm3Random64CodeLen]
+	rp += sm3Random64CodeLen
+	dst.Token = src[rp : rp+sm3TokenLen]
+	rp += sm3TokenLen
+	dst.Iteration = binary.BigEndian.Uint32(src[rp:])
+	rp += 4
+
+	switch len(src) - rp {
+	case 0:
+	case sm3ServerSignatureLen:
+		dst.ServerSignature = src[rp:]
+	default:
+		return &invalidMessageFormatErr{messageType: "AuthenticationSM3", details: "bad server signature length"}
+	}
+
+	return nil
+}
+
+// Encode encodes src into dst. dst will include the 1 byte message type identifier and the 4 byte message length.
+func (src *AuthenticationSM3) Encode(dst []byte) ([]byte, error) {
+	dst, sp := beginMessage(dst, 'R')
+	dst = gaussdbio.AppendUint32(dst, AuthTypeSM3)
+	dst = gaussdbio.AppendUint32(dst, src.PasswordStoredMethod)
+
+	if src.Random64Code != nil || src.Token != nil {
+		if len(src.Random64Code) != sm3Random64CodeLen {
+			return nil, errors.New("random64code must be 64 bytes")
+		}
+		if len(src.Token) != sm3TokenLen {
+			return nil, errors.New("token must be 8 bytes")
+		}
+		dst = append(dst, src.Random64Code...)
+		dst = append(dst, src.Token...)
+		dst = gaussdbio.AppendUint32(dst, src.Iteration)
+
+		if src.ServerSignature != nil {
+			if len(src.ServerSignature) != sm3ServerSignatureLen {
+				return nil, errors.New("server signature must be 64 bytes")
+			}
+			dst = append(dst, src.ServerSignature...)
+		}
+	}
+
+	return finishMessage(dst, sp)
+}
+
+// MarshalJSON implements encoding/json.Marshaler.
+func (src AuthenticationSM3) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type                 string
+		PasswordStoredMethod uint32
+		Random64Code         string
+		Token                string
+		Iteration            uint32
+		ServerSignature      string
+	}{
+		Type:                 "AuthenticationSM3",
+		PasswordStoredMethod: src.PasswordStoredMethod,
+		Random64Code:         string(src.Random64Code),
+		Token:                string(src.Token),
+		Iteration:            src.Iteration,
+		ServerSignature:      string(src.ServerSignature),
+	})
+}
+
+// UnmarshalJSON implements encoding/json.Unmarshaler.
+func (dst *AuthenticationSM3) UnmarshalJSON(data []byte) error {
+	// Ignore null, like in the main JSON package.
+	if string(data) == "null" {
+		return nil
+	}
+
+	var msg struct {
+		PasswordStoredMethod uint32
+		Random64Code         string
+		Token                string
+		Iteration            uint32
+		ServerSignature      string
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+
+	*dst = AuthenticationSM3{PasswordStoredMethod: msg.PasswordStoredMethod, Iteration: msg.Iteration}
+	if msg.Random64Code != "" || msg.Token != "" {
+		dst.Random64Code = []byte(msg.Random64Code)
+		dst.Token = []byte(msg.Token)
+	}
+	if msg.ServerSignature != "" {
+		dst.ServerSignature = []byte(msg.ServerSignature)
+	}
+	return nil
+}
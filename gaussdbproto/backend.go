@@ -279,7 +279,9 @@ func (b *Backend) SetAuthType(authType uint32) error {
 		AuthTypeSSPI,
 		AuthTypeSASL,
 		AuthTypeSASLContinue,
-		AuthTypeSASLFinal:
+		AuthTypeSASLFinal,
+		AuthTypeSHA256,
+		AuthTypeSM3:
 		b.authType = authType
 	default:
 		return fmt.Errorf("authType not recognized: %d", authType)
@@ -29,6 +29,7 @@ type Frontend struct {
 	authenticationGSSContinue       AuthenticationGSSContinue
 	authenticationSASL              AuthenticationSASL
 	authenticationSHA256            AuthenticationSHA256
+	authenticationSM3               AuthenticationSM3
 	authenticationSASLContinue      AuthenticationSASLContinue
 	authenticationSASLFinal         AuthenticationSASLFinal
 	backendKeyData                  BackendKeyData
@@ -424,6 +425,7 @@ const (
 	AuthTypeSHA256            = 10
 	AuthTypeSASLContinue      = 11
 	AuthTypeSASLFinal         = 12
+	AuthTypeSM3               = 13
 )
 
 func (f *Frontend) findAuthenticationMessageType(src []byte) (BackendMessage, error) {
@@ -455,6 +457,8 @@ func (f *Frontend) findAuthenticationMessageType(src []byte) (BackendMessage, er
 		return &f.authenticationSASLContinue, nil
 	case AuthTypeSASLFinal:
 		return &f.authenticationSASLFinal, nil
+	case AuthTypeSM3:
+		return &f.authenticationSM3, nil
 	default:
 		return nil, fmt.Errorf("unknown authentication type: %d", f.authType)
 	}
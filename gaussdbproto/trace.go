@@ -47,6 +47,8 @@ func (t *tracer) traceMessage(sender byte, encodedLen int32, msg Message) {
 		t.traceAuthenticationSASLContinue(sender, encodedLen, msg)
 	case *AuthenticationSASLFinal:
 		t.traceAuthenticationSASLFinal(sender, encodedLen, msg)
+	case *AuthenticationSM3:
+		t.traceAuthenticationSM3(sender, encodedLen, msg)
 	case *BackendKeyData:
 		t.traceBackendKeyData(sender, encodedLen, msg)
 	case *Bind:
@@ -158,6 +160,10 @@ func (t *tracer) traceAuthenticationSASLFinal(sender byte, encodedLen int32, msg
 	t.writeTrace(sender, encodedLen, "AuthenticationSASLFinal", nil)
 }
 
+func (t *tracer) traceAuthenticationSM3(sender byte, encodedLen int32, msg *AuthenticationSM3) {
+	t.writeTrace(sender, encodedLen, "AuthenticationSM3", nil)
+}
+
 func (t *tracer) traceBackendKeyData(sender byte, encodedLen int32, msg *BackendKeyData) {
 	t.writeTrace(sender, encodedLen, "BackendKeyData", func() {
 		if t.RegressMode {
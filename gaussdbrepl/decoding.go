@@ -0,0 +1,297 @@
+package gaussdbrepl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbtype"
+)
+
+// MessageType is the type of a change event decoded from the output of a logical decoding plugin.
+type MessageType uint8
+
+const (
+	MessageTypeBegin MessageType = iota + 1
+	MessageTypeCommit
+	MessageTypeInsert
+	MessageTypeUpdate
+	MessageTypeDelete
+)
+
+func (t MessageType) String() string {
+	switch t {
+	case MessageTypeBegin:
+		return "Begin"
+	case MessageTypeCommit:
+		return "Commit"
+	case MessageTypeInsert:
+		return "Insert"
+	case MessageTypeUpdate:
+		return "Update"
+	case MessageTypeDelete:
+		return "Delete"
+	default:
+		return "Unknown"
+	}
+}
+
+// Message is a change event decoded by ParseMppdbDecodingMessage or ParseTestDecodingMessage. It is one of
+// *BeginMessage, *CommitMessage, *InsertMessage, *UpdateMessage or *DeleteMessage.
+type Message interface {
+	Type() MessageType
+}
+
+// BeginMessage is the start of a transaction. Xid is 0 unless the plugin was started with include-xids.
+type BeginMessage struct {
+	Xid uint64
+}
+
+func (*BeginMessage) Type() MessageType { return MessageTypeBegin }
+
+// CommitMessage is the end of a transaction. Xid is 0 unless the plugin was started with include-xids. CommitTime is
+// zero unless the plugin was started with include-timestamp. CSN is 0 unless the server reports it.
+type CommitMessage struct {
+	Xid        uint64
+	CommitTime time.Time
+	CSN        uint64
+}
+
+func (*CommitMessage) Type() MessageType { return MessageTypeCommit }
+
+// Column is a column value of a changed row.
+type Column struct {
+	Name     string
+	TypeName string // type name as written by the plugin, e.g. "character varying"
+
+	// Value is the column value converted with the gaussdbtype.Map passed to the parse function. It is nil for NULL.
+	// Values of types unknown to the map are strings.
+	Value any
+
+	// Unchanged is true for a TOAST value that was not changed by an update. Its value is not available.
+	Unchanged bool
+}
+
+// Tuple is the columns of a row in the order written by the plugin.
+type Tuple []Column
+
+// Get returns the column named name.
+func (t Tuple) Get(name string) (Column, bool) {
+	for _, c := range t {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// Values returns the values of the tuple mapped by column name.
+func (t Tuple) Values() map[string]any {
+	values := make(map[string]any, len(t))
+	for _, c := range t {
+		values[c.Name] = c.Value
+	}
+	return values
+}
+
+// InsertMessage is an inserted row.
+type InsertMessage struct {
+	Schema string
+	Table  string
+	New    Tuple
+}
+
+func (*InsertMessage) Type() MessageType { return MessageTypeInsert }
+
+// UpdateMessage is an updated row. Old contains the replica identity columns of the old row if the server included
+// them. It is nil otherwise.
+type UpdateMessage struct {
+	Schema string
+	Table  string
+	Old    Tuple
+	New    Tuple
+}
+
+func (*UpdateMessage) Type() MessageType { return MessageTypeUpdate }
+
+// DeleteMessage is a deleted row. Old contains the replica identity columns of the deleted row. It is nil if the table
+// has no replica identity.
+type DeleteMessage struct {
+	Schema string
+	Table  string
+	Old    Tuple
+}
+
+func (*DeleteMessage) Type() MessageType { return MessageTypeDelete }
+
+// parseTxnMessage parses the BEGIN and COMMIT lines written by both mppdb_decoding and test_decoding, e.g.
+// "BEGIN 529" or "COMMIT 529 (at 2024-03-01 12:30:00.123456+08) CSN 2043". It returns nil if s is neither.
+func parseTxnMessage(s string, typeMap *gaussdbtype.Map) (Message, error) {
+	keyword, rest, _ := strings.Cut(s, " ")
+	if keyword != "BEGIN" && keyword != "COMMIT" {
+		return nil, nil
+	}
+
+	var xid, csn uint64
+	var commitTime time.Time
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimSpace(rest) {
+		var token string
+		switch {
+		case strings.HasPrefix(rest, "(at "):
+			end := strings.IndexByte(rest, ')')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated commit timestamp in %q", s)
+			}
+			v, err := decodeTextValue(typeMap, "timestamp with time zone", rest[len("(at "):end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid commit timestamp in %q: %w", s, err)
+			}
+			if t, ok := v.(time.Time); ok {
+				commitTime = t
+			}
+			rest = rest[end+1:]
+			continue
+		case strings.HasPrefix(rest, "CSN"):
+			token, rest, _ = strings.Cut(strings.TrimLeft(rest[len("CSN"):], ": "), " ")
+			n, err := strconv.ParseUint(token, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CSN in %q: %w", s, err)
+			}
+			csn = n
+			continue
+		case strings.HasPrefix(rest, "XID"):
+			rest = strings.TrimLeft(rest[len("XID"):], ": ")
+		}
+
+		token, rest, _ = strings.Cut(rest, " ")
+		n, err := strconv.ParseUint(token, 10, 64)
+		if err != nil {
+			// Ignore anything else a server version may add.
+			continue
+		}
+		xid = n
+	}
+
+	if keyword == "BEGIN" {
+		return &BeginMessage{Xid: xid}, nil
+	}
+	return &CommitMessage{Xid: xid, CommitTime: commitTime, CSN: csn}, nil
+}
+
+// typeNameAliases maps the SQL standard type names written by the plugins to the names registered in gaussdbtype.
+var typeNameAliases = map[string]string{
+	"smallint":                    "int2",
+	"integer":                     "int4",
+	"bigint":                      "int8",
+	"real":                        "float4",
+	"double precision":            "float8",
+	"boolean":                     "bool",
+	"character varying":           "varchar",
+	"character":                   "bpchar",
+	`"char"`:                      "char",
+	"bit varying":                 "varbit",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+	"time without time zone":      "time",
+	"decimal":                     "numeric",
+}
+
+// typeForName looks up the gaussdbtype.Type for a type name written by a plugin.
+func typeForName(typeMap *gaussdbtype.Map, typeName string) (*gaussdbtype.Type, bool) {
+	name := typeName
+	isArray := strings.HasSuffix(name, "[]")
+	if isArray {
+		name = strings.TrimSuffix(name, "[]")
+	}
+	// Drop a type modifier such as the length of character varying(10).
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		if j := strings.IndexByte(name[i:], ')'); j >= 0 {
+			name = strings.TrimSpace(name[:i] + name[i+j+1:])
+		}
+	}
+	if alias, ok := typeNameAliases[name]; ok {
+		name = alias
+	}
+	if isArray {
+		name = "_" + name
+	}
+
+	return typeMap.TypeForName(name)
+}
+
+// decodeTextValue converts the text representation of a value of typeName with typeMap. Values of unknown types are
+// returned as strings.
+func decodeTextValue(typeMap *gaussdbtype.Map, typeName string, s string) (any, error) {
+	typ, ok := typeForName(typeMap, typeName)
+	if !ok {
+		return s, nil
+	}
+
+	return typ.Codec.DecodeValue(typeMap, typ.OID, gaussdbtype.TextFormatCode, []byte(s))
+}
+
+// decodeLiteral converts a value as written by the plugins. NULL is written as null and non-numeric values are
+// single quoted with embedded quotes doubled.
+func decodeLiteral(typeMap *gaussdbtype.Map, typeName string, literal string) (any, error) {
+	if literal == "null" {
+		return nil, nil
+	}
+
+	s := literal
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		s = strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+
+	v, err := decodeTextValue(typeMap, typeName, s)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode %s value %s: %w", typeName, literal, err)
+	}
+	return v, nil
+}
+
+// parseIdentifier parses a possibly double quoted identifier at the start of s and returns the rest of s.
+func parseIdentifier(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		end := strings.IndexAny(s, ".[: ")
+		if end < 0 {
+			end = len(s)
+		}
+		if end == 0 {
+			return "", "", errors.New("missing identifier")
+		}
+		return s[:end], s[end:], nil
+	}
+
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		if s[i] == '"' {
+			if i+1 < len(s) && s[i+1] == '"' {
+				sb.WriteByte('"')
+				i++
+				continue
+			}
+			return sb.String(), s[i+1:], nil
+		}
+		sb.WriteByte(s[i])
+	}
+	return "", "", errors.New("unterminated quoted identifier")
+}
+
+// parseQualifiedName parses a schema qualified table name like public.t or "My Schema"."My Table" at the start of s
+// and returns the rest of s.
+func parseQualifiedName(s string) (schema, table, rest string, err error) {
+	table, rest, err = parseIdentifier(s)
+	if err != nil {
+		return "", "", "", err
+	}
+	if strings.HasPrefix(rest, ".") {
+		schema = table
+		table, rest, err = parseIdentifier(rest[1:])
+		if err != nil {
+			return "", "", "", err
+		}
+	}
+	return schema, table, rest, nil
+}
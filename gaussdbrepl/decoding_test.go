@@ -0,0 +1,131 @@
+package gaussdbrepl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbrepl"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbtype"
+)
+
+func TestParseMppdbDecodingMessage(t *testing.T) {
+	t.Parallel()
+
+	m := gaussdbtype.NewMap()
+
+	msg, err := gaussdbrepl.ParseMppdbDecodingMessage([]byte("BEGIN 15942"), m)
+	require.NoError(t, err)
+	assert.Equal(t, &gaussdbrepl.BeginMessage{Xid: 15942}, msg)
+
+	msg, err = gaussdbrepl.ParseMppdbDecodingMessage([]byte(`{"table_name":"public.t1","op_type":"INSERT","columns_name":["id","name","created_at","active","note"],"columns_type":["integer","character varying","timestamp without time zone","boolean","text"],"columns_val":["1","'it''s'","'2024-03-01 12:30:00'","true","null"],"old_keys_name":[],"old_keys_type":[],"old_keys_val":[]}`), m)
+	require.NoError(t, err)
+	insert, ok := msg.(*gaussdbrepl.InsertMessage)
+	require.True(t, ok)
+	assert.Equal(t, "public", insert.Schema)
+	assert.Equal(t, "t1", insert.Table)
+	assert.Equal(t, map[string]any{
+		"id":         int32(1),
+		"name":       "it's",
+		"created_at": time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC),
+		"active":     true,
+		"note":       nil,
+	}, insert.New.Values())
+	name, ok := insert.New.Get("name")
+	require.True(t, ok)
+	assert.Equal(t, "character varying", name.TypeName)
+
+	msg, err = gaussdbrepl.ParseMppdbDecodingMessage([]byte(`{"table_name":"\"My Schema\".\"T\"","op_type":"UPDATE","columns_name":["id","name"],"columns_type":["bigint","text"],"columns_val":["2","'b'"],"old_keys_name":["id"],"old_keys_type":["bigint"],"old_keys_val":["1"]}`), m)
+	require.NoError(t, err)
+	update, ok := msg.(*gaussdbrepl.UpdateMessage)
+	require.True(t, ok)
+	assert.Equal(t, "My Schema", update.Schema)
+	assert.Equal(t, "T", update.Table)
+	assert.Equal(t, map[string]any{"id": int64(1)}, update.Old.Values())
+	assert.Equal(t, map[string]any{"id": int64(2), "name": "b"}, update.New.Values())
+
+	msg, err = gaussdbrepl.ParseMppdbDecodingMessage([]byte(`{"table_name":"public.t1","op_type":"DELETE","columns_name":[],"columns_type":[],"columns_val":[],"old_keys_name":["id"],"old_keys_type":["integer"],"old_keys_val":["2"]}`), m)
+	require.NoError(t, err)
+	del, ok := msg.(*gaussdbrepl.DeleteMessage)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"id": int32(2)}, del.Old.Values())
+
+	msg, err = gaussdbrepl.ParseMppdbDecodingMessage([]byte("COMMIT 15942 (at 2024-03-01 12:30:00.5+00) CSN 2043"), m)
+	require.NoError(t, err)
+	commit, ok := msg.(*gaussdbrepl.CommitMessage)
+	require.True(t, ok)
+	assert.EqualValues(t, 15942, commit.Xid)
+	assert.EqualValues(t, 2043, commit.CSN)
+	assert.True(t, commit.CommitTime.Equal(time.Date(2024, time.March, 1, 12, 30, 0, 500000000, time.UTC)))
+
+	_, err = gaussdbrepl.ParseMppdbDecodingMessage([]byte(`{"table_name":"public.t1","op_type":"INSERT","columns_name":["id"],"columns_type":["integer"],"columns_val":[]}`), m)
+	require.ErrorContains(t, err, "column count mismatch")
+
+	_, err = gaussdbrepl.ParseMppdbDecodingMessage([]byte(`{"table_name":"public.t1","op_type":"INSERT","columns_name":["id"],"columns_type":["integer"],"columns_val":["'x'"]}`), m)
+	require.ErrorContains(t, err, "column id")
+}
+
+func TestParseTestDecodingMessage(t *testing.T) {
+	t.Parallel()
+
+	m := gaussdbtype.NewMap()
+
+	msg, err := gaussdbrepl.ParseTestDecodingMessage([]byte("BEGIN"), m)
+	require.NoError(t, err)
+	assert.Equal(t, &gaussdbrepl.BeginMessage{}, msg)
+
+	msg, err = gaussdbrepl.ParseTestDecodingMessage([]byte(`table public.data: INSERT: id[integer]:1 data[text]:'hello world' "Tags"[text[]]:'{a,b}' price[numeric]:1.50 deleted[boolean]:false`), m)
+	require.NoError(t, err)
+	insert, ok := msg.(*gaussdbrepl.InsertMessage)
+	require.True(t, ok)
+	assert.Equal(t, "public", insert.Schema)
+	assert.Equal(t, "data", insert.Table)
+	require.Len(t, insert.New, 5)
+	assert.Equal(t, int32(1), insert.New[0].Value)
+	assert.Equal(t, "hello world", insert.New[1].Value)
+	assert.Equal(t, "Tags", insert.New[2].Name)
+	assert.Equal(t, "text[]", insert.New[2].TypeName)
+	assert.Equal(t, []any{"a", "b"}, insert.New[2].Value)
+	price, ok := insert.New[3].Value.(gaussdbtype.Numeric)
+	require.True(t, ok)
+	priceFloat, err := price.Float64Value()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, priceFloat.Float64)
+	assert.Equal(t, false, insert.New[4].Value)
+
+	msg, err = gaussdbrepl.ParseTestDecodingMessage([]byte(`table public.data: UPDATE: old-key: id[integer]:1 new-tuple: id[integer]:2 data[text]:null body[text]:unchanged-toast-datum`), m)
+	require.NoError(t, err)
+	update, ok := msg.(*gaussdbrepl.UpdateMessage)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{"id": int32(1)}, update.Old.Values())
+	require.Len(t, update.New, 3)
+	assert.Equal(t, int32(2), update.New[0].Value)
+	assert.Nil(t, update.New[1].Value)
+	assert.False(t, update.New[1].Unchanged)
+	assert.True(t, update.New[2].Unchanged)
+
+	msg, err = gaussdbrepl.ParseTestDecodingMessage([]byte(`table public.data: UPDATE: id[integer]:3 data[text]:'it''s'`), m)
+	require.NoError(t, err)
+	update, ok = msg.(*gaussdbrepl.UpdateMessage)
+	require.True(t, ok)
+	assert.Nil(t, update.Old)
+	assert.Equal(t, map[string]any{"id": int32(3), "data": "it's"}, update.New.Values())
+
+	msg, err = gaussdbrepl.ParseTestDecodingMessage([]byte(`table public.data: DELETE: (no-tuple-data)`), m)
+	require.NoError(t, err)
+	del, ok := msg.(*gaussdbrepl.DeleteMessage)
+	require.True(t, ok)
+	assert.Nil(t, del.Old)
+
+	msg, err = gaussdbrepl.ParseTestDecodingMessage([]byte("COMMIT 529"), m)
+	require.NoError(t, err)
+	assert.Equal(t, &gaussdbrepl.CommitMessage{Xid: 529}, msg)
+
+	_, err = gaussdbrepl.ParseTestDecodingMessage([]byte(`table public.data: INSERT: data[text]:'unterminated`), m)
+	require.ErrorContains(t, err, "unterminated quoted value")
+
+	_, err = gaussdbrepl.ParseTestDecodingMessage([]byte(`table public.data: TRUNCATE: (no-flags)`), m)
+	require.ErrorContains(t, err, "unsupported test_decoding action")
+}
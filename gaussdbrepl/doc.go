@@ -0,0 +1,71 @@
+// Package gaussdbrepl implements the GaussDB streaming replication protocol.
+/*
+gaussdbrepl is built on gaussdbconn. It sends replication commands on a replication connection and parses the
+messages the server sends while streaming.
+
+Establishing a Connection
+
+Replication commands are only accepted on a connection with the replication startup parameter. Use Connect to establish
+a logical replication (replication=database) connection:
+
+    conn, err := gaussdbrepl.Connect(context.Background(), os.Getenv("DATABASE_URL"))
+
+Streaming Changes
+
+Create a slot with CreateReplicationSlot and start streaming from it with StartReplication. The server then sends
+CopyData messages that are received with GaussdbConn.ReceiveMessage. The first byte of the data identifies the message:
+
+    msg, err := conn.ReceiveMessage(ctx)
+    if err != nil {
+        // ...
+    }
+    copyData, ok := msg.(*gaussdbproto.CopyData)
+    if !ok {
+        // ...
+    }
+    switch copyData.Data[0] {
+    case gaussdbrepl.PrimaryKeepaliveMessageByteID:
+        pkm, err := gaussdbrepl.ParsePrimaryKeepaliveMessage(copyData.Data[1:])
+        // ...
+    case gaussdbrepl.XLogDataByteID:
+        xld, err := gaussdbrepl.ParseXLogData(copyData.Data[1:])
+        // xld.WALData holds the output of the decoding plugin.
+    }
+
+Progress must be reported with SendStandbyStatusUpdate regularly and whenever a keepalive message requests a reply.
+Otherwise the server considers the client dead and terminates the connection. The server only discards WAL up to the
+reported flush position.
+
+Physical Replication
+
+ConnectPhysical establishes a physical replication (replication=true) connection. BaseBackup streams a base backup as
+one tar archive per tablespace. WALReceiver streams WAL into segments, follows timeline switches and reports its
+progress to the server:
+
+    receiver := &gaussdbrepl.WALReceiver{
+        Conn:     conn,
+        SlotName: "backup_slot",
+        OpenSegment: func(timeline int32, segmentStart gaussdbrepl.LSN) (io.WriteCloser, error) {
+            name := gaussdbrepl.WALSegmentFileName(timeline, segmentStart, gaussdbrepl.DefaultWALSegmentSize)
+            return os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+        },
+    }
+    lsn, err := receiver.Receive(ctx, startLSN)
+    // On error, reconnect and call Receive with lsn to resume.
+
+Decoding Changes
+
+ParseMppdbDecodingMessage and ParseTestDecodingMessage turn the WALData written by the mppdb_decoding and test_decoding
+plugins into a Message: *BeginMessage, *CommitMessage, *InsertMessage, *UpdateMessage or *DeleteMessage. Column values
+are converted from text by the gaussdbtype.Map using the column type names:
+
+    msg, err := gaussdbrepl.ParseMppdbDecodingMessage(xld.WALData, typeMap)
+    if err != nil {
+        // ...
+    }
+    switch msg := msg.(type) {
+    case *gaussdbrepl.InsertMessage:
+        fmt.Println(msg.Schema, msg.Table, msg.New.Values())
+    }
+*/
+package gaussdbrepl
@@ -0,0 +1,475 @@
+package gaussdbrepl
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+)
+
+const (
+	XLogDataByteID                = 'w'
+	PrimaryKeepaliveMessageByteID = 'k'
+	StandbyStatusUpdateByteID     = 'r'
+)
+
+// ReplicationMode is the kind of replication slot or stream.
+type ReplicationMode int
+
+const (
+	LogicalReplication ReplicationMode = iota
+	PhysicalReplication
+)
+
+// String formats the mode as used in replication commands.
+func (mode ReplicationMode) String() string {
+	if mode == PhysicalReplication {
+		return "PHYSICAL"
+	}
+	return "LOGICAL"
+}
+
+// LSN is a GaussDB Log Sequence Number. See https://www.postgresql.org/docs/current/datatype-pg-lsn.html.
+type LSN uint64
+
+// String formats the LSN value into the XXX/XXX format which is the text format used by GaussDB.
+func (lsn LSN) String() string {
+	return fmt.Sprintf("%X/%X", uint32(lsn>>32), uint32(lsn))
+}
+
+func (lsn *LSN) decodeText(src string) error {
+	lsnValue, err := ParseLSN(src)
+	if err != nil {
+		return err
+	}
+	*lsn = lsnValue
+
+	return nil
+}
+
+// Scan implements the Scanner interface.
+func (lsn *LSN) Scan(src any) error {
+	if lsn == nil {
+		return nil
+	}
+
+	switch v := src.(type) {
+	case uint64:
+		*lsn = LSN(v)
+	case string:
+		if err := lsn.decodeText(v); err != nil {
+			return err
+		}
+	case []byte:
+		if err := lsn.decodeText(string(v)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("can not scan %T to LSN", src)
+	}
+
+	return nil
+}
+
+// Value implements the driver.Valuer interface.
+func (lsn LSN) Value() (driver.Value, error) {
+	return driver.Value(lsn.String()), nil
+}
+
+// ParseLSN parses the given XXX/XXX text format LSN used by GaussDB.
+func ParseLSN(s string) (LSN, error) {
+	upperHalfStr, lowerHalfStr, found := strings.Cut(s, "/")
+	if !found {
+		return 0, fmt.Errorf("failed to parse LSN %q", s)
+	}
+	upperHalf, err := strconv.ParseUint(upperHalfStr, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse LSN %q: %w", s, err)
+	}
+	lowerHalf, err := strconv.ParseUint(lowerHalfStr, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse LSN %q: %w", s, err)
+	}
+
+	return LSN(upperHalf<<32 | lowerHalf), nil
+}
+
+// Connect establishes a logical replication connection to the database specified by connString. The replication
+// startup parameter is set to database. Only the simple query protocol may be used on the returned connection.
+func Connect(ctx context.Context, connString string) (*gaussdbconn.GaussdbConn, error) {
+	return connect(ctx, connString, "database")
+}
+
+func connect(ctx context.Context, connString string, replication string) (*gaussdbconn.GaussdbConn, error) {
+	config, err := gaussdbconn.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	config.RuntimeParams["replication"] = replication
+
+	return gaussdbconn.ConnectConfig(ctx, config)
+}
+
+// IdentifySystemResult is the parsed result of the IDENTIFY_SYSTEM command.
+type IdentifySystemResult struct {
+	SystemID string
+	Timeline int32
+	XLogPos  LSN
+	DBName   string // empty unless the connection is a logical replication connection
+}
+
+// IdentifySystem executes the IDENTIFY_SYSTEM command.
+func IdentifySystem(ctx context.Context, conn *gaussdbconn.GaussdbConn) (IdentifySystemResult, error) {
+	return ParseIdentifySystem(conn.Exec(ctx, "IDENTIFY_SYSTEM"))
+}
+
+// ParseIdentifySystem parses the result of the IDENTIFY_SYSTEM command.
+func ParseIdentifySystem(mrr *gaussdbconn.MultiResultReader) (IdentifySystemResult, error) {
+	var isr IdentifySystemResult
+	results, err := mrr.ReadAll()
+	if err != nil {
+		return isr, err
+	}
+
+	if len(results) != 1 {
+		return isr, fmt.Errorf("expected 1 result set, got %d", len(results))
+	}
+
+	result := results[0]
+	if len(result.Rows) != 1 {
+		return isr, fmt.Errorf("expected 1 result row, got %d", len(result.Rows))
+	}
+
+	row := result.Rows[0]
+	if len(row) < 3 {
+		return isr, fmt.Errorf("expected at least 3 result columns, got %d", len(row))
+	}
+
+	isr.SystemID = string(row[0])
+	timeline, err := strconv.ParseInt(string(row[1]), 10, 32)
+	if err != nil {
+		return isr, fmt.Errorf("failed to parse timeline: %w", err)
+	}
+	isr.Timeline = int32(timeline)
+
+	isr.XLogPos, err = ParseLSN(string(row[2]))
+	if err != nil {
+		return isr, fmt.Errorf("failed to parse xlogpos as LSN: %w", err)
+	}
+
+	if len(row) > 3 {
+		isr.DBName = string(row[3])
+	}
+
+	return isr, nil
+}
+
+// CreateReplicationSlotOptions are the options for the CREATE_REPLICATION_SLOT command.
+type CreateReplicationSlotOptions struct {
+	Temporary      bool
+	SnapshotAction string // e.g. EXPORT_SNAPSHOT, NOEXPORT_SNAPSHOT or USE_SNAPSHOT. Omitted if empty.
+	Mode           ReplicationMode
+}
+
+// CreateReplicationSlotResult is the parsed result of the CREATE_REPLICATION_SLOT command.
+type CreateReplicationSlotResult struct {
+	SlotName        string
+	ConsistentPoint string
+	SnapshotName    string
+	OutputPlugin    string
+}
+
+// CreateReplicationSlot creates a replication slot. outputPlugin is the logical decoding plugin, e.g. mppdb_decoding.
+// It is ignored for physical slots.
+func CreateReplicationSlot(
+	ctx context.Context,
+	conn *gaussdbconn.GaussdbConn,
+	slotName string,
+	outputPlugin string,
+	options CreateReplicationSlotOptions,
+) (CreateReplicationSlotResult, error) {
+	var sb strings.Builder
+	sb.WriteString("CREATE_REPLICATION_SLOT ")
+	sb.WriteString(slotName)
+	if options.Temporary {
+		sb.WriteString(" TEMPORARY")
+	}
+	sb.WriteString(" ")
+	sb.WriteString(options.Mode.String())
+	if options.Mode == LogicalReplication {
+		sb.WriteString(" ")
+		sb.WriteString(outputPlugin)
+	}
+	if options.SnapshotAction != "" {
+		sb.WriteString(" ")
+		sb.WriteString(options.SnapshotAction)
+	}
+
+	return ParseCreateReplicationSlot(conn.Exec(ctx, sb.String()))
+}
+
+// ParseCreateReplicationSlot parses the result of the CREATE_REPLICATION_SLOT command.
+func ParseCreateReplicationSlot(mrr *gaussdbconn.MultiResultReader) (CreateReplicationSlotResult, error) {
+	var crsr CreateReplicationSlotResult
+	results, err := mrr.ReadAll()
+	if err != nil {
+		return crsr, err
+	}
+
+	if len(results) != 1 {
+		return crsr, fmt.Errorf("expected 1 result set, got %d", len(results))
+	}
+
+	result := results[0]
+	if len(result.Rows) != 1 {
+		return crsr, fmt.Errorf("expected 1 result row, got %d", len(result.Rows))
+	}
+
+	row := result.Rows[0]
+	if len(row) != 4 {
+		return crsr, fmt.Errorf("expected 4 result columns, got %d", len(row))
+	}
+
+	crsr.SlotName = string(row[0])
+	crsr.ConsistentPoint = string(row[1])
+	crsr.SnapshotName = string(row[2])
+	crsr.OutputPlugin = string(row[3])
+
+	return crsr, nil
+}
+
+// DropReplicationSlotOptions are the options for the DROP_REPLICATION_SLOT command.
+type DropReplicationSlotOptions struct {
+	Wait bool
+}
+
+// DropReplicationSlot drops a replication slot.
+func DropReplicationSlot(ctx context.Context, conn *gaussdbconn.GaussdbConn, slotName string, options DropReplicationSlotOptions) error {
+	sql := "DROP_REPLICATION_SLOT " + slotName
+	if options.Wait {
+		sql += " WAIT"
+	}
+
+	_, err := conn.Exec(ctx, sql).ReadAll()
+	return err
+}
+
+// StartReplicationOptions are the options for the START_REPLICATION command.
+type StartReplicationOptions struct {
+	Timeline   int32 // 0 means current server timeline. Only used for physical replication.
+	Mode       ReplicationMode
+	PluginArgs []string // e.g. "\"include-xids\" 'false'". Only used for logical replication.
+}
+
+// StartReplication starts streaming changes from slotName beginning at startLSN. After it returns the connection is
+// in copy both mode. Use GaussdbConn.ReceiveMessage to receive the streamed messages.
+func StartReplication(ctx context.Context, conn *gaussdbconn.GaussdbConn, slotName string, startLSN LSN, options StartReplicationOptions) error {
+	var sb strings.Builder
+	sb.WriteString("START_REPLICATION")
+	if slotName != "" {
+		sb.WriteString(" SLOT ")
+		sb.WriteString(slotName)
+	}
+	sb.WriteString(" ")
+	sb.WriteString(options.Mode.String())
+	sb.WriteString(" ")
+	sb.WriteString(startLSN.String())
+
+	if options.Mode == PhysicalReplication {
+		if options.Timeline > 0 {
+			sb.WriteString(" TIMELINE ")
+			sb.WriteString(strconv.FormatInt(int64(options.Timeline), 10))
+		}
+	} else if len(options.PluginArgs) > 0 {
+		sb.WriteString(" (")
+		sb.WriteString(strings.Join(options.PluginArgs, ", "))
+		sb.WriteString(")")
+	}
+
+	conn.Frontend().SendQuery(&gaussdbproto.Query{String: sb.String()})
+	err := conn.Frontend().Flush()
+	if err != nil {
+		return fmt.Errorf("failed to send START_REPLICATION: %w", err)
+	}
+
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to receive message: %w", err)
+		}
+
+		switch msg := msg.(type) {
+		case *gaussdbproto.CopyBothResponse:
+			return nil
+		case *gaussdbproto.ErrorResponse:
+			// Consume the ReadyForQuery that follows so the connection remains usable.
+			for {
+				msg, err := conn.ReceiveMessage(ctx)
+				if err != nil {
+					break
+				}
+				if _, ok := msg.(*gaussdbproto.ReadyForQuery); ok {
+					break
+				}
+			}
+			return gaussdbconn.ErrorResponseToGuassdbError(msg)
+		case *gaussdbproto.NoticeResponse, *gaussdbproto.ParameterStatus:
+		default:
+			return fmt.Errorf("unexpected response type: %T", msg)
+		}
+	}
+}
+
+// gaussdbEpoch is the epoch of the timestamps in replication messages.
+var gaussdbEpoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// gaussdbTimeToTime converts microseconds since gaussdbEpoch to a time.Time.
+func gaussdbTimeToTime(microsecSinceY2K int64) time.Time {
+	return gaussdbEpoch.Add(time.Duration(microsecSinceY2K) * time.Microsecond)
+}
+
+// timeToGaussdbTime converts t to microseconds since gaussdbEpoch.
+func timeToGaussdbTime(t time.Time) int64 {
+	return t.Sub(gaussdbEpoch).Microseconds()
+}
+
+// PrimaryKeepaliveMessage is a keepalive message sent by the server while streaming.
+type PrimaryKeepaliveMessage struct {
+	ServerWALEnd   LSN
+	ServerTime     time.Time
+	ReplyRequested bool
+}
+
+// ParsePrimaryKeepaliveMessage parses a keepalive message. buf must not include the leading
+// PrimaryKeepaliveMessageByteID.
+func ParsePrimaryKeepaliveMessage(buf []byte) (PrimaryKeepaliveMessage, error) {
+	var pkm PrimaryKeepaliveMessage
+	if len(buf) != 17 {
+		return pkm, fmt.Errorf("PrimaryKeepaliveMessage must be 17 bytes, got %d", len(buf))
+	}
+
+	pkm.ServerWALEnd = LSN(binary.BigEndian.Uint64(buf))
+	pkm.ServerTime = gaussdbTimeToTime(int64(binary.BigEndian.Uint64(buf[8:])))
+	pkm.ReplyRequested = buf[16] != 0
+
+	return pkm, nil
+}
+
+// XLogData is a chunk of WAL sent by the server while streaming. For logical replication WALData is the output of
+// the decoding plugin.
+type XLogData struct {
+	WALStart     LSN
+	ServerWALEnd LSN
+	ServerTime   time.Time
+	WALData      []byte
+}
+
+// ParseXLogData parses an XLogData message. buf must not include the leading XLogDataByteID. WALData is a subslice
+// of buf.
+func ParseXLogData(buf []byte) (XLogData, error) {
+	var xld XLogData
+	if len(buf) < 24 {
+		return xld, fmt.Errorf("XLogData must be at least 24 bytes, got %d", len(buf))
+	}
+
+	xld.WALStart = LSN(binary.BigEndian.Uint64(buf))
+	xld.ServerWALEnd = LSN(binary.BigEndian.Uint64(buf[8:]))
+	xld.ServerTime = gaussdbTimeToTime(int64(binary.BigEndian.Uint64(buf[16:])))
+	xld.WALData = buf[24:]
+
+	return xld, nil
+}
+
+// StandbyStatusUpdate reports the progress of the client to the server.
+type StandbyStatusUpdate struct {
+	WALWritePosition LSN       // The WAL position that has been received and written to disk.
+	WALFlushPosition LSN       // The WAL position that has been durably stored. Defaults to WALWritePosition.
+	WALApplyPosition LSN       // The WAL position that has been applied. Defaults to WALWritePosition.
+	ClientTime       time.Time // Defaults to time.Now().
+	ReplyRequested   bool      // Request the server to reply immediately with a keepalive message.
+}
+
+// SendStandbyStatusUpdate sends a standby status update to the server.
+func SendStandbyStatusUpdate(_ context.Context, conn *gaussdbconn.GaussdbConn, ssu StandbyStatusUpdate) error {
+	if ssu.WALFlushPosition == 0 {
+		ssu.WALFlushPosition = ssu.WALWritePosition
+	}
+	if ssu.WALApplyPosition == 0 {
+		ssu.WALApplyPosition = ssu.WALWritePosition
+	}
+	if ssu.ClientTime.IsZero() {
+		ssu.ClientTime = time.Now()
+	}
+
+	data := make([]byte, 0, 34)
+	data = append(data, StandbyStatusUpdateByteID)
+	data = binary.BigEndian.AppendUint64(data, uint64(ssu.WALWritePosition))
+	data = binary.BigEndian.AppendUint64(data, uint64(ssu.WALFlushPosition))
+	data = binary.BigEndian.AppendUint64(data, uint64(ssu.WALApplyPosition))
+	data = binary.BigEndian.AppendUint64(data, uint64(timeToGaussdbTime(ssu.ClientTime)))
+	if ssu.ReplyRequested {
+		data = append(data, 1)
+	} else {
+		data = append(data, 0)
+	}
+
+	conn.Frontend().Send(&gaussdbproto.CopyData{Data: data})
+	return conn.Frontend().Flush()
+}
+
+// CopyDoneResult is the next timeline reported by the server when physical replication of a timeline that is not the
+// latest timeline ends.
+type CopyDoneResult struct {
+	Timeline int32
+	LSN      LSN
+}
+
+// SendStandbyCopyDone ends streaming. It sends CopyDone and reads the remaining messages of the START_REPLICATION
+// command. The returned *CopyDoneResult is nil unless the server reported a next timeline.
+func SendStandbyCopyDone(ctx context.Context, conn *gaussdbconn.GaussdbConn) (*CopyDoneResult, error) {
+	conn.Frontend().Send(&gaussdbproto.CopyDone{})
+	err := conn.Frontend().Flush()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send CopyDone: %w", err)
+	}
+
+	var cdr *CopyDoneResult
+	var gaussdbErr error
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		switch msg := msg.(type) {
+		case *gaussdbproto.DataRow:
+			// The next timeline and the LSN where the current timeline ended.
+			if len(msg.Values) == 2 {
+				timeline, err := strconv.ParseInt(string(msg.Values[0]), 10, 32)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse timeline: %w", err)
+				}
+				lsn, err := ParseLSN(string(msg.Values[1]))
+				if err != nil {
+					return nil, err
+				}
+				cdr = &CopyDoneResult{Timeline: int32(timeline), LSN: lsn}
+			}
+		case *gaussdbproto.ErrorResponse:
+			gaussdbErr = gaussdbconn.ErrorResponseToGuassdbError(msg)
+		case *gaussdbproto.ReadyForQuery:
+			return cdr, gaussdbErr
+		case *gaussdbproto.CopyData, *gaussdbproto.CopyDone, *gaussdbproto.RowDescription, *gaussdbproto.CommandComplete,
+			*gaussdbproto.NoticeResponse, *gaussdbproto.ParameterStatus:
+			// CopyData may still be in flight when CopyDone is sent.
+		default:
+			return nil, fmt.Errorf("unexpected response type: %T", msg)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package gaussdbrepl_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbrepl"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/gaussdbmock"
+)
+
+// connectMockServer connects to a mock server running steps after a logical replication connection has been accepted.
+func connectMockServer(t *testing.T, steps ...gaussdbmock.Step) (*gaussdbconn.GaussdbConn, chan error) {
+	return connectMockServerWith(t, gaussdbrepl.Connect, "database", steps...)
+}
+
+// connectMockServerWith connects to a mock server with connect. The startup message must set the replication
+// parameter to replication.
+func connectMockServerWith(
+	t *testing.T,
+	connect func(context.Context, string) (*gaussdbconn.GaussdbConn, error),
+	replication string,
+	steps ...gaussdbmock.Step,
+) (*gaussdbconn.GaussdbConn, chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		backend := gaussdbproto.NewBackend(conn, conn)
+		startupMessage, err := backend.ReceiveStartupMessage()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		sm, ok := startupMessage.(*gaussdbproto.StartupMessage)
+		if !ok || sm.Parameters["replication"] != replication {
+			serverErrChan <- fmt.Errorf("unexpected startup message: %#v", startupMessage)
+			return
+		}
+
+		script := &gaussdbmock.Script{Steps: []gaussdbmock.Step{
+			gaussdbmock.SendMessage(&gaussdbproto.AuthenticationOk{}),
+			gaussdbmock.SendMessage(&gaussdbproto.BackendKeyData{ProcessID: 0, SecretKey: 0}),
+			gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		}}
+		script.Steps = append(script.Steps, steps...)
+		script.Steps = append(script.Steps, gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}))
+		serverErrChan <- script.Run(backend)
+	}()
+
+	_, port, _ := strings.Cut(ln.Addr().String(), ":")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := connect(ctx, fmt.Sprintf("host=127.0.0.1 port=%s sslmode=disable", port))
+	require.NoError(t, err)
+
+	return conn, serverErrChan
+}
+
+func closeConn(t *testing.T, conn *gaussdbconn.GaussdbConn, serverErrChan chan error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, conn.Close(ctx))
+	require.NoError(t, <-serverErrChan)
+}
+
+func sendResult(fields []string, values ...[]string) []gaussdbmock.Step {
+	rd := &gaussdbproto.RowDescription{}
+	for _, f := range fields {
+		rd.Fields = append(rd.Fields, gaussdbproto.FieldDescription{Name: []byte(f), DataTypeOID: 25, DataTypeSize: -1})
+	}
+
+	steps := []gaussdbmock.Step{gaussdbmock.SendMessage(rd)}
+	for _, row := range values {
+		dr := &gaussdbproto.DataRow{}
+		for _, v := range row {
+			dr.Values = append(dr.Values, []byte(v))
+		}
+		steps = append(steps, gaussdbmock.SendMessage(dr))
+	}
+	return steps
+}
+
+func TestLSN(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		s   string
+		lsn gaussdbrepl.LSN
+	}{
+		{"0/0", 0},
+		{"0/16B3748", 0x16B3748},
+		{"1/0", 1 << 32},
+		{"FFFFFFFF/FFFFFFFF", 0xFFFFFFFFFFFFFFFF},
+	} {
+		lsn, err := gaussdbrepl.ParseLSN(tt.s)
+		require.NoError(t, err)
+		assert.Equal(t, tt.lsn, lsn)
+		assert.Equal(t, tt.s, lsn.String())
+
+		var scanned gaussdbrepl.LSN
+		require.NoError(t, scanned.Scan([]byte(tt.s)))
+		assert.Equal(t, tt.lsn, scanned)
+
+		v, err := lsn.Value()
+		require.NoError(t, err)
+		assert.Equal(t, tt.s, v)
+	}
+
+	for _, s := range []string{"", "0", "G/0", "0/100000000"} {
+		_, err := gaussdbrepl.ParseLSN(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestIdentifySystem(t *testing.T) {
+	t.Parallel()
+
+	steps := []gaussdbmock.Step{gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "IDENTIFY_SYSTEM"})}
+	steps = append(steps, sendResult([]string{"systemid", "timeline", "xlogpos", "dbname"}, []string{"6940251283914473372", "1", "0/3000A18", "postgres"})...)
+	steps = append(steps,
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("IDENTIFY_SYSTEM")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	)
+	conn, serverErrChan := connectMockServer(t, steps...)
+
+	isr, err := gaussdbrepl.IdentifySystem(context.Background(), conn)
+	require.NoError(t, err)
+	assert.Equal(t, "6940251283914473372", isr.SystemID)
+	assert.EqualValues(t, 1, isr.Timeline)
+	assert.Equal(t, gaussdbrepl.LSN(0x3000A18), isr.XLogPos)
+	assert.Equal(t, "postgres", isr.DBName)
+
+	closeConn(t, conn, serverErrChan)
+}
+
+func TestCreateAndDropReplicationSlot(t *testing.T) {
+	t.Parallel()
+
+	steps := []gaussdbmock.Step{gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "CREATE_REPLICATION_SLOT test_slot TEMPORARY LOGICAL mppdb_decoding"})}
+	steps = append(steps, sendResult([]string{"slot_name", "consistent_point", "snapshot_name", "output_plugin"}, []string{"test_slot", "0/3000A50", "", "mppdb_decoding"})...)
+	steps = append(steps,
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("CREATE_REPLICATION_SLOT")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "DROP_REPLICATION_SLOT test_slot WAIT"}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("DROP_REPLICATION_SLOT")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	)
+	conn, serverErrChan := connectMockServer(t, steps...)
+
+	ctx := context.Background()
+	result, err := gaussdbrepl.CreateReplicationSlot(ctx, conn, "test_slot", "mppdb_decoding", gaussdbrepl.CreateReplicationSlotOptions{Temporary: true})
+	require.NoError(t, err)
+	assert.Equal(t, "test_slot", result.SlotName)
+	assert.Equal(t, "0/3000A50", result.ConsistentPoint)
+	assert.Equal(t, "mppdb_decoding", result.OutputPlugin)
+
+	err = gaussdbrepl.DropReplicationSlot(ctx, conn, "test_slot", gaussdbrepl.DropReplicationSlotOptions{Wait: true})
+	require.NoError(t, err)
+
+	closeConn(t, conn, serverErrChan)
+}
+
+func TestStartReplication(t *testing.T) {
+	t.Parallel()
+
+	serverTime := time.Date(2024, time.March, 1, 12, 30, 0, 123456000, time.UTC)
+	microsecSinceY2K := uint64(serverTime.Sub(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)).Microseconds())
+
+	xLogData := []byte{gaussdbrepl.XLogDataByteID}
+	xLogData = binary.BigEndian.AppendUint64(xLogData, 0x3000A50)
+	xLogData = binary.BigEndian.AppendUint64(xLogData, 0x3000B00)
+	xLogData = binary.BigEndian.AppendUint64(xLogData, microsecSinceY2K)
+	xLogData = append(xLogData, "BEGIN 42"...)
+
+	keepalive := []byte{gaussdbrepl.PrimaryKeepaliveMessageByteID}
+	keepalive = binary.BigEndian.AppendUint64(keepalive, 0x3000B00)
+	keepalive = binary.BigEndian.AppendUint64(keepalive, microsecSinceY2K)
+	keepalive = append(keepalive, 1)
+
+	clientTime := serverTime.Add(time.Second)
+	statusUpdate := []byte{gaussdbrepl.StandbyStatusUpdateByteID}
+	for i := 0; i < 3; i++ {
+		statusUpdate = binary.BigEndian.AppendUint64(statusUpdate, 0x3000B00)
+	}
+	statusUpdate = binary.BigEndian.AppendUint64(statusUpdate, microsecSinceY2K+1000000)
+	statusUpdate = append(statusUpdate, 0)
+
+	conn, serverErrChan := connectMockServer(t,
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: `START_REPLICATION SLOT test_slot LOGICAL 0/3000A50 ("include-xids" '1', "skip-empty-xacts" '1')`}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyBothResponse{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyData{Data: xLogData}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyData{Data: keepalive}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.CopyData{Data: statusUpdate}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.CopyDone{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyDone{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("START_REPLICATION")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := gaussdbrepl.StartReplication(ctx, conn, "test_slot", 0x3000A50, gaussdbrepl.StartReplicationOptions{
+		PluginArgs: []string{`"include-xids" '1'`, `"skip-empty-xacts" '1'`},
+	})
+	require.NoError(t, err)
+
+	msg, err := conn.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	copyData, ok := msg.(*gaussdbproto.CopyData)
+	require.True(t, ok)
+	require.EqualValues(t, gaussdbrepl.XLogDataByteID, copyData.Data[0])
+	xld, err := gaussdbrepl.ParseXLogData(copyData.Data[1:])
+	require.NoError(t, err)
+	assert.Equal(t, gaussdbrepl.LSN(0x3000A50), xld.WALStart)
+	assert.Equal(t, gaussdbrepl.LSN(0x3000B00), xld.ServerWALEnd)
+	assert.True(t, serverTime.Equal(xld.ServerTime))
+	assert.Equal(t, "BEGIN 42", string(xld.WALData))
+
+	msg, err = conn.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	copyData, ok = msg.(*gaussdbproto.CopyData)
+	require.True(t, ok)
+	require.EqualValues(t, gaussdbrepl.PrimaryKeepaliveMessageByteID, copyData.Data[0])
+	pkm, err := gaussdbrepl.ParsePrimaryKeepaliveMessage(copyData.Data[1:])
+	require.NoError(t, err)
+	assert.Equal(t, gaussdbrepl.LSN(0x3000B00), pkm.ServerWALEnd)
+	assert.True(t, serverTime.Equal(pkm.ServerTime))
+	assert.True(t, pkm.ReplyRequested)
+
+	err = gaussdbrepl.SendStandbyStatusUpdate(ctx, conn, gaussdbrepl.StandbyStatusUpdate{WALWritePosition: pkm.ServerWALEnd, ClientTime: clientTime})
+	require.NoError(t, err)
+
+	cdr, err := gaussdbrepl.SendStandbyCopyDone(ctx, conn)
+	require.NoError(t, err)
+	assert.Nil(t, cdr)
+
+	closeConn(t, conn, serverErrChan)
+}
+
+func TestStartReplicationError(t *testing.T) {
+	t.Parallel()
+
+	conn, serverErrChan := connectMockServer(t,
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "START_REPLICATION SLOT missing_slot LOGICAL 0/0"}),
+		gaussdbmock.SendMessage(&gaussdbproto.ErrorResponse{Severity: "ERROR", Code: "42704", Message: `replication slot "missing_slot" does not exist`}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	)
+
+	err := gaussdbrepl.StartReplication(context.Background(), conn, "missing_slot", 0, gaussdbrepl.StartReplicationOptions{})
+	var gaussdbErr *gaussdbconn.GaussdbError
+	require.ErrorAs(t, err, &gaussdbErr)
+	assert.Equal(t, "42704", gaussdbErr.Code)
+
+	closeConn(t, conn, serverErrChan)
+}
+
+func TestParseMessagesInvalidLength(t *testing.T) {
+	t.Parallel()
+
+	_, err := gaussdbrepl.ParseXLogData(make([]byte, 23))
+	assert.Error(t, err)
+	_, err = gaussdbrepl.ParsePrimaryKeepaliveMessage(make([]byte, 16))
+	assert.Error(t, err)
+}
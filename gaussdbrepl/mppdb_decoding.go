@@ -0,0 +1,88 @@
+package gaussdbrepl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbtype"
+)
+
+// mppdbDecodingChange is the JSON object written by mppdb_decoding for a changed row.
+type mppdbDecodingChange struct {
+	TableName   string   `json:"table_name"`
+	OpType      string   `json:"op_type"`
+	ColumnsName []string `json:"columns_name"`
+	ColumnsType []string `json:"columns_type"`
+	ColumnsVal  []string `json:"columns_val"`
+	OldKeysName []string `json:"old_keys_name"`
+	OldKeysType []string `json:"old_keys_type"`
+	OldKeysVal  []string `json:"old_keys_val"`
+}
+
+// ParseMppdbDecodingMessage parses the WALData of an XLogData message produced by the mppdb_decoding output plugin.
+// Column values are converted with typeMap.
+func ParseMppdbDecodingMessage(walData []byte, typeMap *gaussdbtype.Map) (Message, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(walData), []byte("{")) {
+		msg, err := parseTxnMessage(string(walData), typeMap)
+		if err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			return nil, fmt.Errorf("unsupported mppdb_decoding message: %q", walData)
+		}
+		return msg, nil
+	}
+
+	var change mppdbDecodingChange
+	if err := json.Unmarshal(walData, &change); err != nil {
+		return nil, fmt.Errorf("invalid mppdb_decoding message: %w", err)
+	}
+
+	schema, table, rest, err := parseQualifiedName(change.TableName)
+	if err != nil || rest != "" {
+		return nil, fmt.Errorf("invalid mppdb_decoding table name %q", change.TableName)
+	}
+
+	newTuple, err := mppdbDecodingTuple(typeMap, change.ColumnsName, change.ColumnsType, change.ColumnsVal)
+	if err != nil {
+		return nil, err
+	}
+	oldTuple, err := mppdbDecodingTuple(typeMap, change.OldKeysName, change.OldKeysType, change.OldKeysVal)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToUpper(change.OpType) {
+	case "INSERT":
+		return &InsertMessage{Schema: schema, Table: table, New: newTuple}, nil
+	case "UPDATE":
+		return &UpdateMessage{Schema: schema, Table: table, Old: oldTuple, New: newTuple}, nil
+	case "DELETE":
+		return &DeleteMessage{Schema: schema, Table: table, Old: oldTuple}, nil
+	default:
+		return nil, fmt.Errorf("unsupported mppdb_decoding op_type: %q", change.OpType)
+	}
+}
+
+func mppdbDecodingTuple(typeMap *gaussdbtype.Map, names, types, values []string) (Tuple, error) {
+	if len(names) != len(types) || len(names) != len(values) {
+		return nil, fmt.Errorf("mppdb_decoding column count mismatch: %d names, %d types, %d values", len(names), len(types), len(values))
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	tuple := make(Tuple, len(names))
+	for i := range names {
+		tuple[i] = Column{Name: names[i], TypeName: types[i]}
+		value, err := decodeLiteral(typeMap, types[i], values[i])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", names[i], err)
+		}
+		tuple[i].Value = value
+	}
+
+	return tuple, nil
+}
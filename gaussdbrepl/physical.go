@@ -0,0 +1,458 @@
+package gaussdbrepl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+)
+
+// DefaultWALSegmentSize is the size of a GaussDB WAL segment file.
+const DefaultWALSegmentSize = 16 * 1024 * 1024
+
+// ConnectPhysical establishes a physical replication connection to the server specified by connString. The
+// replication startup parameter is set to true. Only the simple query protocol may be used on the returned connection.
+func ConnectPhysical(ctx context.Context, connString string) (*gaussdbconn.GaussdbConn, error) {
+	return connect(ctx, connString, "true")
+}
+
+// WALSegmentFileName returns the name of the WAL segment file of timeline that contains lsn, e.g.
+// 000000010000000000000003.
+func WALSegmentFileName(timeline int32, lsn LSN, segmentSize uint64) string {
+	segmentNumber := uint64(lsn) / segmentSize
+	segmentsPerXLogID := 0x100000000 / segmentSize
+	return fmt.Sprintf("%08X%08X%08X", uint32(timeline), uint32(segmentNumber/segmentsPerXLogID), uint32(segmentNumber%segmentsPerXLogID))
+}
+
+// TimelineHistoryResult is the parsed result of the TIMELINE_HISTORY command.
+type TimelineHistoryResult struct {
+	FileName string
+	Content  []byte
+}
+
+// TimelineHistory executes the TIMELINE_HISTORY command to retrieve the history file of timeline.
+func TimelineHistory(ctx context.Context, conn *gaussdbconn.GaussdbConn, timeline int32) (TimelineHistoryResult, error) {
+	var thr TimelineHistoryResult
+	results, err := conn.Exec(ctx, "TIMELINE_HISTORY "+strconv.FormatInt(int64(timeline), 10)).ReadAll()
+	if err != nil {
+		return thr, err
+	}
+
+	if len(results) != 1 {
+		return thr, fmt.Errorf("expected 1 result set, got %d", len(results))
+	}
+
+	result := results[0]
+	if len(result.Rows) != 1 {
+		return thr, fmt.Errorf("expected 1 result row, got %d", len(result.Rows))
+	}
+
+	row := result.Rows[0]
+	if len(row) != 2 {
+		return thr, fmt.Errorf("expected 2 result columns, got %d", len(row))
+	}
+
+	thr.FileName = string(row[0])
+	thr.Content = row[1]
+
+	return thr, nil
+}
+
+// BaseBackupOptions are the options for the BASE_BACKUP command.
+type BaseBackupOptions struct {
+	Label         string // backup label. Omitted if empty.
+	Progress      bool   // request the size of each tablespace
+	Fast          bool   // request a fast checkpoint
+	WAL           bool   // include the WAL required to restore the backup
+	NoWait        bool   // do not wait for the WAL to be archived
+	MaxRate       int32  // maximum transfer rate in kB/s. 0 is unlimited.
+	TablespaceMap bool   // include tablespace_map
+}
+
+// BaseBackupTablespace is a tablespace sent by BASE_BACKUP.
+type BaseBackupTablespace struct {
+	OID      uint32 // 0 for the main data directory
+	Location string // empty for the main data directory
+	Size     int64  // approximate size in kB. -1 unless BaseBackupOptions.Progress was set.
+}
+
+// BaseBackupResult is the parsed result of the BASE_BACKUP command.
+type BaseBackupResult struct {
+	StartLSN      LSN
+	StartTimeline int32
+	EndLSN        LSN
+	EndTimeline   int32
+	Tablespaces   []BaseBackupTablespace
+}
+
+// BaseBackup executes the BASE_BACKUP command on a physical replication connection. The server sends a tar archive
+// for each tablespace. archiveWriter is called with the tablespace of each archive and the archive is written to the
+// returned io.Writer.
+//
+// If archiveWriter or the io.Writer fail the connection is left in the middle of the backup and must be closed.
+func BaseBackup(
+	ctx context.Context,
+	conn *gaussdbconn.GaussdbConn,
+	options BaseBackupOptions,
+	archiveWriter func(BaseBackupTablespace) (io.Writer, error),
+) (BaseBackupResult, error) {
+	var sb strings.Builder
+	sb.WriteString("BASE_BACKUP")
+	if options.Label != "" {
+		sb.WriteString(" LABEL '")
+		sb.WriteString(strings.ReplaceAll(options.Label, "'", "''"))
+		sb.WriteString("'")
+	}
+	if options.Progress {
+		sb.WriteString(" PROGRESS")
+	}
+	if options.Fast {
+		sb.WriteString(" FAST")
+	}
+	if options.WAL {
+		sb.WriteString(" WAL")
+	}
+	if options.NoWait {
+		sb.WriteString(" NOWAIT")
+	}
+	if options.MaxRate > 0 {
+		sb.WriteString(" MAX_RATE ")
+		sb.WriteString(strconv.FormatInt(int64(options.MaxRate), 10))
+	}
+	if options.TablespaceMap {
+		sb.WriteString(" TABLESPACE_MAP")
+	}
+
+	var bbr BaseBackupResult
+
+	conn.Frontend().SendQuery(&gaussdbproto.Query{String: sb.String()})
+	err := conn.Frontend().Flush()
+	if err != nil {
+		return bbr, fmt.Errorf("failed to send BASE_BACKUP: %w", err)
+	}
+
+	// The server sends the start position, the tablespaces, an archive per tablespace and the end position.
+	resultSet := -1
+	archive := -1
+	var w io.Writer
+	var gaussdbErr error
+	for {
+		msg, err := conn.ReceiveMessage(ctx)
+		if err != nil {
+			return bbr, err
+		}
+
+		switch msg := msg.(type) {
+		case *gaussdbproto.RowDescription:
+			resultSet++
+		case *gaussdbproto.DataRow:
+			switch resultSet {
+			case 0:
+				bbr.StartLSN, bbr.StartTimeline, err = parseBaseBackupPosition(msg.Values)
+			case 1:
+				var ts BaseBackupTablespace
+				ts, err = parseBaseBackupTablespace(msg.Values)
+				bbr.Tablespaces = append(bbr.Tablespaces, ts)
+			default:
+				bbr.EndLSN, bbr.EndTimeline, err = parseBaseBackupPosition(msg.Values)
+			}
+			if err != nil {
+				return bbr, err
+			}
+		case *gaussdbproto.CopyOutResponse:
+			archive++
+			ts := BaseBackupTablespace{Size: -1}
+			if archive < len(bbr.Tablespaces) {
+				ts = bbr.Tablespaces[archive]
+			}
+			w, err = archiveWriter(ts)
+			if err != nil {
+				return bbr, err
+			}
+		case *gaussdbproto.CopyData:
+			if w == nil {
+				return bbr, errors.New("received CopyData outside of an archive")
+			}
+			if _, err := w.Write(msg.Data); err != nil {
+				return bbr, err
+			}
+		case *gaussdbproto.CopyDone:
+			w = nil
+		case *gaussdbproto.ErrorResponse:
+			gaussdbErr = gaussdbconn.ErrorResponseToGuassdbError(msg)
+		case *gaussdbproto.ReadyForQuery:
+			return bbr, gaussdbErr
+		case *gaussdbproto.CommandComplete, *gaussdbproto.NoticeResponse, *gaussdbproto.ParameterStatus:
+		default:
+			return bbr, fmt.Errorf("unexpected response type: %T", msg)
+		}
+	}
+}
+
+func parseBaseBackupPosition(values [][]byte) (LSN, int32, error) {
+	if len(values) < 1 {
+		return 0, 0, errors.New("missing WAL position in BASE_BACKUP result")
+	}
+	lsn, err := ParseLSN(string(values[0]))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var timeline int64
+	if len(values) > 1 && values[1] != nil {
+		timeline, err = strconv.ParseInt(string(values[1]), 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to parse timeline: %w", err)
+		}
+	}
+
+	return lsn, int32(timeline), nil
+}
+
+func parseBaseBackupTablespace(values [][]byte) (BaseBackupTablespace, error) {
+	ts := BaseBackupTablespace{Size: -1}
+	if len(values) != 3 {
+		return ts, fmt.Errorf("expected 3 tablespace columns, got %d", len(values))
+	}
+
+	if values[0] != nil {
+		oid, err := strconv.ParseUint(string(values[0]), 10, 32)
+		if err != nil {
+			return ts, fmt.Errorf("failed to parse tablespace oid: %w", err)
+		}
+		ts.OID = uint32(oid)
+	}
+	ts.Location = string(values[1])
+	if values[2] != nil {
+		size, err := strconv.ParseInt(string(values[2]), 10, 64)
+		if err != nil {
+			return ts, fmt.Errorf("failed to parse tablespace size: %w", err)
+		}
+		ts.Size = size
+	}
+
+	return ts, nil
+}
+
+// WALReceiver streams physical WAL into segments. It follows timeline switches and can be resumed from the position
+// returned by Receive.
+type WALReceiver struct {
+	Conn     *gaussdbconn.GaussdbConn // a physical replication connection, see ConnectPhysical
+	SlotName string                   // physical replication slot to stream from. Optional.
+
+	// Timeline is the timeline to start streaming from. If 0 the current timeline of the server is used. It is updated
+	// as the server switches timelines.
+	Timeline int32
+
+	// SegmentSize is the WAL segment size of the server. It must be a power of 2. Default DefaultWALSegmentSize.
+	SegmentSize uint64
+
+	// StatusInterval is the interval between standby status updates. Default 10 seconds.
+	StatusInterval time.Duration
+
+	// OpenSegment returns the writer for the WAL segment of timeline starting at segmentStart. Use WALSegmentFileName
+	// to name segment files. When resuming inside a segment the first write is at offset startLSN - segmentStart, e.g.
+	// a segment file should be opened for appending. The writer is closed when the segment is complete, the timeline
+	// ends or Receive returns. The position reported to the server as flushed is advanced only when a writer has
+	// been closed without error.
+	OpenSegment func(timeline int32, segmentStart LSN) (io.WriteCloser, error)
+
+	segment io.WriteCloser
+	written LSN // end of the WAL written to segments
+	flushed LSN // end of the WAL written to closed segments
+}
+
+// Receive streams WAL beginning at startLSN until ctx is canceled, an error occurs or the server ends replication
+// without switching to another timeline. It returns the position up to which WAL has been written. Pass that position
+// to a later Receive, with a new connection if the error broke the connection, to resume.
+func (r *WALReceiver) Receive(ctx context.Context, startLSN LSN) (LSN, error) {
+	segmentSize := r.SegmentSize
+	if segmentSize == 0 {
+		segmentSize = DefaultWALSegmentSize
+	}
+	if segmentSize&(segmentSize-1) != 0 {
+		return startLSN, fmt.Errorf("WAL segment size %d is not a power of 2", segmentSize)
+	}
+
+	if r.Timeline == 0 {
+		isr, err := IdentifySystem(ctx, r.Conn)
+		if err != nil {
+			return startLSN, err
+		}
+		r.Timeline = isr.Timeline
+	}
+
+	r.written = startLSN
+	r.flushed = startLSN
+
+	for {
+		err := StartReplication(ctx, r.Conn, r.SlotName, r.written, StartReplicationOptions{Timeline: r.Timeline, Mode: PhysicalReplication})
+		if err != nil {
+			return r.written, errors.Join(err, r.closeSegment())
+		}
+
+		err = r.stream(ctx, segmentSize)
+		if err != nil {
+			return r.written, errors.Join(err, r.closeSegment())
+		}
+
+		// The server ended the timeline. The last segment of the timeline is partial.
+		if err := r.closeSegment(); err != nil {
+			return r.written, err
+		}
+		cdr, err := SendStandbyCopyDone(ctx, r.Conn)
+		if err != nil {
+			return r.written, err
+		}
+		if cdr == nil {
+			return r.written, nil
+		}
+
+		r.Timeline = cdr.Timeline
+		r.written = cdr.LSN
+		r.flushed = cdr.LSN
+	}
+}
+
+// stream receives WAL until the server sends CopyDone.
+func (r *WALReceiver) stream(ctx context.Context, segmentSize uint64) error {
+	statusInterval := r.StatusInterval
+	if statusInterval == 0 {
+		statusInterval = 10 * time.Second
+	}
+	nextStatusDeadline := time.Now().Add(statusInterval)
+
+	for {
+		if time.Now().After(nextStatusDeadline) {
+			if err := r.sendStatus(ctx); err != nil {
+				return err
+			}
+			nextStatusDeadline = time.Now().Add(statusInterval)
+		}
+
+		receiveCtx, cancel := context.WithDeadline(ctx, nextStatusDeadline)
+		msg, err := r.Conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if gaussdbconn.Timeout(err) && ctx.Err() == nil {
+				continue
+			}
+			return err
+		}
+
+		switch msg := msg.(type) {
+		case *gaussdbproto.CopyData:
+			if len(msg.Data) == 0 {
+				return errors.New("received empty CopyData")
+			}
+			switch msg.Data[0] {
+			case PrimaryKeepaliveMessageByteID:
+				pkm, err := ParsePrimaryKeepaliveMessage(msg.Data[1:])
+				if err != nil {
+					return err
+				}
+				if pkm.ReplyRequested {
+					nextStatusDeadline = time.Time{}
+				}
+			case XLogDataByteID:
+				xld, err := ParseXLogData(msg.Data[1:])
+				if err != nil {
+					return err
+				}
+				segmentDone, err := r.write(xld, segmentSize)
+				if err != nil {
+					return err
+				}
+				if segmentDone {
+					// Let the server release the WAL of the completed segment right away.
+					nextStatusDeadline = time.Time{}
+				}
+			default:
+				return fmt.Errorf("unexpected CopyData message type %q", msg.Data[0])
+			}
+		case *gaussdbproto.CopyDone:
+			return nil
+		case *gaussdbproto.ErrorResponse:
+			return gaussdbconn.ErrorResponseToGuassdbError(msg)
+		case *gaussdbproto.NoticeResponse, *gaussdbproto.ParameterStatus:
+		default:
+			return fmt.Errorf("unexpected response type: %T", msg)
+		}
+	}
+}
+
+// write writes the WAL of xld to the segments it belongs to. It reports whether a segment was completed.
+func (r *WALReceiver) write(xld XLogData, segmentSize uint64) (bool, error) {
+	pos := xld.WALStart
+	data := xld.WALData
+	if pos > r.written {
+		return false, fmt.Errorf("WAL gap: expected data at %s, got %s", r.written, pos)
+	}
+	// Skip WAL that has already been written.
+	if skip := uint64(r.written - pos); skip > 0 {
+		if skip >= uint64(len(data)) {
+			return false, nil
+		}
+		data = data[skip:]
+		pos = r.written
+	}
+
+	var segmentDone bool
+	for len(data) > 0 {
+		segmentStart := pos - pos%LSN(segmentSize)
+		if r.segment == nil {
+			segment, err := r.OpenSegment(r.Timeline, segmentStart)
+			if err != nil {
+				return segmentDone, err
+			}
+			r.segment = segment
+		}
+
+		n := uint64(len(data))
+		if remaining := uint64(segmentStart) + segmentSize - uint64(pos); n > remaining {
+			n = remaining
+		}
+		if _, err := r.segment.Write(data[:n]); err != nil {
+			return segmentDone, err
+		}
+		pos += LSN(n)
+		data = data[n:]
+		r.written = pos
+
+		if uint64(pos)%segmentSize == 0 {
+			if err := r.closeSegment(); err != nil {
+				return segmentDone, err
+			}
+			segmentDone = true
+		}
+	}
+
+	return segmentDone, nil
+}
+
+// closeSegment closes the current segment and advances the flushed position.
+func (r *WALReceiver) closeSegment() error {
+	if r.segment == nil {
+		return nil
+	}
+	err := r.segment.Close()
+	r.segment = nil
+	if err != nil {
+		return err
+	}
+	r.flushed = r.written
+	return nil
+}
+
+func (r *WALReceiver) sendStatus(ctx context.Context) error {
+	return SendStandbyStatusUpdate(ctx, r.Conn, StandbyStatusUpdate{
+		WALWritePosition: r.written,
+		WALFlushPosition: r.flushed,
+	})
+}
@@ -0,0 +1,238 @@
+package gaussdbrepl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbrepl"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/gaussdbmock"
+)
+
+type stepFunc func(*gaussdbproto.Backend) error
+
+func (f stepFunc) Step(backend *gaussdbproto.Backend) error { return f(backend) }
+
+// expectStandbyStatusUpdate expects a standby status update with the given write and flush positions.
+func expectStandbyStatusUpdate(write, flush gaussdbrepl.LSN) gaussdbmock.Step {
+	return stepFunc(func(backend *gaussdbproto.Backend) error {
+		msg, err := backend.Receive()
+		if err != nil {
+			return err
+		}
+		copyData, ok := msg.(*gaussdbproto.CopyData)
+		if !ok || len(copyData.Data) != 34 || copyData.Data[0] != gaussdbrepl.StandbyStatusUpdateByteID {
+			return fmt.Errorf("expected standby status update, got %#v", msg)
+		}
+		gotWrite := gaussdbrepl.LSN(binary.BigEndian.Uint64(copyData.Data[1:]))
+		gotFlush := gaussdbrepl.LSN(binary.BigEndian.Uint64(copyData.Data[9:]))
+		if gotWrite != write || gotFlush != flush {
+			return fmt.Errorf("expected write %s flush %s, got write %s flush %s", write, flush, gotWrite, gotFlush)
+		}
+		return nil
+	})
+}
+
+func xLogDataMessage(walStart gaussdbrepl.LSN, data string) *gaussdbproto.CopyData {
+	buf := []byte{gaussdbrepl.XLogDataByteID}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(walStart))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(walStart)+uint64(len(data)))
+	buf = binary.BigEndian.AppendUint64(buf, 0)
+	buf = append(buf, data...)
+	return &gaussdbproto.CopyData{Data: buf}
+}
+
+func TestWALSegmentFileName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "000000010000000000000003", gaussdbrepl.WALSegmentFileName(1, 0x3000A18, gaussdbrepl.DefaultWALSegmentSize))
+	assert.Equal(t, "0000000200000001000000FF", gaussdbrepl.WALSegmentFileName(2, 0x1FF000000, gaussdbrepl.DefaultWALSegmentSize))
+	assert.Equal(t, "00000001000000000000001F", gaussdbrepl.WALSegmentFileName(1, 0x7F000000, 64*1024*1024))
+}
+
+func TestTimelineHistory(t *testing.T) {
+	t.Parallel()
+
+	steps := []gaussdbmock.Step{gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "TIMELINE_HISTORY 2"})}
+	steps = append(steps, sendResult([]string{"filename", "content"}, []string{"00000002.history", "1\t0/3000000\tno recovery target specified\n"})...)
+	steps = append(steps,
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("TIMELINE_HISTORY")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	)
+	conn, serverErrChan := connectMockServerWith(t, gaussdbrepl.ConnectPhysical, "true", steps...)
+
+	thr, err := gaussdbrepl.TimelineHistory(context.Background(), conn, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "00000002.history", thr.FileName)
+	assert.Equal(t, "1\t0/3000000\tno recovery target specified\n", string(thr.Content))
+
+	closeConn(t, conn, serverErrChan)
+}
+
+func TestBaseBackup(t *testing.T) {
+	t.Parallel()
+
+	positionFields := &gaussdbproto.RowDescription{Fields: []gaussdbproto.FieldDescription{
+		{Name: []byte("recptr"), DataTypeOID: 25, DataTypeSize: -1},
+		{Name: []byte("tli"), DataTypeOID: 20, DataTypeSize: 8},
+	}}
+
+	conn, serverErrChan := connectMockServerWith(t, gaussdbrepl.ConnectPhysical, "true",
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "BASE_BACKUP LABEL 'nightly''s' PROGRESS FAST WAL"}),
+		gaussdbmock.SendMessage(positionFields),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("0/2000028"), []byte("1")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT")}),
+		gaussdbmock.SendMessage(&gaussdbproto.RowDescription{Fields: []gaussdbproto.FieldDescription{
+			{Name: []byte("spcoid"), DataTypeOID: 26, DataTypeSize: 4},
+			{Name: []byte("spclocation"), DataTypeOID: 25, DataTypeSize: -1},
+			{Name: []byte("size"), DataTypeOID: 20, DataTypeSize: 8},
+		}}),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("16385"), []byte("/mnt/ts1"), []byte("100")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{nil, nil, []byte("2048")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT")}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyOutResponse{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyData{Data: []byte("ts1-part1 ")}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyData{Data: []byte("ts1-part2")}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyDone{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyOutResponse{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyData{Data: []byte("base")}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyDone{}),
+		gaussdbmock.SendMessage(positionFields),
+		gaussdbmock.SendMessage(&gaussdbproto.DataRow{Values: [][]byte{[]byte("0/2000100"), []byte("1")}}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SELECT")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var archives []gaussdbrepl.BaseBackupTablespace
+	var buffers []*bytes.Buffer
+	bbr, err := gaussdbrepl.BaseBackup(ctx, conn, gaussdbrepl.BaseBackupOptions{Label: "nightly's", Progress: true, Fast: true, WAL: true},
+		func(ts gaussdbrepl.BaseBackupTablespace) (io.Writer, error) {
+			archives = append(archives, ts)
+			buffers = append(buffers, &bytes.Buffer{})
+			return buffers[len(buffers)-1], nil
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, gaussdbrepl.LSN(0x2000028), bbr.StartLSN)
+	assert.EqualValues(t, 1, bbr.StartTimeline)
+	assert.Equal(t, gaussdbrepl.LSN(0x2000100), bbr.EndLSN)
+	assert.EqualValues(t, 1, bbr.EndTimeline)
+
+	expectedTablespaces := []gaussdbrepl.BaseBackupTablespace{
+		{OID: 16385, Location: "/mnt/ts1", Size: 100},
+		{Size: 2048},
+	}
+	assert.Equal(t, expectedTablespaces, bbr.Tablespaces)
+	assert.Equal(t, expectedTablespaces, archives)
+	require.Len(t, buffers, 2)
+	assert.Equal(t, "ts1-part1 ts1-part2", buffers[0].String())
+	assert.Equal(t, "base", buffers[1].String())
+
+	closeConn(t, conn, serverErrChan)
+}
+
+type testSegment struct {
+	timeline     int32
+	segmentStart gaussdbrepl.LSN
+	bytes.Buffer
+	closed bool
+}
+
+func (s *testSegment) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestWALReceiver(t *testing.T) {
+	t.Parallel()
+
+	keepalive := []byte{gaussdbrepl.PrimaryKeepaliveMessageByteID}
+	keepalive = binary.BigEndian.AppendUint64(keepalive, 0x1000014)
+	keepalive = binary.BigEndian.AppendUint64(keepalive, 0)
+	keepalive = append(keepalive, 1)
+
+	steps := []gaussdbmock.Step{gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "IDENTIFY_SYSTEM"})}
+	steps = append(steps, sendResult([]string{"systemid", "timeline", "xlogpos"}, []string{"6940251283914473372", "1", "0/1000014"})...)
+	steps = append(steps,
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("IDENTIFY_SYSTEM")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+
+		// Timeline 1 starting in the middle of a segment.
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "START_REPLICATION SLOT backup_slot PHYSICAL 0/1000008 TIMELINE 1"}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyBothResponse{}),
+		gaussdbmock.SendMessage(xLogDataMessage(0x1000008, "abcdefghijkl")),
+		expectStandbyStatusUpdate(0x1000014, 0x1000010),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyData{Data: keepalive}),
+		expectStandbyStatusUpdate(0x1000014, 0x1000010),
+
+		// The server ends timeline 1 and reports timeline 2.
+		gaussdbmock.SendMessage(&gaussdbproto.CopyDone{}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.CopyDone{}),
+	)
+	steps = append(steps, sendResult([]string{"next_tli", "next_tli_startpos"}, []string{"2", "0/1000014"})...)
+	steps = append(steps,
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("START_STREAMING")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "START_REPLICATION SLOT backup_slot PHYSICAL 0/1000014 TIMELINE 2"}),
+		gaussdbmock.SendMessage(&gaussdbproto.CopyBothResponse{}),
+		// Data already received is skipped.
+		gaussdbmock.SendMessage(xLogDataMessage(0x1000012, "klmn")),
+
+		// The server ends replication.
+		gaussdbmock.SendMessage(&gaussdbproto.CopyDone{}),
+		gaussdbmock.ExpectMessage(&gaussdbproto.CopyDone{}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("START_STREAMING")}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	)
+	conn, serverErrChan := connectMockServerWith(t, gaussdbrepl.ConnectPhysical, "true", steps...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var segments []*testSegment
+	receiver := &gaussdbrepl.WALReceiver{
+		Conn:        conn,
+		SlotName:    "backup_slot",
+		SegmentSize: 16,
+		OpenSegment: func(timeline int32, segmentStart gaussdbrepl.LSN) (io.WriteCloser, error) {
+			segment := &testSegment{timeline: timeline, segmentStart: segmentStart}
+			segments = append(segments, segment)
+			return segment, nil
+		},
+	}
+
+	lsn, err := receiver.Receive(ctx, 0x1000008)
+	require.NoError(t, err)
+	assert.Equal(t, gaussdbrepl.LSN(0x1000016), lsn)
+	assert.EqualValues(t, 2, receiver.Timeline)
+
+	require.Len(t, segments, 3)
+	for i, expected := range []struct {
+		timeline     int32
+		segmentStart gaussdbrepl.LSN
+		data         string
+	}{
+		{1, 0x1000000, "abcdefgh"},
+		{1, 0x1000010, "ijkl"},
+		{2, 0x1000010, "mn"},
+	} {
+		assert.Equal(t, expected.timeline, segments[i].timeline)
+		assert.Equal(t, expected.segmentStart, segments[i].segmentStart)
+		assert.Equal(t, expected.data, segments[i].String())
+		assert.True(t, segments[i].closed)
+	}
+
+	closeConn(t, conn, serverErrChan)
+}
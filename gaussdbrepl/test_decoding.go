@@ -0,0 +1,146 @@
+package gaussdbrepl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbtype"
+)
+
+const (
+	testDecodingNoTupleData    = "(no-tuple-data)"
+	testDecodingUnchangedTOAST = "unchanged-toast-datum"
+)
+
+// ParseTestDecodingMessage parses the WALData of an XLogData message produced by the test_decoding output plugin, e.g.
+//
+//	table public.t: UPDATE: old-key: id[integer]:1 new-tuple: id[integer]:2 name[text]:'x'
+//
+// Column values are converted with typeMap.
+func ParseTestDecodingMessage(walData []byte, typeMap *gaussdbtype.Map) (Message, error) {
+	s := string(walData)
+
+	rest, ok := strings.CutPrefix(s, "table ")
+	if !ok {
+		msg, err := parseTxnMessage(s, typeMap)
+		if err != nil {
+			return nil, err
+		}
+		if msg == nil {
+			return nil, fmt.Errorf("unsupported test_decoding message: %q", s)
+		}
+		return msg, nil
+	}
+
+	schema, table, rest, err := parseQualifiedName(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid test_decoding message %q: %w", s, err)
+	}
+	rest, ok = strings.CutPrefix(rest, ": ")
+	if !ok {
+		return nil, fmt.Errorf("invalid test_decoding message: %q", s)
+	}
+	action, rest, ok := strings.Cut(rest, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid test_decoding message: %q", s)
+	}
+	rest = strings.TrimPrefix(rest, " ")
+
+	switch action {
+	case "INSERT":
+		newTuple, err := parseTestDecodingTuple(rest, typeMap)
+		if err != nil {
+			return nil, err
+		}
+		return &InsertMessage{Schema: schema, Table: table, New: newTuple}, nil
+	case "UPDATE":
+		var oldTuple Tuple
+		if after, ok := strings.CutPrefix(rest, "old-key: "); ok {
+			oldKey, newTuple, ok := strings.Cut(after, " new-tuple: ")
+			if !ok {
+				return nil, fmt.Errorf("invalid test_decoding message: %q", s)
+			}
+			oldTuple, err = parseTestDecodingTuple(oldKey, typeMap)
+			if err != nil {
+				return nil, err
+			}
+			rest = newTuple
+		}
+		newTuple, err := parseTestDecodingTuple(rest, typeMap)
+		if err != nil {
+			return nil, err
+		}
+		return &UpdateMessage{Schema: schema, Table: table, Old: oldTuple, New: newTuple}, nil
+	case "DELETE":
+		oldTuple, err := parseTestDecodingTuple(rest, typeMap)
+		if err != nil {
+			return nil, err
+		}
+		return &DeleteMessage{Schema: schema, Table: table, Old: oldTuple}, nil
+	default:
+		return nil, fmt.Errorf("unsupported test_decoding action: %q", action)
+	}
+}
+
+// parseTestDecodingTuple parses space separated name[type]:value columns.
+func parseTestDecodingTuple(s string, typeMap *gaussdbtype.Map) (Tuple, error) {
+	if s == testDecodingNoTupleData {
+		return nil, nil
+	}
+
+	var tuple Tuple
+	for s != "" {
+		name, rest, err := parseIdentifier(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid test_decoding column in %q: %w", s, err)
+		}
+		if !strings.HasPrefix(rest, "[") {
+			return nil, fmt.Errorf("missing type of test_decoding column %s", name)
+		}
+		// Array type names end with [] so the type ends at the first "]:".
+		typeName, rest, ok := strings.Cut(rest[1:], "]:")
+		if !ok {
+			return nil, fmt.Errorf("missing value of test_decoding column %s", name)
+		}
+
+		literal, rest, err := cutTestDecodingLiteral(rest)
+		if err != nil {
+			return nil, fmt.Errorf("test_decoding column %s: %w", name, err)
+		}
+
+		column := Column{Name: name, TypeName: typeName}
+		if literal == testDecodingUnchangedTOAST {
+			column.Unchanged = true
+		} else {
+			column.Value, err = decodeLiteral(typeMap, typeName, literal)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", name, err)
+			}
+		}
+		tuple = append(tuple, column)
+
+		s = strings.TrimPrefix(rest, " ")
+	}
+
+	return tuple, nil
+}
+
+// cutTestDecodingLiteral returns the value at the start of s and the rest of s. Quoted values may contain spaces.
+func cutTestDecodingLiteral(s string) (string, string, error) {
+	if !strings.HasPrefix(s, "'") {
+		literal, rest, _ := strings.Cut(s, " ")
+		return literal, rest, nil
+	}
+
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\'' {
+			if i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return s[:i+1], s[i+1:], nil
+		}
+	}
+	return "", "", errors.New("unterminated quoted value")
+}
@@ -205,6 +205,8 @@ type Map struct {
 	memoizedScanPlans   map[uint32]map[reflect.Type][2]ScanPlan
 	memoizedEncodePlans map[uint32]map[reflect.Type][2]EncodePlan
 
+	parameterStatuses map[string]string
+
 	// TryWrapEncodePlanFuncs is a slice of functions that will wrap a value that cannot be encoded by the Codec. Every
 	// time a wrapper is found the PlanEncode method will be recursively called with the new value. This allows several layers of wrappers
 	// to be built up. There are default functions placed in this slice by NewMap(). In most cases these functions
@@ -224,6 +226,9 @@ func (m *Map) Copy() *Map {
 	for _, type_ := range m.oidToType {
 		newMap.RegisterType(type_)
 	}
+	for name, value := range m.parameterStatuses {
+		newMap.parameterStatuses[name] = value
+	}
 	return newMap
 }
 
@@ -239,6 +244,8 @@ func NewMap() *Map {
 		memoizedScanPlans:   make(map[uint32]map[reflect.Type][2]ScanPlan),
 		memoizedEncodePlans: make(map[uint32]map[reflect.Type][2]EncodePlan),
 
+		parameterStatuses: make(map[string]string),
+
 		TryWrapEncodePlanFuncs: []TryWrapEncodePlanFunc{
 			TryWrapDerefPointerEncodePlan,
 			TryWrapBuiltinTypeEncodePlan,
@@ -300,6 +307,40 @@ func (m *Map) RegisterDefaultGaussdbType(value any, name string) {
 	}
 }
 
+// planParameterStatuses are the run-time parameters read by the codecs of this package when they plan a scan or
+// encode. Only a change of one of them invalidates the memoized plans.
+var planParameterStatuses = map[string]struct{}{
+	"TimeZone": {},
+}
+
+// SetParameterStatus sets the value of a run-time parameter reported by the server such as TimeZone or DateStyle.
+// gaussdbgo calls it whenever the server reports a parameter. Codecs can read it with ParameterStatus when they plan a
+// scan or encode. Plans are only invalidated by a change of TimeZone, so other parameters must not affect the plan a
+// codec returns.
+func (m *Map) SetParameterStatus(name, value string) {
+	if m.parameterStatuses[name] == value {
+		return
+	}
+	m.parameterStatuses[name] = value
+
+	if _, ok := planParameterStatuses[name]; !ok {
+		return
+	}
+
+	// Invalidated by parameter change as plans may depend on it.
+	for k := range m.memoizedScanPlans {
+		delete(m.memoizedScanPlans, k)
+	}
+	for k := range m.memoizedEncodePlans {
+		delete(m.memoizedEncodePlans, k)
+	}
+}
+
+// ParameterStatus returns the value of a run-time parameter set with SetParameterStatus or "" if it has not been set.
+func (m *Map) ParameterStatus(name string) string {
+	return m.parameterStatuses[name]
+}
+
 // TypeForOID returns the Type registered for the given OID. The returned Type must not be mutated.
 func (m *Map) TypeForOID(oid uint32) (*Type, bool) {
 	if dt, ok := m.oidToType[oid]; ok {
@@ -519,6 +519,46 @@ func TestMapEncodeRawJSONIntoUnknownOID(t *testing.T) {
 	require.Equal(t, []byte(`{"foo": "bar"}`), buf)
 }
 
+type planCountingCodec struct {
+	gaussdbtype.Int4Codec
+	scanPlans int
+}
+
+func (c *planCountingCodec) PlanScan(m *gaussdbtype.Map, oid uint32, format int16, target any) gaussdbtype.ScanPlan {
+	c.scanPlans++
+	return c.Int4Codec.PlanScan(m, oid, format, target)
+}
+
+func TestMapSetParameterStatusInvalidatesPlans(t *testing.T) {
+	codec := &planCountingCodec{}
+	m := gaussdbtype.NewMap()
+	m.RegisterType(&gaussdbtype.Type{Name: "int4", OID: gaussdbtype.Int4OID, Codec: codec})
+
+	scan := func() {
+		var n int32
+		err := m.Scan(gaussdbtype.Int4OID, gaussdbtype.BinaryFormatCode, []byte{0, 0, 0, 42}, &n)
+		require.NoError(t, err)
+		require.EqualValues(t, 42, n)
+	}
+
+	scan()
+	require.Equal(t, 1, codec.scanPlans)
+
+	// Parameters no codec plans with keep the memoized plans.
+	m.SetParameterStatus("search_path", "public")
+	scan()
+	require.Equal(t, 1, codec.scanPlans)
+
+	m.SetParameterStatus("TimeZone", "Asia/Shanghai")
+	scan()
+	require.Equal(t, 2, codec.scanPlans)
+
+	// Setting the same value again changes nothing.
+	m.SetParameterStatus("TimeZone", "Asia/Shanghai")
+	scan()
+	require.Equal(t, 2, codec.scanPlans)
+}
+
 func BenchmarkMapScanInt4IntoBinaryDecoder(b *testing.B) {
 	m := gaussdbtype.NewMap()
 	src := []byte{0, 0, 0, 42}
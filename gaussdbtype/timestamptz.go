@@ -128,6 +128,23 @@ type TimestamptzCodec struct {
 	// ScanLocation is the location to return scanned timestamptz values in. This does not change the instant in time that
 	// the timestamptz represents.
 	ScanLocation *time.Location
+
+	// ScanInSessionTimeZone returns scanned timestamptz values in the TimeZone reported by the server instead of
+	// ScanLocation. ScanLocation is used if the TimeZone is unknown or is not a valid IANA time zone name. It is disabled
+	// by default.
+	ScanInSessionTimeZone bool
+}
+
+// scanLocation returns the location to return scanned timestamptz values in.
+func (c *TimestamptzCodec) scanLocation(m *Map) *time.Location {
+	if c.ScanInSessionTimeZone {
+		if name := m.ParameterStatus("TimeZone"); name != "" {
+			if location, err := time.LoadLocation(name); err == nil {
+				return location
+			}
+		}
+	}
+	return c.ScanLocation
 }
 
 func (*TimestamptzCodec) FormatSupported(format int16) bool {
@@ -230,12 +247,12 @@ func (c *TimestamptzCodec) PlanScan(m *Map, oid uint32, format int16, target any
 	case BinaryFormatCode:
 		switch target.(type) {
 		case TimestamptzScanner:
-			return &scanPlanBinaryTimestamptzToTimestamptzScanner{location: c.ScanLocation}
+			return &scanPlanBinaryTimestamptzToTimestamptzScanner{location: c.scanLocation(m)}
 		}
 	case TextFormatCode:
 		switch target.(type) {
 		case TimestamptzScanner:
-			return &scanPlanTextTimestamptzToTimestamptzScanner{location: c.ScanLocation}
+			return &scanPlanTextTimestamptzToTimestamptzScanner{location: c.scanLocation(m)}
 		}
 	}
 
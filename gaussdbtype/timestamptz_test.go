@@ -66,6 +66,35 @@ func TestTimestamptzCodecWithLocationLocal(t *testing.T) {
 	})
 }
 
+func TestTimestamptzCodecScanInSessionTimeZone(t *testing.T) {
+	m := gaussdbtype.NewMap()
+	m.RegisterType(&gaussdbtype.Type{
+		Name:  "timestamptz",
+		OID:   gaussdbtype.TimestamptzOID,
+		Codec: &gaussdbtype.TimestamptzCodec{ScanLocation: time.UTC, ScanInSessionTimeZone: true},
+	})
+
+	buf, err := m.Encode(gaussdbtype.TimestamptzOID, gaussdbtype.BinaryFormatCode, time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+	require.NoError(t, err)
+
+	var tm time.Time
+	err = m.Scan(gaussdbtype.TimestamptzOID, gaussdbtype.BinaryFormatCode, buf, &tm)
+	require.NoError(t, err)
+	require.Equal(t, "UTC", tm.Location().String())
+
+	m.SetParameterStatus("TimeZone", "Asia/Shanghai")
+	err = m.Scan(gaussdbtype.TimestamptzOID, gaussdbtype.BinaryFormatCode, buf, &tm)
+	require.NoError(t, err)
+	require.Equal(t, "Asia/Shanghai", tm.Location().String())
+	require.True(t, tm.Equal(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	// Fall back to ScanLocation when the session time zone is not a known location.
+	m.SetParameterStatus("TimeZone", "not a time zone")
+	err = m.Scan(gaussdbtype.TimestamptzOID, gaussdbtype.BinaryFormatCode, buf, &tm)
+	require.NoError(t, err)
+	require.Equal(t, "UTC", tm.Location().String())
+}
+
 func TestTimestamptzTranscodeBigTimeBinary(t *testing.T) {
 	defaultConnTestRunner.RunTest(context.Background(), t, func(ctx context.Context, t testing.TB, conn *gaussdbx.Conn) {
 		in := &gaussdbtype.Timestamptz{Time: time.Date(294276, 12, 31, 23, 59, 59, 999999000, time.UTC), Valid: true}
@@ -2,6 +2,7 @@ package gaussdbxpool
 
 import (
 	"context"
+	"io"
 	"sync/atomic"
 
 	"github.com/HuaweiCloudDeveloper/gaussdb-go"
@@ -50,6 +51,15 @@ func (c *Conn) Release() {
 		return
 	}
 
+	if c.p.isDirty(res) {
+		atomic.AddInt64(&c.p.dirtyDestroyCount, 1)
+		res.Destroy()
+		// Signal to the health check to run since we just destroyed a connections
+		// and we might be below minConns now
+		c.p.triggerHealthCheck()
+		return
+	}
+
 	if c.p.afterRelease == nil {
 		res.Release()
 		return
@@ -103,6 +113,22 @@ func (c *Conn) CopyFrom(ctx context.Context, tableName gaussdbgo.Identifier, col
 	return c.Conn().CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
 
+func (c *Conn) CopyFromCSV(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, r io.Reader, opts gaussdbgo.CopyFromCSVOptions) (int64, error) {
+	return c.Conn().CopyFromCSV(ctx, tableName, columnNames, r, opts)
+}
+
+func (c *Conn) CopyFromText(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, r io.Reader, opts gaussdbgo.CopyFromTextOptions) (int64, error) {
+	return c.Conn().CopyFromText(ctx, tableName, columnNames, r, opts)
+}
+
+func (c *Conn) CopyFromMerge(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, rowSrc gaussdbgo.CopyFromSource, opts gaussdbgo.CopyFromMergeOptions) (gaussdbgo.CopyFromMergeResult, error) {
+	return c.Conn().CopyFromMerge(ctx, tableName, columnNames, rowSrc, opts)
+}
+
+func (c *Conn) CopyTo(ctx context.Context, tableOrQuery any, columnNames []string, dst gaussdbgo.CopyToDestination) (int64, error) {
+	return c.Conn().CopyTo(ctx, tableOrQuery, columnNames, dst)
+}
+
 // Begin starts a transaction block from the *Conn without explicitly setting a transaction mode (see BeginTx with TxOptions if transaction mode is required).
 func (c *Conn) Begin(ctx context.Context) (gaussdbgo.Tx, error) {
 	return c.Conn().Begin(ctx)
@@ -23,5 +23,22 @@ specified here. In addition, a config struct can be created by [ParseConfig].
 
 A pool returns without waiting for any connections to be established. Acquire a connection immediately after creating
 the pool to check if a connection can successfully be established.
+
+Listening for Notifications
+
+A [Listener] receives LISTEN/NOTIFY notifications on a dedicated connection from the pool. It reconnects and listens to
+all channels again when the connection is lost:
+
+    listener := &gaussdbxpool.Listener{
+        Pool: pool,
+        OnReconnect: func(ctx context.Context, conn *gaussdbgo.Conn) error {
+            // reload any state that may have changed while disconnected
+        },
+    }
+    listener.Handle("orders", func(ctx context.Context, n *gaussdbconn.Notification) error {
+        // handle n.Payload
+    })
+
+    err := listener.Listen(ctx)
 */
 package gaussdbxpool
@@ -0,0 +1,288 @@
+package gaussdbxpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+	"github.com/jackc/puddle/v2"
+)
+
+const (
+	defaultListenerReconnectDelay    = time.Second
+	defaultListenerMaxReconnectDelay = time.Minute
+)
+
+// NotificationHandler handles a notification received by a Listener. Handlers are called one at a time on the
+// goroutine running Listener.Listen. A slow handler delays the delivery of all other notifications.
+type NotificationHandler func(ctx context.Context, notification *gaussdbconn.Notification) error
+
+// Listener listens for LISTEN/NOTIFY notifications on a dedicated connection acquired from Pool and dispatches them to
+// handlers registered per channel. When the connection is lost, Listener reconnects with an exponential backoff and
+// listens to all channels again.
+//
+// Notifications sent while the Listener is not connected are lost. Use OnReconnect to catch up on any changes that
+// happened in the meantime.
+//
+// Listener is safe for concurrent use. The exported fields must not be modified after Listen has been called.
+type Listener struct {
+	// Pool is the pool the connection is acquired from. It is required. The connection is hijacked from the pool, so it
+	// does not count against the MaxConns of the pool.
+	Pool *Pool
+
+	// OnReconnect is called every time a connection is established and all channels are listened to, including the
+	// first time. Notifications that arrive while OnReconnect runs are dispatched after it returns. If OnReconnect
+	// returns an error the connection is closed and the Listener reconnects.
+	OnReconnect func(ctx context.Context, conn *gaussdbgo.Conn) error
+
+	// LogError is called with errors that do not stop the Listener such as a lost connection or a failed handler. If nil
+	// errors are ignored.
+	LogError func(ctx context.Context, err error)
+
+	// ReconnectDelay is the delay before the first reconnect attempt. It doubles with each consecutive failure up to
+	// MaxReconnectDelay. If zero it defaults to 1 second.
+	ReconnectDelay time.Duration
+
+	// MaxReconnectDelay is the maximum delay between reconnect attempts. If zero it defaults to 1 minute.
+	MaxReconnectDelay time.Duration
+
+	mux      sync.Mutex
+	handlers map[string]NotificationHandler
+	wakeChan chan struct{}
+}
+
+// Handle registers handler for channel. It replaces any handler previously registered for channel. If the Listener is
+// running, it starts listening to channel asynchronously. Notifications sent before the LISTEN is issued are not
+// delivered.
+func (l *Listener) Handle(channel string, handler NotificationHandler) {
+	l.mux.Lock()
+	if l.handlers == nil {
+		l.handlers = make(map[string]NotificationHandler)
+	}
+	l.handlers[channel] = handler
+	l.mux.Unlock()
+
+	l.wake()
+}
+
+// Unhandle removes the handler for channel. If the Listener is running, it stops listening to channel asynchronously.
+func (l *Listener) Unhandle(channel string) {
+	l.mux.Lock()
+	delete(l.handlers, channel)
+	l.mux.Unlock()
+
+	l.wake()
+}
+
+// Channels returns the channels that have a registered handler.
+func (l *Listener) Channels() []string {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	channels := make([]string, 0, len(l.handlers))
+	for channel := range l.handlers {
+		channels = append(channels, channel)
+	}
+	return channels
+}
+
+// Listen listens for notifications and dispatches them until ctx is canceled or Pool is closed. It returns ctx.Err()
+// or puddle.ErrClosedPool. Listen must not be called concurrently on the same Listener.
+func (l *Listener) Listen(ctx context.Context) error {
+	if l.Pool == nil {
+		panic("Listener.Pool must be set")
+	}
+
+	l.mux.Lock()
+	if l.wakeChan == nil {
+		l.wakeChan = make(chan struct{}, 1)
+	}
+	l.mux.Unlock()
+
+	reconnectDelay := l.ReconnectDelay
+	if reconnectDelay == 0 {
+		reconnectDelay = defaultListenerReconnectDelay
+	}
+	maxReconnectDelay := l.MaxReconnectDelay
+	if maxReconnectDelay == 0 {
+		maxReconnectDelay = defaultListenerMaxReconnectDelay
+	}
+
+	delay := reconnectDelay
+	for {
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if l.poolClosed() {
+			return puddle.ErrClosedPool
+		}
+		l.logError(ctx, err)
+
+		if connected {
+			delay = reconnectDelay
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-l.Pool.closeChan:
+			timer.Stop()
+			return puddle.ErrClosedPool
+		case <-timer.C:
+		}
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// listen acquires a connection and dispatches notifications until an error occurs. connected reports if the connection
+// was fully set up before the error.
+func (l *Listener) listen(ctx context.Context) (connected bool, err error) {
+	poolConn, err := l.Pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	// The connection may still be listening to channels. Close it rather than returning it to the pool.
+	conn := poolConn.Hijack()
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		conn.Close(closeCtx)
+		cancel()
+	}()
+
+	listening := make(map[string]struct{})
+	err = l.syncChannels(ctx, conn, listening)
+	if err != nil {
+		return false, err
+	}
+
+	if l.OnReconnect != nil {
+		err = l.OnReconnect(ctx, conn)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	for {
+		err = l.syncChannels(ctx, conn, listening)
+		if err != nil {
+			return true, err
+		}
+
+		notification, err := l.waitForNotification(ctx, conn)
+		if err != nil {
+			if errors.Is(err, context.Canceled) && ctx.Err() == nil && !l.poolClosed() && !conn.IsClosed() {
+				// Woken up by Handle or Unhandle.
+				continue
+			}
+			return true, err
+		}
+
+		l.mux.Lock()
+		handler := l.handlers[notification.Channel]
+		l.mux.Unlock()
+
+		if handler != nil {
+			err = handler(ctx, notification)
+			if err != nil {
+				l.logError(ctx, err)
+			}
+		}
+	}
+}
+
+// syncChannels issues LISTEN and UNLISTEN on conn so the channels in listening match the channels with a handler.
+func (l *Listener) syncChannels(ctx context.Context, conn *gaussdbgo.Conn, listening map[string]struct{}) error {
+	l.mux.Lock()
+	var listen, unlisten []string
+	for channel := range l.handlers {
+		if _, ok := listening[channel]; !ok {
+			listen = append(listen, channel)
+		}
+	}
+	for channel := range listening {
+		if _, ok := l.handlers[channel]; !ok {
+			unlisten = append(unlisten, channel)
+		}
+	}
+	l.mux.Unlock()
+
+	for _, channel := range listen {
+		_, err := conn.Exec(ctx, "listen "+gaussdbgo.Identifier{channel}.Sanitize())
+		if err != nil {
+			return err
+		}
+		listening[channel] = struct{}{}
+	}
+
+	for _, channel := range unlisten {
+		_, err := conn.Exec(ctx, "unlisten "+gaussdbgo.Identifier{channel}.Sanitize())
+		if err != nil {
+			return err
+		}
+		delete(listening, channel)
+	}
+
+	return nil
+}
+
+// waitForNotification waits for a notification on conn. It is interrupted when ctx is canceled, the pool is closed or
+// the set of channels changes.
+func (l *Listener) waitForNotification(ctx context.Context, conn *gaussdbgo.Conn) (*gaussdbconn.Notification, error) {
+	waitCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-l.wakeChan:
+			cancel()
+		case <-l.Pool.closeChan:
+			cancel()
+		case <-done:
+		}
+	}()
+
+	return conn.WaitForNotification(waitCtx)
+}
+
+// wake interrupts a waiting Listen so it picks up a change to the handlers.
+func (l *Listener) wake() {
+	l.mux.Lock()
+	wakeChan := l.wakeChan
+	l.mux.Unlock()
+
+	if wakeChan == nil {
+		return
+	}
+
+	select {
+	case wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+func (l *Listener) poolClosed() bool {
+	select {
+	case <-l.Pool.closeChan:
+		return true
+	default:
+		return false
+	}
+}
+
+func (l *Listener) logError(ctx context.Context, err error) {
+	if l.LogError != nil {
+		l.LogError(ctx, err)
+	}
+}
@@ -0,0 +1,188 @@
+package gaussdbxpool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbxpool"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/gaussdbmock"
+	"github.com/jackc/puddle/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// serveGaussdbMockScripts accepts one connection per script and runs the script after the startup message is accepted.
+// Connections that send a cancel request are closed without consuming a script.
+func serveGaussdbMockScripts(t *testing.T, scripts ...*gaussdbmock.Script) (string, chan error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		for len(scripts) > 0 {
+			conn, err := ln.Accept()
+			if err != nil {
+				serverErrChan <- err
+				return
+			}
+
+			err = conn.SetDeadline(time.Now().Add(5 * time.Second))
+			if err != nil {
+				conn.Close()
+				serverErrChan <- err
+				return
+			}
+
+			backend := gaussdbproto.NewBackend(conn, conn)
+			msg, err := backend.ReceiveStartupMessage()
+			if err != nil {
+				conn.Close()
+				serverErrChan <- err
+				return
+			}
+			if _, ok := msg.(*gaussdbproto.CancelRequest); ok {
+				conn.Close()
+				continue
+			}
+
+			script := &gaussdbmock.Script{Steps: append(gaussdbmock.AcceptUnauthenticatedConnRequestSteps()[1:], scripts[0])}
+			scripts = scripts[1:]
+			err = script.Run(backend)
+			conn.Close()
+			if err != nil {
+				serverErrChan <- err
+				return
+			}
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	return fmt.Sprintf("sslmode=disable host=%s port=%s", host, port), serverErrChan
+}
+
+func expectSimpleQuery(sql, commandTag string) gaussdbmock.Step {
+	return &gaussdbmock.Script{Steps: []gaussdbmock.Step{
+		gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: sql}),
+		gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte(commandTag)}),
+		gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+	}}
+}
+
+func TestListener(t *testing.T) {
+	t.Parallel()
+
+	connString, serverErrChan := serveGaussdbMockScripts(t,
+		&gaussdbmock.Script{Steps: []gaussdbmock.Step{
+			expectSimpleQuery(`listen "chat"`, "LISTEN"),
+			gaussdbmock.SendMessage(&gaussdbproto.NotificationResponse{PID: 1, Channel: "chat", Payload: "hello"}),
+			expectSimpleQuery(`listen "other"`, "LISTEN"),
+			expectSimpleQuery(`unlisten "chat"`, "UNLISTEN"),
+			// The script ends and the server closes the connection.
+		}},
+		&gaussdbmock.Script{Steps: []gaussdbmock.Step{
+			expectSimpleQuery(`listen "other"`, "LISTEN"),
+			gaussdbmock.SendMessage(&gaussdbproto.NotificationResponse{PID: 2, Channel: "other", Payload: "world"}),
+			gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+		}},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := gaussdbxpool.New(ctx, connString)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	notifications := make(chan *gaussdbconn.Notification, 1)
+	handler := func(ctx context.Context, n *gaussdbconn.Notification) error {
+		notifications <- n
+		return nil
+	}
+
+	var reconnectCount int32
+	var loggedErr atomic.Value
+	listener := &gaussdbxpool.Listener{
+		Pool: pool,
+		OnReconnect: func(ctx context.Context, conn *gaussdbgo.Conn) error {
+			atomic.AddInt32(&reconnectCount, 1)
+			return nil
+		},
+		LogError: func(ctx context.Context, err error) {
+			loggedErr.Store(err)
+		},
+		ReconnectDelay: 10 * time.Millisecond,
+	}
+	listener.Handle("chat", handler)
+
+	listenCtx, cancelListen := context.WithCancel(ctx)
+	defer cancelListen()
+	listenErrChan := make(chan error, 1)
+	go func() {
+		listenErrChan <- listener.Listen(listenCtx)
+	}()
+
+	n := <-notifications
+	require.Equal(t, &gaussdbconn.Notification{PID: 1, Channel: "chat", Payload: "hello"}, n)
+	require.EqualValues(t, 1, atomic.LoadInt32(&reconnectCount))
+
+	// Changing the channels interrupts the wait for a notification.
+	listener.Handle("other", handler)
+	listener.Unhandle("chat")
+	require.Equal(t, []string{"other"}, listener.Channels())
+
+	n = <-notifications
+	require.Equal(t, &gaussdbconn.Notification{PID: 2, Channel: "other", Payload: "world"}, n)
+	require.EqualValues(t, 2, atomic.LoadInt32(&reconnectCount))
+	require.Error(t, loggedErr.Load().(error))
+
+	cancelListen()
+	require.ErrorIs(t, <-listenErrChan, context.Canceled)
+	require.NoError(t, <-serverErrChan)
+}
+
+func TestListenerReturnsWhenPoolClosed(t *testing.T) {
+	t.Parallel()
+
+	connString, serverErrChan := serveGaussdbMockScripts(t, &gaussdbmock.Script{Steps: []gaussdbmock.Step{
+		expectSimpleQuery(`listen "chat"`, "LISTEN"),
+		gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pool, err := gaussdbxpool.New(ctx, connString)
+	require.NoError(t, err)
+
+	listening := make(chan struct{})
+	listener := &gaussdbxpool.Listener{
+		Pool: pool,
+		OnReconnect: func(ctx context.Context, conn *gaussdbgo.Conn) error {
+			close(listening)
+			return nil
+		},
+	}
+	listener.Handle("chat", func(ctx context.Context, n *gaussdbconn.Notification) error { return nil })
+
+	listenErrChan := make(chan error, 1)
+	go func() {
+		listenErrChan <- listener.Listen(ctx)
+	}()
+
+	<-listening
+	pool.Close()
+	err = <-listenErrChan
+	require.True(t, errors.Is(err, puddle.ErrClosedPool))
+	require.NoError(t, <-serverErrChan)
+}
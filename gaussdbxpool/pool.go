@@ -3,6 +3,8 @@ package gaussdbxpool
 import (
 	"context"
 	"fmt"
+	"io"
+	"maps"
 	"math/rand"
 	"runtime"
 	"strconv"
@@ -27,6 +29,9 @@ type connResource struct {
 	poolRows   []poolRow
 	poolRowss  []poolRows
 	maxAgeTime time.Time
+
+	// parameterStatuses is the session state after AfterConnect. Used to detect dirty sessions on release.
+	parameterStatuses map[string]string
 }
 
 func (cr *connResource) getConn(p *Pool, res *puddle.Resource[*connResource]) *Conn {
@@ -78,10 +83,12 @@ type Pool struct {
 	newConnsCount        int64
 	lifetimeDestroyCount int64
 	idleDestroyCount     int64
+	dirtyDestroyCount    int64
 
 	p                     *puddle.Pool[*connResource]
 	config                *Config
 	beforeConnect         func(context.Context, *gaussdbgo.ConnConfig) error
+	getPassword           gaussdbconn.GetPasswordFunc
 	afterConnect          func(context.Context, *gaussdbgo.Conn) error
 	beforeAcquire         func(context.Context, *gaussdbgo.Conn) bool
 	afterRelease          func(*gaussdbgo.Conn) bool
@@ -92,6 +99,7 @@ type Pool struct {
 	maxConnLifetimeJitter time.Duration
 	maxConnIdleTime       time.Duration
 	healthCheckPeriod     time.Duration
+	destroyDirtySessions  bool
 
 	healthCheckChan chan struct{}
 
@@ -111,6 +119,11 @@ type Config struct {
 	// will not impact any existing open connections.
 	BeforeConnect func(context.Context, *gaussdbgo.ConnConfig) error
 
+	// GetPassword is set as the GetPassword function of the gaussdbgo.ConnConfig of every new connection before
+	// BeforeConnect is called. It allows the pool to use rotating passwords or IAM tokens. See
+	// gaussdbconn.Config.GetPassword.
+	GetPassword gaussdbconn.GetPasswordFunc
+
 	// AfterConnect is called after a connection is established, but before it is added to the pool.
 	AfterConnect func(context.Context, *gaussdbgo.Conn) error
 
@@ -147,6 +160,11 @@ type Config struct {
 	// HealthCheckPeriod is the duration between checks of the health of idle connections.
 	HealthCheckPeriod time.Duration
 
+	// DestroyDirtySessions destroys a released connection instead of returning it to the pool if a run-time parameter
+	// reported by the server (e.g. TimeZone or search_path) was changed while it was acquired. The state after
+	// AfterConnect is considered clean. See gaussdbgo.Conn.ChangedParameterStatuses.
+	DestroyDirtySessions bool
+
 	createdByParseConfig bool // Used to enforce created by ParseConfig rule.
 }
 
@@ -184,6 +202,7 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 	p := &Pool{
 		config:                config,
 		beforeConnect:         config.BeforeConnect,
+		getPassword:           config.GetPassword,
 		afterConnect:          config.AfterConnect,
 		beforeAcquire:         config.BeforeAcquire,
 		afterRelease:          config.AfterRelease,
@@ -194,6 +213,7 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 		maxConnLifetimeJitter: config.MaxConnLifetimeJitter,
 		maxConnIdleTime:       config.MaxConnIdleTime,
 		healthCheckPeriod:     config.HealthCheckPeriod,
+		destroyDirtySessions:  config.DestroyDirtySessions,
 		healthCheckChan:       make(chan struct{}, 1),
 		closeChan:             make(chan struct{}),
 	}
@@ -218,6 +238,10 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 					connConfig.ConnectTimeout = 2 * time.Minute
 				}
 
+				if p.getPassword != nil {
+					connConfig.GetPassword = p.getPassword
+				}
+
 				if p.beforeConnect != nil {
 					if err := p.beforeConnect(ctx, connConfig); err != nil {
 						return nil, err
@@ -248,6 +272,10 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 					maxAgeTime: maxAgeTime,
 				}
 
+				if p.destroyDirtySessions {
+					cr.parameterStatuses = conn.ChangedParameterStatuses()
+				}
+
 				return cr, nil
 			},
 			Destructor: func(value *connResource) {
@@ -287,6 +315,7 @@ func NewWithConfig(ctx context.Context, config *Config) (*Pool, error) {
 //   - pool_max_conn_idle_time: duration string (default 30 minutes)
 //   - pool_health_check_period: duration string (default 1 minute)
 //   - pool_max_conn_lifetime_jitter: duration string (default 0)
+//   - pool_destroy_dirty_sessions: boolean (default false)
 //
 // See Config for definitions of these arguments.
 //
@@ -373,6 +402,15 @@ func ParseConfig(connString string) (*Config, error) {
 		config.MaxConnLifetimeJitter = d
 	}
 
+	if s, ok := config.ConnConfig.Config.RuntimeParams["pool_destroy_dirty_sessions"]; ok {
+		delete(connConfig.Config.RuntimeParams, "pool_destroy_dirty_sessions")
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pool_destroy_dirty_sessions: %w", err)
+		}
+		config.DestroyDirtySessions = b
+	}
+
 	return config, nil
 }
 
@@ -389,6 +427,15 @@ func (p *Pool) isExpired(res *puddle.Resource[*connResource]) bool {
 	return time.Now().After(res.Value().maxAgeTime)
 }
 
+// isDirty reports if the session state of the connection of res changed since it was added to the pool.
+func (p *Pool) isDirty(res *puddle.Resource[*connResource]) bool {
+	if !p.destroyDirtySessions {
+		return false
+	}
+	cr := res.Value()
+	return !maps.Equal(cr.conn.ChangedParameterStatuses(), cr.parameterStatuses)
+}
+
 func (p *Pool) triggerHealthCheck() {
 	go func() {
 		// Destroy is asynchronous so we give it time to actually remove itself from
@@ -591,6 +638,7 @@ func (p *Pool) Stat() *Stat {
 		newConnsCount:        atomic.LoadInt64(&p.newConnsCount),
 		lifetimeDestroyCount: atomic.LoadInt64(&p.lifetimeDestroyCount),
 		idleDestroyCount:     atomic.LoadInt64(&p.idleDestroyCount),
+		dirtyDestroyCount:    atomic.LoadInt64(&p.dirtyDestroyCount),
 	}
 }
 
@@ -615,9 +663,9 @@ func (p *Pool) Exec(ctx context.Context, sql string, arguments ...any) (gaussdbc
 // If there is an error, the returned gaussdbgo.Rows will be returned in an error state.
 // If preferred, ignore the error returned from Query and handle errors using the returned gaussdbgo.Rows.
 //
-// For extra control over how the query is executed, the types QuerySimpleProtocol, QueryResultFormats, and
-// QueryResultFormatsByOID may be used as the first args to control exactly how the query is executed. This is rarely
-// needed. See the documentation for those types for details.
+// For extra control over how the query is executed, the types QuerySimpleProtocol, QueryResultFormats,
+// QueryResultFormatsByOID, and QueryFetchSize may be used as the first args to control exactly how the query is
+// executed. This is rarely needed. See the documentation for those types for details.
 func (p *Pool) Query(ctx context.Context, sql string, args ...any) (gaussdbgo.Rows, error) {
 	c, err := p.Acquire(ctx)
 	if err != nil {
@@ -702,6 +750,46 @@ func (p *Pool) CopyFrom(ctx context.Context, tableName gaussdbgo.Identifier, col
 	return c.Conn().CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
 
+func (p *Pool) CopyFromCSV(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, r io.Reader, opts gaussdbgo.CopyFromCSVOptions) (int64, error) {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Release()
+
+	return c.Conn().CopyFromCSV(ctx, tableName, columnNames, r, opts)
+}
+
+func (p *Pool) CopyFromText(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, r io.Reader, opts gaussdbgo.CopyFromTextOptions) (int64, error) {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Release()
+
+	return c.Conn().CopyFromText(ctx, tableName, columnNames, r, opts)
+}
+
+func (p *Pool) CopyFromMerge(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, rowSrc gaussdbgo.CopyFromSource, opts gaussdbgo.CopyFromMergeOptions) (gaussdbgo.CopyFromMergeResult, error) {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return gaussdbgo.CopyFromMergeResult{}, err
+	}
+	defer c.Release()
+
+	return c.Conn().CopyFromMerge(ctx, tableName, columnNames, rowSrc, opts)
+}
+
+func (p *Pool) CopyTo(ctx context.Context, tableOrQuery any, columnNames []string, dst gaussdbgo.CopyToDestination) (int64, error) {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Release()
+
+	return c.Conn().CopyTo(ctx, tableOrQuery, columnNames, dst)
+}
+
 // Ping acquires a connection from the Pool and executes an empty sql statement against it.
 // If the sql returns without error, the database Ping is considered successful, otherwise, the error is returned.
 func (p *Pool) Ping(ctx context.Context) error {
@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/HuaweiCloudDeveloper/gaussdb-go"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbproto"
 	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbxpool"
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/internal/gaussdbmock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -42,12 +44,14 @@ func TestConnectConfig(t *testing.T) {
 func TestParseConfigExtractsPoolArguments(t *testing.T) {
 	t.Parallel()
 
-	config, err := gaussdbxpool.ParseConfig("pool_max_conns=42 pool_min_conns=1")
+	config, err := gaussdbxpool.ParseConfig("pool_max_conns=42 pool_min_conns=1 pool_destroy_dirty_sessions=true")
 	assert.NoError(t, err)
 	assert.EqualValues(t, 42, config.MaxConns)
 	assert.EqualValues(t, 1, config.MinConns)
+	assert.True(t, config.DestroyDirtySessions)
 	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_max_conns")
 	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_min_conns")
+	assert.NotContains(t, config.ConnConfig.Config.RuntimeParams, "pool_destroy_dirty_sessions")
 }
 
 func TestConstructorIgnoresContext(t *testing.T) {
@@ -260,6 +264,35 @@ func TestPoolBeforeConnect(t *testing.T) {
 	assert.EqualValues(t, "gaussdbgo", str)
 }
 
+func TestPoolGetPassword(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	config, err := gaussdbxpool.ParseConfig(os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+
+	password := config.ConnConfig.Password
+	config.ConnConfig.Password = "wrong password"
+
+	var calls int32
+	config.GetPassword = func(ctx context.Context, host string, port uint16, user string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		return password, nil
+	}
+
+	db, err := gaussdbxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var n int32
+	err = db.QueryRow(ctx, "select 1").Scan(&n)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, n)
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&calls), int32(1))
+}
+
 func TestPoolAfterConnect(t *testing.T) {
 	t.Parallel()
 
@@ -493,6 +526,58 @@ func TestConnReleaseChecksMaxConnLifetime(t *testing.T) {
 	assert.EqualValues(t, 0, stats.TotalConns())
 }
 
+func TestConnReleaseDestroysDirtySession(t *testing.T) {
+	t.Parallel()
+
+	connString, serverErrChan := serveGaussdbMockScripts(t,
+		&gaussdbmock.Script{Steps: []gaussdbmock.Step{
+			gaussdbmock.ExpectMessage(&gaussdbproto.Query{String: "set timezone = 'UTC'"}),
+			gaussdbmock.SendMessage(&gaussdbproto.ParameterStatus{Name: "TimeZone", Value: "UTC"}),
+			gaussdbmock.SendMessage(&gaussdbproto.CommandComplete{CommandTag: []byte("SET")}),
+			gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+			gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+		}},
+		&gaussdbmock.Script{Steps: []gaussdbmock.Step{
+			gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+		}},
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	config, err := gaussdbxpool.ParseConfig(connString)
+	require.NoError(t, err)
+	config.DestroyDirtySessions = true
+
+	db, err := gaussdbxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+
+	c, err := db.Acquire(ctx)
+	require.NoError(t, err)
+	_, err = c.Conn().GaussdbConn().Exec(ctx, "set timezone = 'UTC'").ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"TimeZone": "UTC"}, c.Conn().ChangedParameterStatuses())
+
+	c.Release()
+	waitForReleaseToComplete()
+
+	stats := db.Stat()
+	assert.EqualValues(t, 1, stats.DirtySessionDestroyCount())
+
+	// A clean session is returned to the pool.
+	c, err = db.Acquire(ctx)
+	require.NoError(t, err)
+	c.Release()
+	waitForReleaseToComplete()
+
+	stats = db.Stat()
+	assert.EqualValues(t, 1, stats.DirtySessionDestroyCount())
+	assert.EqualValues(t, 1, stats.IdleConns())
+
+	db.Close()
+	require.NoError(t, <-serverErrChan)
+}
+
 func TestConnReleaseClosesBusyConn(t *testing.T) {
 	t.Parallel()
 
@@ -12,6 +12,7 @@ type Stat struct {
 	newConnsCount        int64
 	lifetimeDestroyCount int64
 	idleDestroyCount     int64
+	dirtyDestroyCount    int64
 }
 
 // AcquireCount returns the cumulative count of successful acquires from the pool.
@@ -83,6 +84,12 @@ func (s *Stat) MaxIdleDestroyCount() int64 {
 	return s.idleDestroyCount
 }
 
+// DirtySessionDestroyCount returns the cumulative count of connections destroyed on release because their session
+// state changed. See Config.DestroyDirtySessions.
+func (s *Stat) DirtySessionDestroyCount() int64 {
+	return s.dirtyDestroyCount
+}
+
 // EmptyAcquireWaitTime returns the cumulative time waited for successful acquires
 // from the pool for a resource to be released or constructed because the pool was
 // empty.
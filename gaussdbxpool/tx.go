@@ -2,6 +2,7 @@ package gaussdbxpool
 
 import (
 	"context"
+	"io"
 
 	"github.com/HuaweiCloudDeveloper/gaussdb-go"
 	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
@@ -47,6 +48,22 @@ func (tx *Tx) CopyFrom(ctx context.Context, tableName gaussdbgo.Identifier, colu
 	return tx.t.CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
 
+func (tx *Tx) CopyFromCSV(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, r io.Reader, opts gaussdbgo.CopyFromCSVOptions) (int64, error) {
+	return tx.t.CopyFromCSV(ctx, tableName, columnNames, r, opts)
+}
+
+func (tx *Tx) CopyFromText(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, r io.Reader, opts gaussdbgo.CopyFromTextOptions) (int64, error) {
+	return tx.t.CopyFromText(ctx, tableName, columnNames, r, opts)
+}
+
+func (tx *Tx) CopyFromMerge(ctx context.Context, tableName gaussdbgo.Identifier, columnNames []string, rowSrc gaussdbgo.CopyFromSource, opts gaussdbgo.CopyFromMergeOptions) (gaussdbgo.CopyFromMergeResult, error) {
+	return tx.t.CopyFromMerge(ctx, tableName, columnNames, rowSrc, opts)
+}
+
+func (tx *Tx) CopyTo(ctx context.Context, tableOrQuery any, columnNames []string, dst gaussdbgo.CopyToDestination) (int64, error) {
+	return tx.t.CopyTo(ctx, tableOrQuery, columnNames, dst)
+}
+
 func (tx *Tx) SendBatch(ctx context.Context, b *gaussdbgo.Batch) gaussdbgo.BatchResults {
 	return tx.t.SendBatch(ctx, b)
 }
@@ -55,6 +72,12 @@ func (tx *Tx) LargeObjects() gaussdbgo.LargeObjects {
 	return tx.t.LargeObjects()
 }
 
+// DeclareCursor declares a server-side cursor within the transaction. The cursor is only valid until the transaction
+// is committed or rolled back and the connection is returned to the Pool.
+func (tx *Tx) DeclareCursor(ctx context.Context, name, sql string, args []any, opts gaussdbgo.CursorOptions) (*gaussdbgo.Cursor, error) {
+	return tx.t.DeclareCursor(ctx, name, sql, args, opts)
+}
+
 // Prepare creates a prepared statement with name and sql. If the name is empty,
 // an anonymous prepared statement will be used. sql can contain placeholders
 // for bound parameters. These placeholders are referenced positionally as $1, $2, etc.
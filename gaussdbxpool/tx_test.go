@@ -95,3 +95,30 @@ func TestTxCopyFrom(t *testing.T) {
 
 	testCopyFrom(t, ctx, tx)
 }
+
+func TestTxDeclareCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	pool, err := gaussdbxpool.New(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	require.NoError(t, err)
+	defer pool.Close()
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	cursor, err := tx.DeclareCursor(ctx, "numbers", "select generate_series(1, 5)", nil, gaussdbgo.CursorOptions{})
+	require.NoError(t, err)
+
+	rows, err := cursor.Fetch(ctx, 10)
+	require.NoError(t, err)
+	numbers, err := gaussdbgo.CollectRows(rows, gaussdbgo.RowTo[int32])
+	require.NoError(t, err)
+	require.Equal(t, []int32{1, 2, 3, 4, 5}, numbers)
+
+	require.NoError(t, cursor.Close(ctx))
+	require.NoError(t, tx.Commit(ctx))
+}
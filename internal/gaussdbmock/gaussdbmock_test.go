@@ -2,6 +2,10 @@ package gaussdbmock_test
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"net"
 	"strings"
@@ -14,6 +18,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/pbkdf2"
 )
 
 func TestScript(t *testing.T) {
@@ -89,3 +94,126 @@ func TestScript(t *testing.T) {
 
 	assert.NoError(t, <-serverErrChan)
 }
+
+func sm3ServerSignature(password, random64code, token string, iteration int) string {
+	salt, _ := hex.DecodeString(random64code)
+	k := pbkdf2.Key([]byte(password), salt, iteration, 32, sha1.New)
+	serverKey := hmac.New(sha256.New, k)
+	serverKey.Write([]byte("Sever Key"))
+	tokenBytes, _ := hex.DecodeString(token)
+	mac := hmac.New(sha256.New, serverKey.Sum(nil))
+	mac.Write(tokenBytes)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func runSM3AuthScript(t *testing.T, authRequest *gaussdbproto.AuthenticationSM3, steps []gaussdbmock.Step) (*gaussdbconn.GaussdbConn, error, chan error) {
+	script := &gaussdbmock.Script{
+		Steps: []gaussdbmock.Step{
+			gaussdbmock.ExpectAnyMessage(&gaussdbproto.StartupMessage{ProtocolVersion: gaussdbproto.ProtocolVersionNumber, Parameters: map[string]string{}}),
+			gaussdbmock.SendMessage(authRequest),
+		},
+	}
+	script.Steps = append(script.Steps, steps...)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	serverErrChan := make(chan error, 1)
+	go func() {
+		defer close(serverErrChan)
+
+		conn, err := ln.Accept()
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+		defer conn.Close()
+
+		err = conn.SetDeadline(time.Now().Add(time.Second))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+
+		err = script.Run(gaussdbproto.NewBackend(conn, conn))
+		if err != nil {
+			serverErrChan <- err
+			return
+		}
+	}()
+
+	host, port, _ := strings.Cut(ln.Addr().String(), ":")
+	connStr := fmt.Sprintf("sslmode=disable host=%s port=%s user=gaussdb password=Secret@123", host, port)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	gaussdbConn, err := gaussdbconn.Connect(ctx, connStr)
+	return gaussdbConn, err, serverErrChan
+}
+
+func TestSM3Auth(t *testing.T) {
+	const (
+		password  = "Secret@123"
+		random64  = "7e6c7a1f0a6b4f3cb1bcd2a44e9b0f51e3a4c8d6f2b19a3d5e7c9b1a2d4f6e80"
+		token     = "4a1b2c3d"
+		iteration = 10000
+	)
+
+	proof := gaussdbconn.RFC5802Algorithm(password, random64, token, "", iteration, "sm3")
+	require.NotEmpty(t, proof)
+
+	for _, tt := range []struct {
+		name            string
+		storedMethod    uint32
+		serverSignature []byte
+	}{
+		{name: "plain stored password", storedMethod: gaussdbconn.PlainPassword},
+		{name: "sm3 stored password", storedMethod: gaussdbconn.Sm3Password},
+		{name: "sm3 stored password with server signature", storedMethod: gaussdbconn.Sm3Password, serverSignature: []byte(sm3ServerSignature(password, random64, token, iteration))},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			authRequest := &gaussdbproto.AuthenticationSM3{
+				PasswordStoredMethod: tt.storedMethod,
+				Random64Code:         []byte(random64),
+				Token:                []byte(token),
+				Iteration:            iteration,
+				ServerSignature:      tt.serverSignature,
+			}
+			gaussdbConn, err, serverErrChan := runSM3AuthScript(t, authRequest, []gaussdbmock.Step{
+				gaussdbmock.ExpectMessage(&gaussdbproto.PasswordMessage{Password: string(proof)}),
+				gaussdbmock.SendMessage(&gaussdbproto.AuthenticationOk{}),
+				gaussdbmock.SendMessage(&gaussdbproto.BackendKeyData{ProcessID: 0, SecretKey: 0}),
+				gaussdbmock.SendMessage(&gaussdbproto.ReadyForQuery{TxStatus: 'I'}),
+				gaussdbmock.ExpectMessage(&gaussdbproto.Terminate{}),
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			gaussdbConn.Close(ctx)
+
+			assert.NoError(t, <-serverErrChan)
+		})
+	}
+}
+
+func TestSM3AuthInvalidServerSignature(t *testing.T) {
+	authRequest := &gaussdbproto.AuthenticationSM3{
+		PasswordStoredMethod: gaussdbconn.Sm3Password,
+		Random64Code:         []byte("7e6c7a1f0a6b4f3cb1bcd2a44e9b0f51e3a4c8d6f2b19a3d5e7c9b1a2d4f6e80"),
+		Token:                []byte("4a1b2c3d"),
+		Iteration:            10000,
+		ServerSignature:      []byte(strings.Repeat("0", 64)),
+	}
+	_, err, serverErrChan := runSM3AuthScript(t, authRequest, nil)
+	require.ErrorContains(t, err, "failed SM3 auth")
+	assert.NoError(t, <-serverErrChan)
+}
+
+func TestSM3AuthUnsupportedPasswordStoredMethod(t *testing.T) {
+	authRequest := &gaussdbproto.AuthenticationSM3{PasswordStoredMethod: gaussdbconn.Md5Password}
+	_, err, serverErrChan := runSM3AuthScript(t, authRequest, nil)
+	require.ErrorContains(t, err, "password-stored method is not supported")
+	assert.NoError(t, <-serverErrChan)
+}
@@ -15,6 +15,11 @@ var maxLargeObjectMessageLength = 1024*1024*1024 - 1024
 // LargeObjects is a structure used to access the large objects API. It is only valid within the transaction where it
 // was created.
 type LargeObjects struct {
+	// UseFunctionCall makes the large objects opened with Open read and write with Conn.FunctionCall instead of a select
+	// statement, the way libpq does. This avoids parsing and planning a statement for every chunk. Function calls are
+	// not traced by the QueryTracer.
+	UseFunctionCall bool
+
 	tx Tx
 }
 
@@ -39,7 +44,7 @@ func (o *LargeObjects) Open(ctx context.Context, oid uint32, mode LargeObjectMod
 	if err != nil {
 		return nil, err
 	}
-	return &LargeObject{fd: fd, tx: o.tx, ctx: ctx}, nil
+	return &LargeObject{fd: fd, tx: o.tx, ctx: ctx, useFunctionCall: o.UseFunctionCall}, nil
 }
 
 // Unlink removes a large object from the database.
@@ -68,6 +73,8 @@ type LargeObject struct {
 	ctx context.Context
 	tx  Tx
 	fd  int32
+
+	useFunctionCall bool
 }
 
 // Write writes p to the large object and returns the number of bytes written and an error if not all of p was written.
@@ -82,7 +89,12 @@ func (o *LargeObject) Write(p []byte) (int, error) {
 		}
 
 		var n int
-		err := o.tx.QueryRow(o.ctx, "select lowrite($1, $2)", o.fd, p[nTotal:nTotal+expected]).Scan(&n)
+		var err error
+		if o.useFunctionCall {
+			err = o.functionCall("lowrite(integer, bytea)", &n, o.fd, p[nTotal:nTotal+expected])
+		} else {
+			err = o.tx.QueryRow(o.ctx, "select lowrite($1, $2)", o.fd, p[nTotal:nTotal+expected]).Scan(&n)
+		}
 		if err != nil {
 			return nTotal, err
 		}
@@ -115,7 +127,12 @@ func (o *LargeObject) Read(p []byte) (int, error) {
 		}
 
 		res := gaussdbtype.PreallocBytes(p[nTotal:])
-		err := o.tx.QueryRow(o.ctx, "select loread($1, $2)", o.fd, expected).Scan(&res)
+		var err error
+		if o.useFunctionCall {
+			err = o.functionCall("loread(integer, integer)", &res, o.fd, expected)
+		} else {
+			err = o.tx.QueryRow(o.ctx, "select loread($1, $2)", o.fd, expected).Scan(&res)
+		}
 		// We compute expected so that it always fits into p, so it should never happen
 		// that PreallocBytes's ScanBytes had to allocate a new slice.
 		nTotal += len(res)
@@ -133,6 +150,16 @@ func (o *LargeObject) Read(p []byte) (int, error) {
 	return nTotal, nil
 }
 
+// functionCall calls name with Conn.FunctionCall. Conn.FunctionCall bypasses the Tx so it checks that the Tx is still
+// open first.
+func (o *LargeObject) functionCall(name string, result any, args ...any) error {
+	if tx, ok := o.tx.(interface{ isClosed() bool }); ok && tx.isClosed() {
+		return ErrTxClosed
+	}
+
+	return o.tx.Conn().FunctionCall(o.ctx, name, result, args...)
+}
+
 // Seek moves the current location pointer to the new location specified by offset.
 func (o *LargeObject) Seek(offset int64, whence int) (n int64, err error) {
 	err = o.tx.QueryRow(o.ctx, "select lo_lseek64($1, $2, $3)", o.fd, offset, whence).Scan(&n)
@@ -1,6 +1,8 @@
 package gaussdbgo
 
 import (
+	"context"
+	"errors"
 	"testing"
 )
 
@@ -18,3 +20,19 @@ func SetMaxLargeObjectMessageLength(t *testing.T, length int) {
 
 	maxLargeObjectMessageLength = length
 }
+
+func TestLargeObjectFunctionCallTxClosed(t *testing.T) {
+	for _, tx := range []Tx{&dbTx{closed: true}, &dbSimulatedNestedTx{closed: true}} {
+		lo := &LargeObject{ctx: context.Background(), tx: tx, useFunctionCall: true}
+
+		_, err := lo.Write([]byte("foo"))
+		if !errors.Is(err, ErrTxClosed) {
+			t.Fatalf("expected ErrTxClosed, got %v", err)
+		}
+
+		_, err = lo.Read(make([]byte, 3))
+		if !errors.Is(err, ErrTxClosed) {
+			t.Fatalf("expected ErrTxClosed, got %v", err)
+		}
+	}
+}
@@ -29,7 +29,7 @@ func TestLargeObjects(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	testLargeObjects(t, ctx, tx)
+	testLargeObjects(t, ctx, tx.LargeObjects())
 }
 
 func TestLargeObjectsSimpleProtocol(t *testing.T) {
@@ -57,12 +57,33 @@ func TestLargeObjectsSimpleProtocol(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	testLargeObjects(t, ctx, tx)
+	testLargeObjects(t, ctx, tx.LargeObjects())
 }
 
-func testLargeObjects(t *testing.T, ctx context.Context, tx gaussdbgo.Tx) {
+func TestLargeObjectsFunctionCall(t *testing.T) {
+	t.Skip("GaussDB currently does not support Large Objects.")
+	// We use a very short limit to test chunking logic.
+	gaussdbgo.SetMaxLargeObjectMessageLength(t, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	conn, err := gaussdbgo.Connect(ctx, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	lo := tx.LargeObjects()
+	lo.UseFunctionCall = true
+	testLargeObjects(t, ctx, lo)
+}
+
+func testLargeObjects(t *testing.T, ctx context.Context, lo gaussdbgo.LargeObjects) {
 
 	id, err := lo.Create(ctx, 0)
 	if err != nil {
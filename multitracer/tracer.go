@@ -14,6 +14,7 @@ type Tracer struct {
 	QueryTracers       []gaussdbgo.QueryTracer
 	BatchTracers       []gaussdbgo.BatchTracer
 	CopyFromTracers    []gaussdbgo.CopyFromTracer
+	CopyToTracers      []gaussdbgo.CopyToTracer
 	PrepareTracers     []gaussdbgo.PrepareTracer
 	ConnectTracers     []gaussdbgo.ConnectTracer
 	PoolAcquireTracers []gaussdbxpool.AcquireTracer
@@ -35,6 +36,10 @@ func New(tracers ...gaussdbgo.QueryTracer) *Tracer {
 			t.CopyFromTracers = append(t.CopyFromTracers, copyFromTracer)
 		}
 
+		if copyToTracer, ok := tracer.(gaussdbgo.CopyToTracer); ok {
+			t.CopyToTracers = append(t.CopyToTracers, copyToTracer)
+		}
+
 		if prepareTracer, ok := tracer.(gaussdbgo.PrepareTracer); ok {
 			t.PrepareTracers = append(t.PrepareTracers, prepareTracer)
 		}
@@ -103,6 +108,20 @@ func (t *Tracer) TraceCopyFromEnd(ctx context.Context, conn *gaussdbgo.Conn, dat
 	}
 }
 
+func (t *Tracer) TraceCopyToStart(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToStartData) context.Context {
+	for _, tracer := range t.CopyToTracers {
+		ctx = tracer.TraceCopyToStart(ctx, conn, data)
+	}
+
+	return ctx
+}
+
+func (t *Tracer) TraceCopyToEnd(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToEndData) {
+	for _, tracer := range t.CopyToTracers {
+		tracer.TraceCopyToEnd(ctx, conn, data)
+	}
+}
+
 func (t *Tracer) TracePrepareStart(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TracePrepareStartData) context.Context {
 	for _, tracer := range t.PrepareTracers {
 		ctx = tracer.TracePrepareStart(ctx, conn, data)
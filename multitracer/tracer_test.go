@@ -36,6 +36,13 @@ func (tt *testFullTracer) TraceCopyFromStart(ctx context.Context, conn *gaussdbg
 func (tt *testFullTracer) TraceCopyFromEnd(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyFromEndData) {
 }
 
+func (tt *testFullTracer) TraceCopyToStart(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToStartData) context.Context {
+	return ctx
+}
+
+func (tt *testFullTracer) TraceCopyToEnd(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToEndData) {
+}
+
 func (tt *testFullTracer) TracePrepareStart(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TracePrepareStartData) context.Context {
 	return ctx
 }
@@ -97,6 +104,9 @@ func TestNew(t *testing.T) {
 				fullTracer,
 				copyTracer,
 			},
+			CopyToTracers: []gaussdbgo.CopyToTracer{
+				fullTracer,
+			},
 			PrepareTracers: []gaussdbgo.PrepareTracer{
 				fullTracer,
 			},
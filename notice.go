@@ -0,0 +1,61 @@
+package gaussdbgo
+
+import (
+	"context"
+
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+)
+
+type ctxKey int
+
+const (
+	_ ctxKey = iota
+	noticeHandlerCtxKey
+)
+
+// WithNoticeHandler returns a copy of ctx that makes Exec, Query, QueryRow, SendBatch and CopyFrom call handler with
+// every notice (e.g. from RAISE NOTICE or a warning) the server sends while that call is in progress. For Query and
+// SendBatch this lasts until the returned Rows or BatchResults are closed.
+//
+// handler is called on the goroutine using the connection. It is called in addition to the connection-wide
+// gaussdbconn.Config.OnNotice.
+func WithNoticeHandler(ctx context.Context, handler func(*gaussdbconn.Notice)) context.Context {
+	return context.WithValue(ctx, noticeHandlerCtxKey, handler)
+}
+
+// noticeScope is the notice handler of a single operation on a Conn.
+type noticeScope struct {
+	handler func(*gaussdbconn.Notice)
+}
+
+// beginNoticeScope makes the notice handler of ctx the current notice handler of c. It returns the scope to pass to
+// endNoticeScope when the operation is finished. The scope is nil if ctx has no notice handler.
+func (c *Conn) beginNoticeScope(ctx context.Context) *noticeScope {
+	var scope *noticeScope
+	if handler, ok := ctx.Value(noticeHandlerCtxKey).(func(*gaussdbconn.Notice)); ok && handler != nil {
+		scope = &noticeScope{handler: handler}
+	}
+	c.noticeScope = scope
+	return scope
+}
+
+// endNoticeScope removes scope as the current notice handler of c. It is a no-op if another operation has already
+// begun a new scope, so it is safe to call more than once.
+func (c *Conn) endNoticeScope(scope *noticeScope) {
+	if scope != nil && c.noticeScope == scope {
+		c.noticeScope = nil
+	}
+}
+
+// handleNotice is installed as the gaussdbconn.Config.OnNotice of every Conn. It calls the notice handler of the
+// current operation and then onNotice, the handler of the original config.
+func (c *Conn) handleNotice(onNotice gaussdbconn.NoticeHandler) gaussdbconn.NoticeHandler {
+	return func(gaussdbConn *gaussdbconn.GaussdbConn, notice *gaussdbconn.Notice) {
+		if c.noticeScope != nil {
+			c.noticeScope.handler(notice)
+		}
+		if onNotice != nil {
+			onNotice(gaussdbConn, notice)
+		}
+	}
+}
@@ -387,6 +387,50 @@ func TestConnQueryCloseEarlyWithErrorOnWire(t *testing.T) {
 	ensureConnValid(t, conn)
 }
 
+func TestConnQueryFetchSize(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	modes := []gaussdbgo.QueryExecMode{
+		gaussdbgo.QueryExecModeCacheStatement,
+		gaussdbgo.QueryExecModeCacheDescribe,
+		gaussdbgo.QueryExecModeDescribeExec,
+		gaussdbgo.QueryExecModeExec,
+	}
+	gaussdbxtest.RunWithQueryExecModes(ctx, t, defaultConnTestRunner, modes, func(ctx context.Context, t testing.TB, conn *gaussdbgo.Conn) {
+		rows, _ := conn.Query(ctx, "select n from generate_series(1,$1) n", gaussdbgo.QueryFetchSize(3), 10)
+		numbers, err := gaussdbgo.CollectRows(rows, gaussdbgo.RowTo[int32])
+		require.NoError(t, err)
+		require.Equal(t, []int32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, numbers)
+		require.Equal(t, "SELECT 10", rows.CommandTag().String())
+
+		// Closing early inside a transaction closes the portal.
+		tx, err := conn.Begin(ctx)
+		require.NoError(t, err)
+		rows, err = tx.Query(ctx, "select n from generate_series(1,$1) n", gaussdbgo.QueryFetchSize(3), 10)
+		require.NoError(t, err)
+		require.True(t, rows.Next())
+		rows.Close()
+		require.NoError(t, rows.Err())
+		require.NoError(t, tx.Commit(ctx))
+
+		// Errors in a later chunk are reported.
+		rows, _ = conn.Query(ctx, "select 1/(5-n) from generate_series(1,10) n", gaussdbgo.QueryFetchSize(2))
+		for rows.Next() {
+		}
+		require.Error(t, rows.Err())
+	})
+
+	conn := mustConnectString(t, os.Getenv(gaussdbgo.EnvGaussdbTestDatabase))
+	defer closeConn(t, conn)
+	_, err := conn.Query(ctx, "select 1", gaussdbgo.QueryExecModeSimpleProtocol, gaussdbgo.QueryFetchSize(3))
+	require.Error(t, err)
+
+	ensureConnValid(t, conn)
+}
+
 // Test that a connection stays valid when query results read incorrectly
 func TestConnQueryReadWrongTypeError(t *testing.T) {
 	t.Parallel()
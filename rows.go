@@ -140,6 +140,7 @@ type baseRows struct {
 
 	queryTracer QueryTracer
 	batchTracer BatchTracer
+	noticeScope *noticeScope
 	ctx         context.Context
 	startTime   time.Time
 	sql         string
@@ -183,6 +184,10 @@ func (rows *baseRows) Close() {
 		}
 	}
 
+	if rows.conn != nil {
+		rows.conn.endNoticeScope(rows.noticeScope)
+	}
+
 	if rows.batchTracer != nil {
 		rows.batchTracer.TraceBatchQuery(rows.ctx, rows.conn, TraceBatchQueryData{SQL: rows.sql, Args: rows.args, CommandTag: rows.commandTag, Err: rows.err})
 	} else if rows.queryTracer != nil {
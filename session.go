@@ -0,0 +1,67 @@
+package gaussdbgo
+
+import (
+	"github.com/HuaweiCloudDeveloper/gaussdb-go/gaussdbconn"
+)
+
+// ChangedParameterStatuses returns the run-time parameters reported by the server whose values differ from when the
+// connection was established, e.g. because of a SET statement. The map is keyed by parameter name and holds the current
+// values. It is empty if the session state matches the initial state.
+//
+// Only parameters the server reports (e.g. TimeZone, search_path, client_encoding, DateStyle and IntervalStyle) are
+// tracked. Other session state such as temporary tables is not.
+func (c *Conn) ChangedParameterStatuses() map[string]string {
+	changed := make(map[string]string, len(c.changedParameterStatuses))
+	for name, value := range c.changedParameterStatuses {
+		changed[name] = value
+	}
+	return changed
+}
+
+// initSessionState records the parameters reported while the connection was established as the initial session state.
+func (c *Conn) initSessionState() {
+	c.initialParameterStatuses = c.gaussdbConn.ParameterStatuses()
+	c.changedParameterStatuses = make(map[string]string)
+	for name, value := range c.initialParameterStatuses {
+		c.typeMap.SetParameterStatus(name, value)
+	}
+	c.parameterStatusConn.Store(c.gaussdbConn)
+}
+
+// handleParameterStatus is installed as the gaussdbconn.Config.OnParameterStatus of every Conn. It keeps the type map
+// and the session state in sync with the server and then calls onParameterStatus, the handler of the original config.
+func (c *Conn) handleParameterStatus(onParameterStatus gaussdbconn.ParameterStatusHandler) gaussdbconn.ParameterStatusHandler {
+	return func(gaussdbConn *gaussdbconn.GaussdbConn, name, value string) {
+		// Parameters reported while connecting, possibly by several parallel connection attempts, are picked up by
+		// initSessionState.
+		if gaussdbConn == c.parameterStatusConn.Load() {
+			c.updateSessionState(name, value)
+		}
+
+		if onParameterStatus != nil {
+			onParameterStatus(gaussdbConn, name, value)
+		}
+	}
+}
+
+func (c *Conn) updateSessionState(name, value string) {
+	previous := c.typeMap.ParameterStatus(name)
+	c.typeMap.SetParameterStatus(name, value)
+
+	if initial, ok := c.initialParameterStatuses[name]; ok && initial == value {
+		delete(c.changedParameterStatuses, name)
+	} else {
+		c.changedParameterStatuses[name] = value
+	}
+
+	// Cached statements and functions may refer to different objects after search_path changes.
+	if name == "search_path" && value != previous {
+		if c.statementCache != nil {
+			c.statementCache.InvalidateAll()
+		}
+		if c.descriptionCache != nil {
+			c.descriptionCache.InvalidateAll()
+		}
+		clear(c.functionDescriptions)
+	}
+}
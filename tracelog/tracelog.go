@@ -130,7 +130,7 @@ func DefaultTraceLogConfig() *TraceLogConfig {
 	}
 }
 
-// TraceLog implements gaussdbgo.QueryTracer, gaussdbgo.BatchTracer, gaussdbgo.ConnectTracer, and gaussdbgo.CopyFromTracer. Logger and LogLevel
+// TraceLog implements gaussdbgo.QueryTracer, gaussdbgo.BatchTracer, gaussdbgo.ConnectTracer, gaussdbgo.CopyFromTracer, and gaussdbgo.CopyToTracer. Logger and LogLevel
 // are required. Config will be automatically initialized on first use if nil.
 type TraceLog struct {
 	Logger   Logger
@@ -160,6 +160,7 @@ const (
 	tracelogCopyFromCtxKey
 	tracelogConnectCtxKey
 	tracelogPrepareCtxKey
+	tracelogCopyToCtxKey
 )
 
 type traceQueryData struct {
@@ -270,6 +271,41 @@ func (tl *TraceLog) TraceCopyFromEnd(ctx context.Context, conn *gaussdbgo.Conn,
 	}
 }
 
+type traceCopyToData struct {
+	startTime   time.Time
+	TableName   gaussdbgo.Identifier
+	ColumnNames []string
+	SQL         string
+}
+
+func (tl *TraceLog) TraceCopyToStart(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToStartData) context.Context {
+	return context.WithValue(ctx, tracelogCopyToCtxKey, &traceCopyToData{
+		startTime:   time.Now(),
+		TableName:   data.TableName,
+		ColumnNames: data.ColumnNames,
+		SQL:         data.SQL,
+	})
+}
+
+func (tl *TraceLog) TraceCopyToEnd(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToEndData) {
+	tl.ensureConfig()
+	copyToData := ctx.Value(tracelogCopyToCtxKey).(*traceCopyToData)
+
+	endTime := time.Now()
+	interval := endTime.Sub(copyToData.startTime)
+
+	if data.Err != nil {
+		if tl.shouldLog(LogLevelError) {
+			tl.log(ctx, conn, LogLevelError, "CopyTo", map[string]any{"tableName": copyToData.TableName, "columnNames": copyToData.ColumnNames, "sql": copyToData.SQL, "err": data.Err, tl.Config.TimeKey: interval})
+		}
+		return
+	}
+
+	if tl.shouldLog(LogLevelInfo) {
+		tl.log(ctx, conn, LogLevelInfo, "CopyTo", map[string]any{"tableName": copyToData.TableName, "columnNames": copyToData.ColumnNames, "sql": copyToData.SQL, tl.Config.TimeKey: interval, "rowCount": data.CommandTag.RowsAffected()})
+	}
+}
+
 type traceConnectData struct {
 	startTime  time.Time
 	connConfig *gaussdbgo.ConnConfig
@@ -69,6 +69,28 @@ type TraceCopyFromEndData struct {
 	Err        error
 }
 
+// CopyToTracer traces CopyTo.
+type CopyToTracer interface {
+	// TraceCopyToStart is called at the beginning of CopyTo calls. The returned context is used for the
+	// rest of the call and will be passed to TraceCopyToEnd.
+	TraceCopyToStart(ctx context.Context, conn *Conn, data TraceCopyToStartData) context.Context
+
+	TraceCopyToEnd(ctx context.Context, conn *Conn, data TraceCopyToEndData)
+}
+
+// TraceCopyToStartData describes the source of a CopyTo. SQL is set when a query is copied. Otherwise TableName and
+// ColumnNames are set.
+type TraceCopyToStartData struct {
+	TableName   Identifier
+	ColumnNames []string
+	SQL         string
+}
+
+type TraceCopyToEndData struct {
+	CommandTag gaussdbconn.CommandTag
+	Err        error
+}
+
 // PrepareTracer traces Prepare.
 type PrepareTracer interface {
 	// TracePrepareStart is called at the beginning of Prepare calls. The returned context is used for the
@@ -18,6 +18,8 @@ type testTracer struct {
 	traceBatchEnd      func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceBatchEndData)
 	traceCopyFromStart func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyFromStartData) context.Context
 	traceCopyFromEnd   func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyFromEndData)
+	traceCopyToStart   func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToStartData) context.Context
+	traceCopyToEnd     func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToEndData)
 	tracePrepareStart  func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TracePrepareStartData) context.Context
 	tracePrepareEnd    func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TracePrepareEndData)
 	traceConnectStart  func(ctx context.Context, data gaussdbgo.TraceConnectStartData) context.Context
@@ -71,6 +73,19 @@ func (tt *testTracer) TraceCopyFromEnd(ctx context.Context, conn *gaussdbgo.Conn
 	}
 }
 
+func (tt *testTracer) TraceCopyToStart(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToStartData) context.Context {
+	if tt.traceCopyToStart != nil {
+		return tt.traceCopyToStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (tt *testTracer) TraceCopyToEnd(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToEndData) {
+	if tt.traceCopyToEnd != nil {
+		tt.traceCopyToEnd(ctx, conn, data)
+	}
+}
+
 func (tt *testTracer) TracePrepareStart(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TracePrepareStartData) context.Context {
 	if tt.tracePrepareStart != nil {
 		return tt.tracePrepareStart(ctx, conn, data)
@@ -462,6 +477,55 @@ func TestTraceCopyFrom(t *testing.T) {
 	})
 }
 
+func TestTraceCopyTo(t *testing.T) {
+	t.Parallel()
+
+	tracer := &testTracer{}
+
+	ctr := defaultConnTestRunner
+	ctr.CreateConfig = func(ctx context.Context, t testing.TB) *gaussdbgo.ConnConfig {
+		config := defaultConnTestRunner.CreateConfig(ctx, t)
+		config.Tracer = tracer
+		return config
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	gaussdbxtest.RunWithQueryExecModes(ctx, t, ctr, nil, func(ctx context.Context, t testing.TB, conn *gaussdbgo.Conn) {
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+
+		traceCopyToStartCalled := false
+		tracer.traceCopyToStart = func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToStartData) context.Context {
+			traceCopyToStartCalled = true
+			require.Equal(t, gaussdbgo.Identifier{"foo"}, data.TableName)
+			require.Equal(t, []string{"a"}, data.ColumnNames)
+			return context.WithValue(ctx, ctxKey("fromTraceCopyToStart"), "foo")
+		}
+
+		traceCopyToEndCalled := false
+		tracer.traceCopyToEnd = func(ctx context.Context, conn *gaussdbgo.Conn, data gaussdbgo.TraceCopyToEndData) {
+			traceCopyToEndCalled = true
+			require.Equal(t, "foo", ctx.Value(ctxKey("fromTraceCopyToStart")))
+			require.Equal(t, `COPY 2`, data.CommandTag.String())
+			require.NoError(t, data.Err)
+		}
+
+		_, err := conn.Exec(ctx, `create temporary table foo(a int4)`)
+		require.NoError(t, err)
+		_, err = conn.Exec(ctx, `insert into foo values (1), (null)`)
+		require.NoError(t, err)
+
+		var rows [][]any
+		copyCount, err := conn.CopyTo(ctx, gaussdbgo.Identifier{"foo"}, []string{"a"}, gaussdbgo.CopyToRows(&rows))
+		require.NoError(t, err)
+		require.EqualValues(t, 2, copyCount)
+		require.True(t, traceCopyToStartCalled)
+		require.True(t, traceCopyToEndCalled)
+	})
+}
+
 func TestTracePrepare(t *testing.T) {
 	t.Parallel()
 
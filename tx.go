@@ -3,6 +3,7 @@ package gaussdbgo
 import (
 	"context"
 	"errors"
+	"io"
 	"strconv"
 	"strings"
 
@@ -137,9 +138,20 @@ type Tx interface {
 	Rollback(ctx context.Context) error
 
 	CopyFrom(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource) (int64, error)
+	CopyFromCSV(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromCSVOptions) (int64, error)
+	CopyFromText(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromTextOptions) (int64, error)
+
+	// CopyFromMerge merges rowSrc into tableName like Conn.CopyFromMerge in a pseudo nested transaction.
+	CopyFromMerge(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource, opts CopyFromMergeOptions) (CopyFromMergeResult, error)
+
+	CopyTo(ctx context.Context, tableOrQuery any, columnNames []string, dst CopyToDestination) (int64, error)
 	SendBatch(ctx context.Context, b *Batch) BatchResults
 	LargeObjects() LargeObjects
 
+	// DeclareCursor declares a server-side cursor called name for the query sql with args. The rows are read with
+	// Cursor.Fetch.
+	DeclareCursor(ctx context.Context, name, sql string, args []any, opts CursorOptions) (*Cursor, error)
+
 	Prepare(ctx context.Context, name, sql string) (*gaussdbconn.StatementDescription, error)
 
 	Exec(ctx context.Context, sql string, arguments ...any) (commandTag gaussdbconn.CommandTag, err error)
@@ -266,6 +278,42 @@ func (tx *dbTx) CopyFrom(ctx context.Context, tableName Identifier, columnNames
 	return tx.conn.CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
 
+// CopyFromCSV delegates to the underlying *Conn
+func (tx *dbTx) CopyFromCSV(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromCSVOptions) (int64, error) {
+	if tx.closed {
+		return 0, ErrTxClosed
+	}
+
+	return tx.conn.CopyFromCSV(ctx, tableName, columnNames, r, opts)
+}
+
+// CopyFromText delegates to the underlying *Conn
+func (tx *dbTx) CopyFromText(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromTextOptions) (int64, error) {
+	if tx.closed {
+		return 0, ErrTxClosed
+	}
+
+	return tx.conn.CopyFromText(ctx, tableName, columnNames, r, opts)
+}
+
+// CopyFromMerge merges rowSrc into tableName in a pseudo nested transaction
+func (tx *dbTx) CopyFromMerge(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource, opts CopyFromMergeOptions) (CopyFromMergeResult, error) {
+	if tx.closed {
+		return CopyFromMergeResult{}, ErrTxClosed
+	}
+
+	return copyFromMergeFunc(ctx, tx, tableName, columnNames, rowSrc, opts)
+}
+
+// CopyTo delegates to the underlying *Conn
+func (tx *dbTx) CopyTo(ctx context.Context, tableOrQuery any, columnNames []string, dst CopyToDestination) (int64, error) {
+	if tx.closed {
+		return 0, ErrTxClosed
+	}
+
+	return tx.conn.CopyTo(ctx, tableOrQuery, columnNames, dst)
+}
+
 // SendBatch delegates to the underlying *Conn
 func (tx *dbTx) SendBatch(ctx context.Context, b *Batch) BatchResults {
 	if tx.closed {
@@ -280,6 +328,19 @@ func (tx *dbTx) LargeObjects() LargeObjects {
 	return LargeObjects{tx: tx}
 }
 
+func (tx *dbTx) isClosed() bool {
+	return tx.closed
+}
+
+// DeclareCursor declares a server-side cursor within the transaction.
+func (tx *dbTx) DeclareCursor(ctx context.Context, name, sql string, args []any, opts CursorOptions) (*Cursor, error) {
+	if tx.closed {
+		return nil, ErrTxClosed
+	}
+
+	return declareCursor(ctx, tx, name, sql, args, opts)
+}
+
 func (tx *dbTx) Conn() *Conn {
 	return tx.conn
 }
@@ -368,6 +429,42 @@ func (sp *dbSimulatedNestedTx) CopyFrom(ctx context.Context, tableName Identifie
 	return sp.tx.CopyFrom(ctx, tableName, columnNames, rowSrc)
 }
 
+// CopyFromCSV delegates to the underlying *Conn
+func (sp *dbSimulatedNestedTx) CopyFromCSV(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromCSVOptions) (int64, error) {
+	if sp.closed {
+		return 0, ErrTxClosed
+	}
+
+	return sp.tx.CopyFromCSV(ctx, tableName, columnNames, r, opts)
+}
+
+// CopyFromText delegates to the underlying *Conn
+func (sp *dbSimulatedNestedTx) CopyFromText(ctx context.Context, tableName Identifier, columnNames []string, r io.Reader, opts CopyFromTextOptions) (int64, error) {
+	if sp.closed {
+		return 0, ErrTxClosed
+	}
+
+	return sp.tx.CopyFromText(ctx, tableName, columnNames, r, opts)
+}
+
+// CopyFromMerge merges rowSrc into tableName in a pseudo nested transaction
+func (sp *dbSimulatedNestedTx) CopyFromMerge(ctx context.Context, tableName Identifier, columnNames []string, rowSrc CopyFromSource, opts CopyFromMergeOptions) (CopyFromMergeResult, error) {
+	if sp.closed {
+		return CopyFromMergeResult{}, ErrTxClosed
+	}
+
+	return copyFromMergeFunc(ctx, sp, tableName, columnNames, rowSrc, opts)
+}
+
+// CopyTo delegates to the underlying *Conn
+func (sp *dbSimulatedNestedTx) CopyTo(ctx context.Context, tableOrQuery any, columnNames []string, dst CopyToDestination) (int64, error) {
+	if sp.closed {
+		return 0, ErrTxClosed
+	}
+
+	return sp.tx.CopyTo(ctx, tableOrQuery, columnNames, dst)
+}
+
 // SendBatch delegates to the underlying *Conn
 func (sp *dbSimulatedNestedTx) SendBatch(ctx context.Context, b *Batch) BatchResults {
 	if sp.closed {
@@ -381,6 +478,19 @@ func (sp *dbSimulatedNestedTx) LargeObjects() LargeObjects {
 	return LargeObjects{tx: sp}
 }
 
+func (sp *dbSimulatedNestedTx) isClosed() bool {
+	return sp.closed
+}
+
+// DeclareCursor declares a server-side cursor within the pseudo nested transaction.
+func (sp *dbSimulatedNestedTx) DeclareCursor(ctx context.Context, name, sql string, args []any, opts CursorOptions) (*Cursor, error) {
+	if sp.closed {
+		return nil, ErrTxClosed
+	}
+
+	return declareCursor(ctx, sp, name, sql, args, opts)
+}
+
 func (sp *dbSimulatedNestedTx) Conn() *Conn {
 	return sp.tx.Conn()
 }